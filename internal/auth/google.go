@@ -201,9 +201,11 @@ func HandleGoogleCallback(c *gin.Context) {
 	}
 
 	accessClaims := jwt.MapClaims{
+		"user_id": finalUser.ID,
 		"email":   finalUser.Email,
 		"name":    finalUser.Name,
 		"picture": gu.Picture,
+		"role":    services.EffectiveRole(finalUser),
 		"exp":     time.Now().Add(accessExpiry).Unix(),
 		"iat":     time.Now().Unix(),
 		"iss":     "altoai_mvp",
@@ -233,6 +235,7 @@ func HandleGoogleCallback(c *gin.Context) {
 	}
 
 	refreshClaims := jwt.MapClaims{
+		"user_id": finalUser.ID,
 		"email":   finalUser.Email,
 		"name":    finalUser.Name,
 		"picture": gu.Picture,
@@ -2,12 +2,16 @@ package handlers
 
 import (
 	"altoai_mvp/interview"
+	"altoai_mvp/internal/middleware"
 	"altoai_mvp/internal/services"
 	"altoai_mvp/pkg/response"
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -26,8 +30,23 @@ type ChatRequest struct {
 		Role    string `json:"role"`
 		Content string `json:"content"`
 	} `json:"messages"`
-	SessionID string `json:"session_id,omitempty"` // Optional: for continuing existing interview
-	Level     string `json:"level,omitempty"`      // Optional: difficulty level (easy, medium, hard)
+	SessionID       string `json:"session_id,omitempty"`        // Optional: for continuing existing interview
+	Level           string `json:"level,omitempty"`             // Optional: level name from levels.json (easy, medium, hard); default level when empty
+	AsyncGrading    bool   `json:"async_grading,omitempty"`     // Optional: return the next question now and grade in the background
+	Seed            *int64 `json:"seed,omitempty"`              // Optional: selection seed of an earlier session, to replay its questions
+	VisaType        string `json:"visa_type,omitempty"`         // Optional: visa to practice for (F-1, J-1, B1/B2, H-1B, M-1); F-1 when empty
+	RetryQuestionID string `json:"retry_question_id,omitempty"` // Optional: answered question the message is a new attempt at
+	SummaryAttempt  string `json:"summary_attempt,omitempty"`   // Optional: attempt at a retried question the summary counts, best or latest (default)
+}
+
+// newSession starts the session for a chat request
+func (req ChatRequest) newSession(userID string) *interview.Session {
+	return interview.NewSessionWithOptions(userID, interview.SessionOptions{
+		Level:          req.Level,
+		VisaType:       req.VisaType,
+		Seed:           req.Seed,
+		SummaryAttempt: req.SummaryAttempt,
+	})
 }
 
 type ChatResponse struct {
@@ -42,13 +61,26 @@ type ChatResponse struct {
 	Suggestions     []string                    `json:"suggestions,omitempty"`      // Improvement suggestions
 	ImprovedVersion string                      `json:"improved_version,omitempty"` // Suggested improved answer
 	AllAnalyses     []AnswerAnalysis            `json:"all_analyses,omitempty"`     // All answers with analyses (when finished)
+	IsFollowup      bool                        `json:"is_followup,omitempty"`      // Whether the returned question follows up on a weak answer
+	GradingStatus   string                      `json:"grading_status,omitempty"`   // graded, pending or failed for the submitted answer
+	GradingMessage  string                      `json:"grading_message,omitempty"`  // Explains a pending or failed grade
+	Attempt         int                         `json:"attempt,omitempty"`          // Attempt number of a retried answer
+	ScoreDelta      *float64                    `json:"score_delta,omitempty"`      // Change in weighted score (0-100) from the previous attempt
 }
 
+const (
+	// gradingPendingMessage is shown when the LLM is unavailable and the answer is kept for later grading
+	gradingPendingMessage = "grading temporarily unavailable, answer saved for later grading"
+	// gradingQueuedMessage is shown when the client asked for background grading
+	gradingQueuedMessage = "answer saved, grading in progress"
+)
+
 type AnswerAnalysis struct {
-	QuestionID   string                      `json:"question_id"`
-	QuestionText string                      `json:"question_text"`
-	AnswerText   string                      `json:"answer_text"`
-	Analysis     *interview.AnalysisResponse `json:"analysis,omitempty"`
+	QuestionID      string                      `json:"question_id"`
+	QuestionText    string                      `json:"question_text"`
+	AnswerText      string                      `json:"answer_text"`
+	Analysis        *interview.AnalysisResponse `json:"analysis,omitempty"`
+	ImprovedVersion string                      `json:"improved_version,omitempty"` // Model answer built from the student's own facts
 }
 
 func (h *ChatHandler) Chat(c *gin.Context) {
@@ -58,6 +90,77 @@ func (h *ChatHandler) Chat(c *gin.Context) {
 		return
 	}
 
+	userID, err := resolveUserID(c, h.userSvc)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "user not found")
+		return
+	}
+
+	// Serialize with background grading of the same session
+	unlock := lockChatSession(req)
+	defer unlock()
+
+	turn, reply, apiErr := beginTurn(userID, req)
+	if apiErr != nil {
+		response.Error(c, apiErr.status, apiErr.message)
+		return
+	}
+	if reply == nil {
+		turn.grade(c.Request.Context(), nil)
+		done := turn.complete()
+		reply = &done
+	}
+	response.OK(c, *reply)
+	if reply.Finished {
+		// After the client has its answer; the check is an LLM round-trip
+		unlock()
+		interview.ScheduleConsistencyCheck(c.Request.Context(), reply.SessionID)
+	}
+}
+
+// lockChatSession takes the lock of the request's session, if it names one.
+// The returned function may be called more than once.
+func lockChatSession(req ChatRequest) func() {
+	if req.SessionID == "" {
+		return func() {}
+	}
+	return sync.OnceFunc(interview.LockSession(req.SessionID))
+}
+
+// chatError is an error response produced while starting a turn
+type chatError struct {
+	status  int
+	message string
+}
+
+// chatTurn is one answered question on its way through grading. It is shared
+// by the blocking and the streaming chat endpoints.
+type chatTurn struct {
+	session        *interview.Session
+	question       interview.Question
+	answer         interview.Answer
+	async          bool
+	analysis       *interview.AnalysisResponse
+	gradingMessage string
+	followup       *interview.Question // asked next because the answer was weak
+	retry          bool                // a new attempt at an answered question
+}
+
+// beginTurn resolves or creates the session and records the user's answer.
+// When there is nothing to grade (new session, no message, finished or
+// already answered) it returns the reply to send instead of a turn.
+func beginTurn(userID string, req ChatRequest) (*chatTurn, *ChatResponse, *chatError) {
+	// Reject unknown levels and visas instead of starting a session with no questions
+	if _, err := interview.LookupLevel(req.Level); err != nil {
+		return nil, nil, &chatError{http.StatusBadRequest, err.Error()}
+	}
+	if _, err := interview.LookupVisaType(req.VisaType); err != nil {
+		return nil, nil, &chatError{http.StatusBadRequest, err.Error()}
+	}
+	if _, err := interview.ParseSummaryAttempt(req.SummaryAttempt); err != nil {
+		return nil, nil, &chatError{http.StatusBadRequest, err.Error()}
+	}
+
 	// Get or create session
 	var session *interview.Session
 	var isNewSession bool
@@ -65,18 +168,25 @@ func (h *ChatHandler) Chat(c *gin.Context) {
 	if req.SessionID != "" {
 		// Try to retrieve existing session
 		if s, ok := interview.GetSession(req.SessionID); ok {
+			if !s.OwnedBy(userID) {
+				return nil, nil, &chatError{http.StatusForbidden, interview.ErrSessionForbidden.Error()}
+			}
 			session = s
 			isNewSession = false
 		} else {
 			// Session not found, create new one with level
-			session = interview.NewSessionWithLevel("", req.Level)
-			interview.SaveSession(session)
+			session = req.newSession(userID)
+			if err := interview.SaveSession(session); err != nil {
+				return nil, nil, &chatError{http.StatusInternalServerError, "failed to save session"}
+			}
 			isNewSession = true
 		}
 	} else {
 		// No session ID provided, create new session with level
-		session = interview.NewSessionWithLevel("", req.Level)
-		interview.SaveSession(session)
+		session = req.newSession(userID)
+		if err := interview.SaveSession(session); err != nil {
+			return nil, nil, &chatError{http.StatusInternalServerError, "failed to save session"}
+		}
 		isNewSession = true
 	}
 
@@ -85,88 +195,67 @@ func (h *ChatHandler) Chat(c *gin.Context) {
 		log.Printf("Creating session with level: %s, selected questions: %d", req.Level, len(session.SelectedQuestions))
 	}
 
+	// Retrying an answered question works in finished sessions too
+	if req.RetryQuestionID != "" {
+		return beginRetry(session, req)
+	}
+
 	// If session is finished, return completion message
 	if session.Status == interview.SessionStatusFinished {
 		completionMsg := buildCompletionMessage(session)
-		response.OK(c, ChatResponse{
+		return nil, &ChatResponse{
 			Content:      completionMsg,
 			SessionID:    session.ID,
 			Finished:     true,
 			Scores:       &session.Scores,
 			IsNewSession: false,
-		})
-		return
+		}, nil
 	}
 
 	// If this is a new session, return the first question
 	if isNewSession {
 		if len(session.SelectedQuestions) == 0 {
-			response.Error(c, http.StatusInternalServerError, "no questions selected for session")
-			return
+			return nil, nil, &chatError{http.StatusInternalServerError, "no questions selected for session"}
 		}
 		currentQ := session.SelectedQuestions[0]
 
-		response.OK(c, ChatResponse{
+		return nil, &ChatResponse{
 			Content:      currentQ.Text,
 			SessionID:    session.ID,
 			QuestionID:   currentQ.ID,
 			Finished:     false,
 			IsNewSession: isNewSession,
-		})
-		return
+		}, nil
 	}
 
 	// If no messages provided, return current question
 	if len(req.Messages) == 0 {
-		var currentQ *interview.Question
-		for i, q := range session.SelectedQuestions {
-			if q.ID == session.CurrentQuestion {
-				currentQ = &session.SelectedQuestions[i]
-				break
-			}
-		}
+		currentQ := currentQuestion(session)
 		if currentQ == nil {
-			response.Error(c, http.StatusInternalServerError, "current question not found")
-			return
+			return nil, nil, &chatError{http.StatusInternalServerError, "current question not found"}
 		}
 
-		response.OK(c, ChatResponse{
+		return nil, &ChatResponse{
 			Content:    currentQ.Text,
 			SessionID:  session.ID,
 			QuestionID: currentQ.ID,
 			Finished:   false,
 			Scores:     &session.Scores,
-		})
-		return
-	}
-
-	// Find the last user message
-	var lastUserMessage string
-	for i := len(req.Messages) - 1; i >= 0; i-- {
-		if req.Messages[i].Role == "user" {
-			lastUserMessage = req.Messages[i].Content
-			break
-		}
+			IsFollowup: currentQ.IsFollowup,
+		}, nil
 	}
 
+	lastUserMessage := req.lastUserMessage()
 	if lastUserMessage == "" {
-		response.Error(c, http.StatusBadRequest, "no user message found")
-		return
+		return nil, nil, &chatError{http.StatusBadRequest, "no user message found"}
 	}
 
 	// Get current question
-	var currentQ *interview.Question
-	for i, q := range session.SelectedQuestions {
-		if q.ID == session.CurrentQuestion {
-			currentQ = &session.SelectedQuestions[i]
-			break
-		}
-	}
+	currentQ := currentQuestion(session)
 	if currentQ == nil {
 		session.Status = interview.SessionStatusFinished
 		interview.SaveSession(session)
-		response.Error(c, http.StatusInternalServerError, "current question not found")
-		return
+		return nil, nil, &chatError{http.StatusInternalServerError, "current question not found"}
 	}
 
 	// Check if we've already answered this question (prevent duplicate processing)
@@ -194,13 +283,12 @@ func (h *ChatHandler) Chat(c *gin.Context) {
 			interview.SaveSession(session)
 
 			completionMsg := buildCompletionMessage(session)
-			response.OK(c, ChatResponse{
+			return nil, &ChatResponse{
 				Content:   completionMsg,
 				SessionID: session.ID,
 				Finished:  true,
 				Scores:    &session.Scores,
-			})
-			return
+			}, nil
 		}
 
 		// Update session with next question
@@ -208,47 +296,169 @@ func (h *ChatHandler) Chat(c *gin.Context) {
 		session.CurrentQuestion = nextQ.ID
 		interview.SaveSession(session)
 
-		response.OK(c, ChatResponse{
+		return nil, &ChatResponse{
 			Content:    nextQ.Text,
 			SessionID:  session.ID,
 			QuestionID: nextQ.ID,
 			Finished:   false,
 			Scores:     &session.Scores,
-		})
-		return
+			IsFollowup: nextQ.IsFollowup,
+		}, nil
 	}
 
 	// Record the answer
-	answer := interview.Answer{
-		QuestionID:   currentQ.ID,
-		QuestionText: currentQ.Text,
-		Text:         lastUserMessage,
-		CreatedAt:    time.Now(),
+	return &chatTurn{
+		session:  session,
+		question: *currentQ,
+		answer: interview.Answer{
+			QuestionID:   currentQ.ID,
+			QuestionText: currentQ.Text,
+			Text:         lastUserMessage,
+			CreatedAt:    time.Now(),
+		},
+		// Background grading only applies when a queue is running
+		async: req.AsyncGrading && interview.GetGradingQueue() != nil,
+	}, nil, nil
+}
+
+// beginRetry records the last user message as a new attempt at the answered
+// question req.RetryQuestionID. The session stays on its current question.
+func beginRetry(session *interview.Session, req ChatRequest) (*chatTurn, *ChatResponse, *chatError) {
+	previous, ok := session.FindAnswer(req.RetryQuestionID)
+	if !ok {
+		return nil, nil, &chatError{http.StatusBadRequest, interview.ErrQuestionNotAnswered.Error()}
+	}
+	if previous.GradingStatus == interview.GradingStatusPending {
+		return nil, nil, &chatError{http.StatusConflict, interview.ErrAnswerPending.Error()}
+	}
+	text := req.lastUserMessage()
+	if text == "" {
+		return nil, nil, &chatError{http.StatusBadRequest, "no user message found"}
+	}
+
+	question := interview.Question{ID: previous.QuestionID, Text: previous.QuestionText}
+	if q := findQuestion(session, previous.QuestionID); q != nil {
+		question = *q
+	}
+	return &chatTurn{
+		session:  session,
+		question: question,
+		answer: interview.Answer{
+			QuestionID:   question.ID,
+			QuestionText: question.Text,
+			Text:         text,
+			CreatedAt:    time.Now(),
+		},
+		async: req.AsyncGrading && interview.GetGradingQueue() != nil,
+		retry: true,
+	}, nil, nil
+}
+
+// lastUserMessage returns the content of the last user message, or ""
+func (req ChatRequest) lastUserMessage() string {
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == "user" {
+			return req.Messages[i].Content
+		}
+	}
+	return ""
+}
+
+// currentQuestion returns the session's current question, or nil
+func currentQuestion(session *interview.Session) *interview.Question {
+	return findQuestion(session, session.CurrentQuestion)
+}
+
+// findQuestion returns the selected question with the given ID, or nil
+func findQuestion(session *interview.Session, id string) *interview.Question {
+	for i, q := range session.SelectedQuestions {
+		if q.ID == id {
+			return &session.SelectedQuestions[i]
+		}
+	}
+	return nil
+}
+
+// nextQuestion returns the question that follows the one being answered, or
+// nil when this answer ends the interview. A retry returns to the current question.
+func (t *chatTurn) nextQuestion() *interview.Question {
+	if t.retry {
+		if t.session.Status == interview.SessionStatusFinished {
+			return nil
+		}
+		return currentQuestion(t.session)
+	}
+	next := t.session.QuestionIndex + 1
+	if next >= len(t.session.SelectedQuestions) {
+		return nil
+	}
+	return &t.session.SelectedQuestions[next]
+}
+
+// grade analyzes the answer, passing the model's output to onDelta when set.
+// Async turns are left pending for the grading queue.
+func (t *chatTurn) grade(ctx context.Context, onDelta func(string)) {
+	if t.async {
+		t.answer.GradingStatus = interview.GradingStatusPending
+		t.gradingMessage = gradingQueuedMessage
+		return
 	}
 
 	// Call new analyzer for detailed feedback with session context
-	analysis, err := interview.AnalyzeAnswer(session, *currentQ, lastUserMessage)
+	analysis, err := interview.AnalyzeAnswerStream(ctx, t.session, t.question, t.answer.Text, onDelta)
 	if err != nil {
 		// Log error for debugging
 		log.Printf("Error analyzing answer: %v", err)
 		// Continue without analysis (graceful degradation)
-		analysis = nil
-	} else if analysis != nil {
-		log.Printf("Analysis successful: Classification=%s, TotalScore=%d",
-			analysis.Classification, analysis.Scores.TotalScore)
+		// A client that disconnects or times out mid-grade leaves the answer
+		// for the background queue rather than failing it
+		if errors.Is(err, interview.ErrGradingUnavailable) || ctx.Err() != nil {
+			t.answer.GradingStatus = interview.GradingStatusPending
+			t.gradingMessage = gradingPendingMessage
+		} else {
+			t.answer.GradingStatus = interview.GradingStatusFailed
+		}
+		return
 	}
+	if analysis == nil {
+		return
+	}
+	t.answer.GradingStatus = interview.GradingStatusGraded
+	log.Printf("Analysis successful: Classification=%s, TotalScore=%d",
+		analysis.Classification, analysis.Scores.TotalScore)
 
 	// Attach analysis to answer
-	if analysis != nil {
-		answer.Analysis = analysis
-		// Also create EvalResult for backward compatibility with scoring system
-		eval := interview.ConvertAnalysisToEval(analysis, *currentQ)
-		answer.Eval = eval
-		// Update scores using the converted eval
-		interview.ApplyEval(session, eval)
+	t.analysis = analysis
+	t.answer.Analysis = analysis
+	// Also create EvalResult for backward compatibility with scoring system
+	eval := interview.ConvertAnalysisToEval(analysis, t.question)
+	t.answer.Eval = eval
+	if t.retry {
+		// Scores are recomputed once the attempt is recorded. Retries are not
+		// reviews: the schedule follows the first recall of the answer.
+		return
 	}
+	// Schedule the question for spaced repetition
+	interview.RecordReview(t.session.UserID, t.question, analysis)
+	// Update scores using the converted eval
+	interview.ApplyEval(t.session, eval)
+	// Dig into a weak answer before moving on
+	t.followup = interview.PlanFollowup(ctx, t.session, t.question, t.answer.Text, eval)
+}
 
-	session.Answers = append(session.Answers, answer)
+// complete stores the answer, advances the session and builds the reply
+func (t *chatTurn) complete() ChatResponse {
+	if t.retry {
+		return t.completeRetry()
+	}
+	session := t.session
+	analysis := t.analysis
+	session.Answers = append(session.Answers, t.answer)
+
+	if t.followup != nil {
+		interview.InsertNextQuestion(session, *t.followup)
+		log.Printf("Inserted follow-up %s after %s in session %s", t.followup.ID, t.question.ID, session.ID)
+	}
 
 	// Move to next question
 	session.QuestionIndex++
@@ -256,9 +466,6 @@ func (h *ChatHandler) Chat(c *gin.Context) {
 		// All questions answered
 		session.Status = interview.SessionStatusFinished
 
-		// Add delay to ensure analysis is fully processed before returning results
-		time.Sleep(1 * time.Second)
-
 		// Generate session summary before completing
 		summary, err := interview.GenerateSessionSummary(session)
 		if err == nil && summary != nil {
@@ -266,40 +473,32 @@ func (h *ChatHandler) Chat(c *gin.Context) {
 		}
 
 		interview.SaveSession(session)
-
-		// Build all analyses array from session answers
-		allAnalyses := make([]AnswerAnalysis, 0, len(session.Answers))
-		for _, ans := range session.Answers {
-			if ans.Analysis != nil {
-				allAnalyses = append(allAnalyses, AnswerAnalysis{
-					QuestionID:   ans.QuestionID,
-					QuestionText: ans.QuestionText,
-					AnswerText:   ans.Text,
-					Analysis:     ans.Analysis,
-				})
-			}
-		}
+		enqueuePendingGrading(session, t.answer)
 
 		completionMsg := buildCompletionMessage(session)
-		response.OK(c, ChatResponse{
-			Content:     completionMsg,
-			SessionID:   session.ID,
-			QuestionID:  currentQ.ID, // Include question ID for the last answered question
-			Finished:    true,
-			Scores:      &session.Scores,
-			Analysis:    analysis,
-			Grade:       getGradeFromAnalysis(analysis),
-			AllAnalyses: allAnalyses, // Include all analyses when finished
-		})
-		return
+		return ChatResponse{
+			Content:         completionMsg,
+			SessionID:       session.ID,
+			QuestionID:      t.question.ID, // Include question ID for the last answered question
+			Finished:        true,
+			Scores:          &session.Scores,
+			Analysis:        analysis,
+			Grade:           getGradeFromAnalysis(analysis),
+			ImprovedVersion: getImprovedVersionFromAnalysis(analysis),
+			AllAnalyses:     allAnalyses(session), // Include all analyses when finished
+
+			GradingStatus:  t.answer.GradingStatus,
+			GradingMessage: t.gradingMessage,
+		}
 	}
 
 	// Update session with next question
 	nextQ := session.SelectedQuestions[session.QuestionIndex]
 	session.CurrentQuestion = nextQ.ID
 	interview.SaveSession(session)
+	enqueuePendingGrading(session, t.answer)
 
-	response.OK(c, ChatResponse{
+	return ChatResponse{
 		Content:         nextQ.Text,
 		SessionID:       session.ID,
 		QuestionID:      nextQ.ID,
@@ -309,7 +508,110 @@ func (h *ChatHandler) Chat(c *gin.Context) {
 		Grade:           getGradeFromAnalysis(analysis),
 		Suggestions:     getSuggestionsFromAnalysis(analysis),
 		ImprovedVersion: getImprovedVersionFromAnalysis(analysis),
-	})
+		IsFollowup:      nextQ.IsFollowup,
+		GradingStatus:   t.answer.GradingStatus,
+		GradingMessage:  t.gradingMessage,
+	}
+}
+
+// completeRetry stores the new attempt, rescoring the session, and returns
+// to the question the session was on
+func (t *chatTurn) completeRetry() ChatResponse {
+	session := t.session
+	answer, err := session.RecordAttempt(t.answer)
+	if err != nil {
+		// Not reached: beginRetry found the answer under the session lock
+		log.Printf("Error recording attempt in session %s: %v", session.ID, err)
+		return ChatResponse{Content: t.question.Text, SessionID: session.ID, QuestionID: t.question.ID}
+	}
+
+	interview.RecomputeScores(session)
+	if session.Status == interview.SessionStatusFinished {
+		if summary, err := interview.GenerateSessionSummary(session); err == nil && summary != nil {
+			session.Summary = summary
+		}
+	}
+	interview.SaveSession(session)
+	enqueuePendingGrading(session, t.answer)
+
+	resp := ChatResponse{
+		SessionID:       session.ID,
+		Finished:        session.Status == interview.SessionStatusFinished,
+		Scores:          &session.Scores,
+		Analysis:        t.analysis,
+		Grade:           getGradeFromAnalysis(t.analysis),
+		Suggestions:     getSuggestionsFromAnalysis(t.analysis),
+		ImprovedVersion: getImprovedVersionFromAnalysis(t.analysis),
+		GradingStatus:   t.answer.GradingStatus,
+		GradingMessage:  t.gradingMessage,
+		Attempt:         answer.AttemptNumber(),
+	}
+	if delta, ok := interview.AttemptScoreDelta(*answer); ok {
+		resp.ScoreDelta = &delta
+	}
+	if resp.Finished {
+		resp.Content = buildCompletionMessage(session)
+		resp.QuestionID = t.question.ID
+		resp.AllAnalyses = allAnalyses(session)
+	} else if next := currentQuestion(session); next != nil {
+		resp.Content = next.Text
+		resp.QuestionID = next.ID
+		resp.IsFollowup = next.IsFollowup
+	}
+	return resp
+}
+
+// allAnalyses lists the graded answers of a session, with the attempt the
+// summary counts for retried questions
+func allAnalyses(session *interview.Session) []AnswerAnalysis {
+	analyses := make([]AnswerAnalysis, 0, len(session.Answers))
+	for _, ans := range session.CountedAnswers() {
+		if ans.Analysis != nil {
+			analyses = append(analyses, AnswerAnalysis{
+				QuestionID:      ans.QuestionID,
+				QuestionText:    ans.QuestionText,
+				AnswerText:      ans.Text,
+				Analysis:        ans.Analysis,
+				ImprovedVersion: getImprovedVersionFromAnalysis(ans.Analysis),
+			})
+		}
+	}
+	return analyses
+}
+
+// enqueuePendingGrading hands an answer that could not be graded inline to the background queue
+func enqueuePendingGrading(session *interview.Session, answer interview.Answer) {
+	if answer.GradingStatus != interview.GradingStatusPending {
+		return
+	}
+	if !interview.EnqueueGrading(session.ID) {
+		log.Printf("No grading queue running; session %s will be graded on the next scan", session.ID)
+	}
+}
+
+// UserResolver resolves callers the way these handlers do, for the handlers of
+// the interview package
+func UserResolver(userSvc services.UserService) interview.CurrentUser {
+	return func(c *gin.Context) (string, error) {
+		return resolveUserID(c, userSvc)
+	}
+}
+
+// resolveUserID returns the authenticated caller's user ID. Tokens issued before
+// user_id was added to the claims only carry the email, so fall back to a lookup.
+func resolveUserID(c *gin.Context, userSvc services.UserService) (string, error) {
+	claims := middleware.CurrentClaims(c)
+	if claims == nil {
+		return "", errors.New("missing auth claims")
+	}
+	if claims.UserID != "" {
+		return claims.UserID, nil
+	}
+	user, err := userSvc.GetByEmail(c.Request.Context(), claims.Email)
+	if err != nil {
+		return "", err
+	}
+	return user.ID, nil
 }
 
 // buildCompletionMessage creates a completion message based on session summary or scores
@@ -386,6 +688,5 @@ func getImprovedVersionFromAnalysis(analysis *interview.AnalysisResponse) string
 	if analysis == nil {
 		return ""
 	}
-	// Improved version no longer provided in new analysis format
-	return ""
+	return analysis.ImprovedVersion
 }
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"altoai_mvp/interview"
+	"altoai_mvp/pkg/response"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// streamWriteTimeout bounds each write of a stream. The server's WriteTimeout
+// counts from the start of the request, so a stream that waits on the model
+// would be cut off; every event gets this long instead.
+const streamWriteTimeout = 10 * time.Second
+
+// ChatStreamQuestion is the payload of the "question" event
+type ChatStreamQuestion struct {
+	SessionID  string `json:"session_id"`
+	QuestionID string `json:"question_id"`
+	Content    string `json:"content"`
+	IsFollowup bool   `json:"is_followup,omitempty"`
+}
+
+// ChatStreamToken is the payload of the "token" event
+type ChatStreamToken struct {
+	Delta string `json:"delta"`
+}
+
+// ChatStream is the Server-Sent Events variant of Chat. It takes the same
+// request body and emits:
+//
+//	question - the next question, as soon as the answer is recorded. If grading
+//	           then adds a follow-up, a second question event replaces it.
+//	token    - raw analysis output as the model generates it
+//	analysis - the validated AnalysisResponse, when grading succeeded
+//	done     - the same ChatResponse that Chat would return
+//
+// Requests that need no grading (new session, no message, finished session)
+// get a single done event. Errors before streaming starts are plain JSON errors.
+func (h *ChatHandler) ChatStream(c *gin.Context) {
+	var req ChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	userID, err := resolveUserID(c, h.userSvc)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "user not found")
+		return
+	}
+
+	// Serialize with background grading of the same session
+	unlock := lockChatSession(req)
+	defer unlock()
+
+	turn, reply, apiErr := beginTurn(userID, req)
+	if apiErr != nil {
+		response.Error(c, apiErr.status, apiErr.message)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	send := func(event string, data any) {
+		extendWriteDeadline(c, streamWriteTimeout)
+		c.SSEvent(event, data)
+		c.Writer.Flush()
+	}
+
+	if reply != nil {
+		send("done", reply)
+		if reply.Finished {
+			unlock()
+			interview.ScheduleConsistencyCheck(c.Request.Context(), reply.SessionID)
+		}
+		return
+	}
+
+	var previewID string
+	if next := turn.nextQuestion(); next != nil {
+		previewID = next.ID
+		send("question", ChatStreamQuestion{
+			SessionID:  turn.session.ID,
+			QuestionID: next.ID,
+			Content:    next.Text,
+			IsFollowup: next.IsFollowup,
+		})
+	}
+
+	turn.grade(c.Request.Context(), func(delta string) {
+		send("token", ChatStreamToken{Delta: delta})
+	})
+	if turn.analysis != nil {
+		send("analysis", turn.analysis)
+	}
+
+	done := turn.complete()
+	if !done.Finished && done.QuestionID != previewID {
+		send("question", ChatStreamQuestion{
+			SessionID:  done.SessionID,
+			QuestionID: done.QuestionID,
+			Content:    done.Content,
+			IsFollowup: done.IsFollowup,
+		})
+	}
+	send("done", done)
+	if done.Finished {
+		// After the client has its answer; the check is an LLM round-trip
+		unlock()
+		interview.ScheduleConsistencyCheck(c.Request.Context(), done.SessionID)
+	}
+}
+
+// extendWriteDeadline lets the next write happen up to d from now, past the
+// server's WriteTimeout. Writers without deadlines, such as test recorders,
+// are left as they are.
+func extendWriteDeadline(c *gin.Context, d time.Duration) {
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(d))
+}
@@ -0,0 +1,416 @@
+package handlers
+
+import (
+	"altoai_mvp/internal/services"
+	"altoai_mvp/interview"
+	"altoai_mvp/pkg/response"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultInterviewPageSize = 20
+	maxInterviewPageSize     = 100
+	// defaultProgressPageSize is how many of the newest finished sessions a
+	// progress report covers unless page_size says otherwise
+	defaultProgressPageSize = 50
+	maxGradingWait          = 60 * time.Second
+	// gradingWriteTimeout is how long the reply to a long poll may take to
+	// write once the wait is over, which may be past the server's WriteTimeout
+	gradingWriteTimeout = 10 * time.Second
+)
+
+// InterviewHandler serves the interview history of the authenticated user
+type InterviewHandler struct {
+	userSvc services.UserService
+}
+
+func NewInterviewHandler(userSvc services.UserService) *InterviewHandler {
+	return &InterviewHandler{userSvc: userSvc}
+}
+
+// InterviewListItem is a compact view of a session for history listings
+type InterviewListItem struct {
+	ID             string                    `json:"id"`
+	Level          string                    `json:"level,omitempty"`
+	VisaType       string                    `json:"visa_type,omitempty"`
+	Status         interview.SessionStatus   `json:"status"`
+	TotalQuestions int                       `json:"total_questions"`
+	AnsweredCount  int                       `json:"answered_count"`
+	Summary        *interview.SessionSummary `json:"summary,omitempty"`
+	CreatedAt      time.Time                 `json:"created_at"`
+	UpdatedAt      time.Time                 `json:"updated_at"`
+}
+
+type InterviewListResponse struct {
+	Items    []InterviewListItem `json:"items"`
+	Total    int                 `json:"total"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
+}
+
+// List returns the caller's sessions, newest first.
+// Query params: page, page_size, level, status, from, to (RFC 3339 or YYYY-MM-DD).
+func (h *InterviewHandler) List(c *gin.Context) {
+	userID, err := resolveUserID(c, h.userSvc)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "user not found")
+		return
+	}
+
+	page, err := positiveIntQuery(c, "page", 1)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	pageSize, err := positiveIntQuery(c, "page_size", defaultInterviewPageSize)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if pageSize > maxInterviewPageSize {
+		pageSize = maxInterviewPageSize
+	}
+
+	filter := interview.SessionFilter{
+		UserID:   userID,
+		Level:    c.Query("level"),
+		VisaType: c.Query("visa_type"),
+		Status:   interview.SessionStatus(c.Query("status")),
+		Limit:    pageSize,
+		Offset:   (page - 1) * pageSize,
+	}
+	if filter.From, err = dateQuery(c, "from", false); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if filter.To, err = dateQuery(c, "to", true); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sessions, total, err := interview.ListSessions(filter)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "failed to list interviews")
+		return
+	}
+
+	items := make([]InterviewListItem, 0, len(sessions))
+	for _, s := range sessions {
+		items = append(items, InterviewListItem{
+			ID:             s.ID,
+			Level:          s.Level,
+			VisaType:       s.VisaType,
+			Status:         s.Status,
+			TotalQuestions: len(s.SelectedQuestions),
+			AnsweredCount:  len(s.Answers),
+			Summary:        s.Summary,
+			CreatedAt:      s.CreatedAt,
+			UpdatedAt:      s.UpdatedAt,
+		})
+	}
+
+	response.OK(c, InterviewListResponse{
+		Items:    items,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+// Levels lists the interview levels a session can be started with
+func (h *InterviewHandler) Levels(c *gin.Context) {
+	response.OK(c, interview.Levels())
+}
+
+// VisaTypes lists the visas an interview can be practiced for
+func (h *InterviewHandler) VisaTypes(c *gin.Context) {
+	response.OK(c, interview.VisaProfiles())
+}
+
+// Progress aggregates the caller's finished sessions into per-criterion time series.
+// Query params: window (number of sessions in the moving average), page and
+// page_size (the newest sessions come first, 50 per page by default).
+func (h *InterviewHandler) Progress(c *gin.Context) {
+	userID, err := resolveUserID(c, h.userSvc)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "user not found")
+		return
+	}
+	h.progressReport(c, userID)
+}
+
+// CohortProgress is the coach view of Progress for one student.
+// Query params: user_id (required), window, page, page_size.
+func (h *InterviewHandler) CohortProgress(c *gin.Context) {
+	userID := c.Query("user_id")
+	if userID == "" {
+		response.Error(c, http.StatusBadRequest, "user_id is required")
+		return
+	}
+	h.progressReport(c, userID)
+}
+
+// ProgressResponse is one page of a user's finished sessions as a progress report
+type ProgressResponse struct {
+	*interview.ProgressReport
+	Total    int `json:"total"` // finished sessions of the user
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+}
+
+// progressReport writes the progress report of one page of userID's finished sessions
+func (h *InterviewHandler) progressReport(c *gin.Context, userID string) {
+	window, err := positiveIntQuery(c, "window", interview.DefaultProgressWindow)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := positiveIntQuery(c, "page", 1)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	pageSize, err := positiveIntQuery(c, "page_size", defaultProgressPageSize)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if pageSize > maxInterviewPageSize {
+		pageSize = maxInterviewPageSize
+	}
+
+	sessions, total, err := interview.ListSessions(interview.SessionFilter{
+		UserID: userID,
+		Status: interview.SessionStatusFinished,
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	})
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "failed to load interviews")
+		return
+	}
+
+	response.OK(c, ProgressResponse{
+		ProgressReport: interview.BuildProgressReport(sessions, window),
+		Total:          total,
+		Page:           page,
+		PageSize:       pageSize,
+	})
+}
+
+// Get returns one session with all answers and their analyses
+func (h *InterviewHandler) Get(c *gin.Context) {
+	session, ok := h.loadOwnedSession(c)
+	if !ok {
+		return
+	}
+	// Chat turns and background grading update the session in place, so it
+	// is encoded under the session lock
+	unlock := interview.LockSession(session.ID)
+	data, err := json.Marshal(session)
+	unlock()
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "failed to load interview")
+		return
+	}
+	response.OK(c, json.RawMessage(data))
+}
+
+// AnswerGrading is the grading state of one answer
+type AnswerGrading struct {
+	QuestionID    string                      `json:"question_id"`
+	GradingStatus string                      `json:"grading_status"`
+	Analysis      *interview.AnalysisResponse `json:"analysis,omitempty"`
+}
+
+// GradingStatusResponse reports which answers of a session are graded
+type GradingStatusResponse struct {
+	SessionID string                    `json:"session_id"`
+	Complete  bool                      `json:"complete"` // no answers are waiting to be graded
+	Pending   int                       `json:"pending"`
+	Graded    int                       `json:"graded"`
+	Failed    int                       `json:"failed"`
+	Answers   []AnswerGrading           `json:"answers"`
+	Scores    interview.Scores          `json:"scores"`
+	Summary   *interview.SessionSummary `json:"summary,omitempty"`
+}
+
+// Grading reports the grading progress of a session. With wait (e.g. "30s" or
+// "30", at most 60s) it long-polls until no answer is pending or the wait expires.
+func (h *InterviewHandler) Grading(c *gin.Context) {
+	session, ok := h.loadOwnedSession(c)
+	if !ok {
+		return
+	}
+	wait, err := durationQuery(c, "wait")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if wait > maxGradingWait {
+		wait = maxGradingWait
+	}
+
+	status := gradingStatus(session)
+	if wait > 0 && status.Pending > 0 && interview.GetGradingQueue() != nil {
+		timeout := time.NewTimer(wait)
+		defer timeout.Stop()
+		for status.Pending > 0 {
+			changed, stop := interview.WatchGrading(session.ID)
+			s, ok := interview.GetSession(session.ID)
+			if !ok {
+				stop()
+				response.Error(c, http.StatusNotFound, "interview not found")
+				return
+			}
+			if status = gradingStatus(s); status.Pending == 0 {
+				stop()
+				break
+			}
+			select {
+			case <-changed:
+				stop()
+				continue
+			case <-timeout.C:
+			case <-c.Request.Context().Done():
+			}
+			stop()
+			break
+		}
+		extendWriteDeadline(c, gradingWriteTimeout)
+	}
+	response.OK(c, status)
+}
+
+// gradingStatus summarizes the grading state of every answer. Answers saved
+// before grading states existed count as graded when they have an analysis.
+func gradingStatus(session *interview.Session) GradingStatusResponse {
+	unlock := interview.LockSession(session.ID)
+	defer unlock()
+
+	resp := GradingStatusResponse{
+		SessionID: session.ID,
+		Answers:   make([]AnswerGrading, 0, len(session.Answers)),
+		Scores:    session.Scores,
+		Summary:   session.Summary,
+	}
+	for _, a := range session.Answers {
+		status := a.GradingStatus
+		if status == "" {
+			status = interview.GradingStatusFailed
+			if a.Analysis != nil {
+				status = interview.GradingStatusGraded
+			}
+		}
+		switch status {
+		case interview.GradingStatusPending:
+			resp.Pending++
+		case interview.GradingStatusGraded:
+			resp.Graded++
+		default:
+			resp.Failed++
+		}
+		resp.Answers = append(resp.Answers, AnswerGrading{
+			QuestionID:    a.QuestionID,
+			GradingStatus: status,
+			Analysis:      a.Analysis,
+		})
+	}
+	resp.Complete = resp.Pending == 0
+	return resp
+}
+
+// Delete removes one of the caller's sessions
+func (h *InterviewHandler) Delete(c *gin.Context) {
+	session, ok := h.loadOwnedSession(c)
+	if !ok {
+		return
+	}
+	// Hold the session lock so an in-flight chat turn or background grade
+	// finishes its save before the session is removed
+	unlock := interview.LockSession(session.ID)
+	defer unlock()
+	if err := interview.DeleteSession(session.ID); err != nil {
+		if errors.Is(err, interview.ErrSessionNotFound) {
+			response.Error(c, http.StatusNotFound, "interview not found")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "failed to delete interview")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// loadOwnedSession fetches the session named by the :id param and checks that the
+// caller owns it. It writes the error response itself and returns false on failure.
+func (h *InterviewHandler) loadOwnedSession(c *gin.Context) (*interview.Session, bool) {
+	userID, err := resolveUserID(c, h.userSvc)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "user not found")
+		return nil, false
+	}
+	session, ok := interview.GetSession(c.Param("id"))
+	if !ok {
+		response.Error(c, http.StatusNotFound, "interview not found")
+		return nil, false
+	}
+	if !session.OwnedBy(userID) {
+		response.Error(c, http.StatusForbidden, interview.ErrSessionForbidden.Error())
+		return nil, false
+	}
+	return session, true
+}
+
+func positiveIntQuery(c *gin.Context, key string, def int) (int, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		return 0, errors.New("invalid " + key)
+	}
+	return n, nil
+}
+
+// durationQuery parses a Go duration ("30s") or a number of seconds
+func durationQuery(c *gin.Context, key string) (time.Duration, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return 0, nil
+	}
+	if n, err := strconv.Atoi(raw); err == nil && n >= 0 {
+		return time.Duration(n) * time.Second, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		return 0, errors.New("invalid " + key)
+	}
+	return d, nil
+}
+
+// dateQuery parses an RFC 3339 timestamp or a plain date. With endOfDay set, a
+// plain date is moved to the following midnight so "to=2025-01-31" includes that day.
+func dateQuery(c *gin.Context, key string, endOfDay bool) (time.Time, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return time.Time{}, errors.New("invalid " + key + " date")
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
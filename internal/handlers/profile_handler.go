@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"altoai_mvp/internal/services"
+	"altoai_mvp/interview"
+	"altoai_mvp/pkg/response"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProfileHandler serves the applicant profile of the authenticated user
+type ProfileHandler struct {
+	userSvc services.UserService
+}
+
+func NewProfileHandler(userSvc services.UserService) *ProfileHandler {
+	return &ProfileHandler{userSvc: userSvc}
+}
+
+// Get returns the caller's applicant profile, empty when none was saved yet
+func (h *ProfileHandler) Get(c *gin.Context) {
+	userID, err := resolveUserID(c, h.userSvc)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "user not found")
+		return
+	}
+	profile, err := interview.GetProfile(userID)
+	if errors.Is(err, interview.ErrProfileNotFound) {
+		profile = &interview.ApplicantProfile{UserID: userID}
+	} else if err != nil {
+		log.Printf("Failed to load applicant profile: %v", err)
+		response.Error(c, http.StatusInternalServerError, "failed to load applicant profile")
+		return
+	}
+	response.OK(c, profile)
+}
+
+// Update replaces the caller's applicant profile
+func (h *ProfileHandler) Update(c *gin.Context) {
+	userID, err := resolveUserID(c, h.userSvc)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "user not found")
+		return
+	}
+	var profile interview.ApplicantProfile
+	if err := c.ShouldBindJSON(&profile); err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	profile.UserID = userID
+	unlock := interview.LockProfile(userID)
+	defer unlock()
+	if err := interview.SaveProfile(&profile); err != nil {
+		if errors.Is(err, interview.ErrInvalidProfile) {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Printf("Failed to save applicant profile: %v", err)
+		response.Error(c, http.StatusInternalServerError, "failed to save applicant profile")
+		return
+	}
+	response.OK(c, profile)
+}
+
+// UploadDocument reads an I-20, admission letter or bank statement and
+// pre-fills the caller's applicant profile with what it shows.
+// Multipart form fields: kind (i20, admission_letter, bank_statement), file.
+func (h *ProfileHandler) UploadDocument(c *gin.Context) {
+	userID, err := resolveUserID(c, h.userSvc)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "user not found")
+		return
+	}
+	kind, err := interview.ParseDocumentKind(c.PostForm("kind"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "missing file")
+		return
+	}
+	if header.Size > interview.MaxDocumentSize {
+		response.Error(c, http.StatusRequestEntityTooLarge, "document is too large")
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "failed to read file")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, interview.MaxDocumentSize))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "failed to read file")
+		return
+	}
+
+	doc, err := interview.NewDocument(kind, header.Filename, data)
+	if err != nil {
+		response.Error(c, http.StatusUnsupportedMediaType, err.Error())
+		return
+	}
+	if !interview.ExtractorReads(doc.ContentType) {
+		response.Error(c, http.StatusUnsupportedMediaType, doc.ContentType+" documents need an OCR extractor; upload a PDF with a text layer")
+		return
+	}
+	result, err := interview.ImportDocument(c.Request.Context(), userID, doc)
+	if err != nil {
+		switch {
+		case errors.Is(err, interview.ErrUnsupportedDocument):
+			response.Error(c, http.StatusUnsupportedMediaType, err.Error())
+		case errors.Is(err, interview.ErrNothingExtracted), errors.Is(err, interview.ErrInvalidProfile):
+			response.Error(c, http.StatusUnprocessableEntity, err.Error())
+		default:
+			log.Printf("Failed to import document: %v", err)
+			response.Error(c, http.StatusInternalServerError, "failed to import document")
+		}
+		return
+	}
+	response.OK(c, result)
+}
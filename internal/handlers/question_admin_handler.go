@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"altoai_mvp/interview"
+	"altoai_mvp/pkg/response"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QuestionAdminHandler lets admins edit the question bank. Changes apply to
+// new sessions immediately; sessions in progress keep their questions.
+type QuestionAdminHandler struct{}
+
+func NewQuestionAdminHandler() *QuestionAdminHandler {
+	return &QuestionAdminHandler{}
+}
+
+// QuestionRequest is the editable content of a bank question
+type QuestionRequest struct {
+	ID                 string   `json:"id,omitempty"` // create only; generated from the category when empty
+	Category           string   `json:"category" binding:"required"`
+	Text               string   `json:"text" binding:"required"`
+	Difficulty         string   `json:"difficulty,omitempty"`
+	Tags               []string `json:"tags,omitempty"`
+	VisaTypes          []string `json:"visa_types,omitempty"`
+	FollowupCandidates []string `json:"followup_candidates,omitempty"`
+	OfficerNotes       string   `json:"officer_notes,omitempty"`
+	RubricHints        []string `json:"rubric_hints,omitempty"`
+}
+
+func (r QuestionRequest) question() interview.Question {
+	return interview.Question{
+		ID:                 r.ID,
+		Category:           r.Category,
+		Text:               r.Text,
+		Difficulty:         r.Difficulty,
+		Tags:               r.Tags,
+		VisaTypes:          r.VisaTypes,
+		FollowupCandidates: r.FollowupCandidates,
+		OfficerNotes:       r.OfficerNotes,
+		RubricHints:        r.RubricHints,
+	}
+}
+
+// ReorderQuestionsRequest lists every active question ID of a category in the new order
+type ReorderQuestionsRequest struct {
+	Category string   `json:"category" binding:"required"`
+	IDs      []string `json:"ids" binding:"required"`
+}
+
+// List returns the bank by category and position, retired questions included.
+// Query params: category.
+func (h *QuestionAdminHandler) List(c *gin.Context) {
+	questions, err := interview.ListBankQuestions()
+	if err != nil {
+		log.Printf("Failed to list questions: %v", err)
+		response.Error(c, http.StatusInternalServerError, "failed to list questions")
+		return
+	}
+	if category := c.Query("category"); category != "" {
+		filtered := make([]interview.BankQuestion, 0, len(questions))
+		for _, q := range questions {
+			if q.Category == category {
+				filtered = append(filtered, q)
+			}
+		}
+		questions = filtered
+	}
+	response.OK(c, questions)
+}
+
+func (h *QuestionAdminHandler) Create(c *gin.Context) {
+	var req QuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	created, err := interview.CreateBankQuestion(req.question())
+	if err != nil {
+		questionAdminError(c, err)
+		return
+	}
+	response.Created(c, created)
+}
+
+func (h *QuestionAdminHandler) Update(c *gin.Context) {
+	var req QuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	updated, err := interview.UpdateBankQuestion(c.Param("id"), req.question())
+	if err != nil {
+		questionAdminError(c, err)
+		return
+	}
+	response.OK(c, updated)
+}
+
+func (h *QuestionAdminHandler) Retire(c *gin.Context) {
+	retired, err := interview.RetireBankQuestion(c.Param("id"))
+	if err != nil {
+		questionAdminError(c, err)
+		return
+	}
+	response.OK(c, retired)
+}
+
+func (h *QuestionAdminHandler) Reorder(c *gin.Context) {
+	var req ReorderQuestionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	questions, err := interview.ReorderBankQuestions(req.Category, req.IDs)
+	if err != nil {
+		questionAdminError(c, err)
+		return
+	}
+	response.OK(c, questions)
+}
+
+// questionAdminError maps question bank errors to HTTP responses
+func questionAdminError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, interview.ErrQuestionNotFound):
+		response.Error(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, interview.ErrQuestionExists):
+		response.Error(c, http.StatusConflict, err.Error())
+	case errors.Is(err, interview.ErrInvalidQuestion):
+		response.Error(c, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("Question bank update failed: %v", err)
+		response.Error(c, http.StatusInternalServerError, "failed to update question bank")
+	}
+}
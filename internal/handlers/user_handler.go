@@ -76,6 +76,27 @@ func (h *UserHandler) Update(c *gin.Context) {
 	response.OK(c, u)
 }
 
+// UpdateRole changes the role of a user. Tokens already issued keep their
+// old role until they are refreshed.
+func (h *UserHandler) UpdateRole(c *gin.Context) {
+	id := c.Param("id")
+	var dto models.UpdateRoleDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		response.ValidationError(c, errs.FromBinding(err))
+		return
+	}
+	u, err := h.svc.UpdateRole(c.Request.Context(), id, dto.Role)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			response.Error(c, http.StatusNotFound, "user not found")
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "failed to update role")
+		return
+	}
+	response.OK(c, u)
+}
+
 func (h *UserHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 	if err := h.svc.Delete(c.Request.Context(), id); err != nil {
@@ -6,15 +6,19 @@ import (
 	"os"
 	"strings"
 
+	"altoai_mvp/internal/models"
+
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type MyClaims struct {
-	Email   string `json:"email"`
-	Name    string `json:"name"`
-	Picture string `json:"picture"`
-	Type    string `json:"type"`
+	UserID  string      `json:"user_id"`
+	Email   string      `json:"email"`
+	Name    string      `json:"name"`
+	Picture string      `json:"picture"`
+	Role    models.Role `json:"role,omitempty"`
+	Type    string      `json:"type"`
 	jwt.RegisteredClaims
 }
 
@@ -61,4 +65,22 @@ func JWTAuth() gin.HandlerFunc {
 		c.Set("user", claims)
 		c.Next()
 	}
-}
\ No newline at end of file
+}
+
+// CurrentClaims returns the claims stored by JWTAuth, or nil for unauthenticated requests
+func CurrentClaims(c *gin.Context) *MyClaims {
+	v, ok := c.Get("user")
+	if !ok {
+		return nil
+	}
+	claims, _ := v.(*MyClaims)
+	return claims
+}
+
+// CurrentUserID returns the user ID carried in the access token, or "" if there is none
+func CurrentUserID(c *gin.Context) string {
+	if claims := CurrentClaims(c); claims != nil {
+		return claims.UserID
+	}
+	return ""
+}
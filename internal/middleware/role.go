@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+
+	"altoai_mvp/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CurrentRole returns the role carried in the access token. Tokens issued
+// before roles existed count as students.
+func CurrentRole(c *gin.Context) models.Role {
+	claims := CurrentClaims(c)
+	if claims == nil || !claims.Role.Valid() {
+		return models.RoleStudent
+	}
+	return claims.Role
+}
+
+// RequireRole rejects requests whose access token carries none of roles.
+// Admins are always let through. It must run after JWTAuth.
+func RequireRole(roles ...models.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if CurrentClaims(c) == nil {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		role := CurrentRole(c)
+		if role == models.RoleAdmin {
+			c.Next()
+			return
+		}
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatus(http.StatusForbidden)
+	}
+}
@@ -2,12 +2,27 @@ package models
 
 import "time"
 
+// Role controls which endpoints a user may call
+type Role string
+
+const (
+	RoleStudent Role = "student" // default for new users
+	RoleCoach   Role = "coach"   // can follow the progress of students
+	RoleAdmin   Role = "admin"   // can manage users and the question bank
+)
+
+// Valid reports whether r is one of the known roles
+func (r Role) Valid() bool {
+	return r == RoleStudent || r == RoleCoach || r == RoleAdmin
+}
+
 type User struct {
 	ID                      string    `json:"id"`
 	Email                   string    `json:"email"`
 	Name                    string    `json:"name"`
 	Password                string    `json:"-"` // Don't serialize password
 	EmailVerified           bool      `json:"email_verified"`
+	Role                    Role      `json:"role"`
 	College                 string    `json:"college,omitempty"`
 	Major                   string    `json:"major,omitempty"`
 	VerificationCode        string    `json:"-"`
@@ -36,6 +51,10 @@ type UpdateUserDTO struct {
 	Major   *string `json:"major" binding:"omitempty"`
 }
 
+type UpdateRoleDTO struct {
+	Role Role `json:"role" binding:"required,oneof=student coach admin"`
+}
+
 type VerifyEmailDTO struct {
 	Email string `json:"email" binding:"required,email"`
 	Code  string `json:"code" binding:"required,len=6"`
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"altoai_mvp/interview"
+)
+
+type postgresProfileRepo struct {
+	db *sql.DB
+}
+
+// NewPostgresProfileRepo returns an interview.ProfileStore backed by PostgreSQL
+func NewPostgresProfileRepo() (interview.ProfileStore, error) {
+	db, err := openPostgres()
+	if err != nil {
+		return nil, err
+	}
+
+	table := `CREATE TABLE IF NOT EXISTS applicant_profiles (
+		user_id VARCHAR(36) PRIMARY KEY,
+		profile JSONB NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	)`
+	if _, err := db.Exec(table); err != nil {
+		return nil, fmt.Errorf("error creating profile tables: %v", err)
+	}
+
+	return &postgresProfileRepo{db: db}, nil
+}
+
+func (r *postgresProfileRepo) GetProfile(userID string) (*interview.ApplicantProfile, error) {
+	var data []byte
+	err := r.db.QueryRow("SELECT profile FROM applicant_profiles WHERE user_id = $1", userID).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, interview.ErrProfileNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var p interview.ApplicantProfile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("unmarshal profile: %w", err)
+	}
+	p.UserID = userID
+	return &p, nil
+}
+
+func (r *postgresProfileRepo) SaveProfile(p *interview.ApplicantProfile) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("marshal profile: %w", err)
+	}
+	_, err = r.db.Exec(`
+		INSERT INTO applicant_profiles (user_id, profile, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET
+			profile = EXCLUDED.profile,
+			updated_at = EXCLUDED.updated_at`,
+		p.UserID, string(data), p.UpdatedAt,
+	)
+	return err
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"altoai_mvp/interview"
+)
+
+type postgresQuestionRepo struct {
+	db *sql.DB
+}
+
+// NewPostgresQuestionRepo returns an interview.QuestionStore backed by PostgreSQL
+func NewPostgresQuestionRepo() (interview.QuestionStore, error) {
+	db, err := openPostgres()
+	if err != nil {
+		return nil, err
+	}
+
+	tables := []string{
+		`CREATE TABLE IF NOT EXISTS interview_questions (
+			id VARCHAR(255) PRIMARY KEY,
+			category VARCHAR(255) NOT NULL,
+			position INTEGER NOT NULL,
+			retired BOOLEAN NOT NULL DEFAULT FALSE,
+			question JSONB NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_interview_questions_category ON interview_questions(category, position)`,
+	}
+	for _, table := range tables {
+		if _, err := db.Exec(table); err != nil {
+			return nil, fmt.Errorf("error creating question tables: %v", err)
+		}
+	}
+
+	return &postgresQuestionRepo{db: db}, nil
+}
+
+func (r *postgresQuestionRepo) ListQuestions() ([]interview.BankQuestion, error) {
+	rows, err := r.db.Query("SELECT position, retired, question, updated_at FROM interview_questions ORDER BY category, position")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var questions []interview.BankQuestion
+	for rows.Next() {
+		var q interview.BankQuestion
+		var data []byte
+		if err := rows.Scan(&q.Position, &q.Retired, &data, &q.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &q.Question); err != nil {
+			return nil, fmt.Errorf("unmarshal question: %w", err)
+		}
+		questions = append(questions, q)
+	}
+	return questions, rows.Err()
+}
+
+func (r *postgresQuestionRepo) SaveQuestions(questions []interview.BankQuestion) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, q := range questions {
+		data, err := json.Marshal(q.Question)
+		if err != nil {
+			return fmt.Errorf("marshal question: %w", err)
+		}
+		_, err = tx.Exec(`
+			INSERT INTO interview_questions (id, category, position, retired, question, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (id) DO UPDATE SET
+				category = EXCLUDED.category,
+				position = EXCLUDED.position,
+				retired = EXCLUDED.retired,
+				question = EXCLUDED.question,
+				updated_at = EXCLUDED.updated_at`,
+			q.ID, q.Category, q.Position, q.Retired, string(data), q.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
 	"altoai_mvp/internal/models"
@@ -17,23 +18,43 @@ type postgresRepo struct {
 	db *sql.DB
 }
 
-func NewPostgresRepo() (UserRepo, error) {
-	connStr := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		os.Getenv("POSTGRES_HOST"),
-		os.Getenv("POSTGRES_PORT"),
-		os.Getenv("POSTGRES_USER"),
-		os.Getenv("POSTGRES_PASSWORD"),
-		os.Getenv("POSTGRES_DB"),
-	)
+var (
+	sharedDB     *sql.DB
+	sharedDBErr  error
+	sharedDBOnce sync.Once
+)
 
-	db, err := sql.Open("postgres", connStr)
-	if err != nil {
-		return nil, fmt.Errorf("error connecting to database: %v", err)
-	}
+// openPostgres returns the connection pool shared by all PostgreSQL repositories
+func openPostgres() (*sql.DB, error) {
+	sharedDBOnce.Do(func() {
+		connStr := fmt.Sprintf(
+			"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+			os.Getenv("POSTGRES_HOST"),
+			os.Getenv("POSTGRES_PORT"),
+			os.Getenv("POSTGRES_USER"),
+			os.Getenv("POSTGRES_PASSWORD"),
+			os.Getenv("POSTGRES_DB"),
+		)
 
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("error pinging database: %v", err)
+		db, err := sql.Open("postgres", connStr)
+		if err != nil {
+			sharedDBErr = fmt.Errorf("error connecting to database: %v", err)
+			return
+		}
+
+		if err := db.Ping(); err != nil {
+			sharedDBErr = fmt.Errorf("error pinging database: %v", err)
+			return
+		}
+		sharedDB = db
+	})
+	return sharedDB, sharedDBErr
+}
+
+func NewPostgresRepo() (UserRepo, error) {
+	db, err := openPostgres()
+	if err != nil {
+		return nil, err
 	}
 
 	// Create users table if it doesn't exist
@@ -68,6 +89,7 @@ func NewPostgresRepo() (UserRepo, error) {
 		`ALTER TABLE users ADD COLUMN IF NOT EXISTS verification_code_expires TIMESTAMP`,
 		`ALTER TABLE users ADD COLUMN IF NOT EXISTS reset_code VARCHAR(6)`,
 		`ALTER TABLE users ADD COLUMN IF NOT EXISTS reset_code_expires TIMESTAMP`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(16) NOT NULL DEFAULT 'student'`,
 	}
 
 	// Check if password column exists and rename it to password_hash if needed
@@ -110,7 +132,7 @@ func NewPostgresRepo() (UserRepo, error) {
 }
 
 func (r *postgresRepo) List() ([]models.User, error) {
-	rows, err := r.db.Query("SELECT id, email, name, password_hash, email_verified, college, major, verification_code, verification_code_expires, reset_code, reset_code_expires, created_at, updated_at FROM users")
+	rows, err := r.db.Query("SELECT id, email, name, password_hash, email_verified, role, college, major, verification_code, verification_code_expires, reset_code, reset_code_expires, created_at, updated_at FROM users")
 	if err != nil {
 		return nil, err
 	}
@@ -121,7 +143,7 @@ func (r *postgresRepo) List() ([]models.User, error) {
 		var u models.User
 		var passwordHash, verificationCode, resetCode, college, major sql.NullString
 		var verificationCodeExpires, resetCodeExpires sql.NullTime
-		err := rows.Scan(&u.ID, &u.Email, &u.Name, &passwordHash, &u.EmailVerified, &college, &major, &verificationCode, &verificationCodeExpires, &resetCode, &resetCodeExpires, &u.CreatedAt, &u.UpdatedAt)
+		err := rows.Scan(&u.ID, &u.Email, &u.Name, &passwordHash, &u.EmailVerified, &u.Role, &college, &major, &verificationCode, &verificationCodeExpires, &resetCode, &resetCodeExpires, &u.CreatedAt, &u.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -156,9 +178,9 @@ func (r *postgresRepo) Get(id string) (models.User, error) {
 	var passwordHash, verificationCode, resetCode, college, major sql.NullString
 	var verificationCodeExpires, resetCodeExpires sql.NullTime
 	err := r.db.QueryRow(
-		"SELECT id, email, name, password_hash, email_verified, college, major, verification_code, verification_code_expires, reset_code, reset_code_expires, created_at, updated_at FROM users WHERE id = $1",
+		"SELECT id, email, name, password_hash, email_verified, role, college, major, verification_code, verification_code_expires, reset_code, reset_code_expires, created_at, updated_at FROM users WHERE id = $1",
 		id,
-	).Scan(&u.ID, &u.Email, &u.Name, &passwordHash, &u.EmailVerified, &college, &major, &verificationCode, &verificationCodeExpires, &resetCode, &resetCodeExpires, &u.CreatedAt, &u.UpdatedAt)
+	).Scan(&u.ID, &u.Email, &u.Name, &passwordHash, &u.EmailVerified, &u.Role, &college, &major, &verificationCode, &verificationCodeExpires, &resetCode, &resetCodeExpires, &u.CreatedAt, &u.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		return models.User{}, ErrNotFound
@@ -189,9 +211,9 @@ func (r *postgresRepo) GetByEmail(email string) (models.User, error) {
 	var passwordHash, verificationCode, resetCode, college, major sql.NullString
 	var verificationCodeExpires, resetCodeExpires sql.NullTime
 	err := r.db.QueryRow(
-		"SELECT id, email, name, password_hash, email_verified, college, major, verification_code, verification_code_expires, reset_code, reset_code_expires, created_at, updated_at FROM users WHERE email = $1",
+		"SELECT id, email, name, password_hash, email_verified, role, college, major, verification_code, verification_code_expires, reset_code, reset_code_expires, created_at, updated_at FROM users WHERE email = $1",
 		email,
-	).Scan(&u.ID, &u.Email, &u.Name, &passwordHash, &u.EmailVerified, &college, &major, &verificationCode, &verificationCodeExpires, &resetCode, &resetCodeExpires, &u.CreatedAt, &u.UpdatedAt)
+	).Scan(&u.ID, &u.Email, &u.Name, &passwordHash, &u.EmailVerified, &u.Role, &college, &major, &verificationCode, &verificationCodeExpires, &resetCode, &resetCodeExpires, &u.CreatedAt, &u.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		return models.User{}, ErrNotFound
@@ -231,13 +253,14 @@ func (r *postgresRepo) Create(email, name, passwordHash string) (models.User, er
 		Name:          name,
 		Password:      passwordHash,
 		EmailVerified: false,
+		Role:          models.RoleStudent,
 		CreatedAt:     now,
 		UpdatedAt:     now,
 	}
 
 	_, err := r.db.Exec(
-		"INSERT INTO users (id, email, name, password_hash, email_verified, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7)",
-		u.ID, u.Email, u.Name, u.Password, u.EmailVerified, u.CreatedAt, u.UpdatedAt,
+		"INSERT INTO users (id, email, name, password_hash, email_verified, role, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+		u.ID, u.Email, u.Name, u.Password, u.EmailVerified, u.Role, u.CreatedAt, u.UpdatedAt,
 	)
 	if err != nil {
 		return models.User{}, err
@@ -315,6 +338,25 @@ func (r *postgresRepo) UpdateCollegeMajor(id string, college, major *string) (mo
 	return r.Get(id)
 }
 
+func (r *postgresRepo) UpdateRole(id string, role models.Role) (models.User, error) {
+	result, err := r.db.Exec(
+		"UPDATE users SET role = $1, updated_at = $2 WHERE id = $3",
+		role, time.Now().UTC(), id,
+	)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return models.User{}, err
+	}
+	if rowsAffected == 0 {
+		return models.User{}, ErrNotFound
+	}
+	return r.Get(id)
+}
+
 func (r *postgresRepo) Delete(id string) error {
 	result, err := r.db.Exec("DELETE FROM users WHERE id = $1", id)
 	if err != nil {
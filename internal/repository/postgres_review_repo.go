@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"altoai_mvp/interview"
+)
+
+type postgresReviewRepo struct {
+	db *sql.DB
+}
+
+// NewPostgresReviewRepo returns an interview.ReviewStore backed by PostgreSQL
+func NewPostgresReviewRepo() (interview.ReviewStore, error) {
+	db, err := openPostgres()
+	if err != nil {
+		return nil, err
+	}
+
+	table := `CREATE TABLE IF NOT EXISTS interview_reviews (
+		user_id VARCHAR(36) NOT NULL,
+		question_id VARCHAR(255) NOT NULL,
+		category VARCHAR(255) NOT NULL,
+		repetitions INTEGER NOT NULL,
+		ease_factor DOUBLE PRECISION NOT NULL,
+		interval_days INTEGER NOT NULL,
+		last_quality INTEGER NOT NULL,
+		reviewed_at TIMESTAMP NOT NULL,
+		due_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (user_id, question_id)
+	)`
+	if _, err := db.Exec(table); err != nil {
+		return nil, fmt.Errorf("error creating review tables: %v", err)
+	}
+
+	return &postgresReviewRepo{db: db}, nil
+}
+
+func (r *postgresReviewRepo) ListReviews(userID string) ([]interview.ReviewItem, error) {
+	rows, err := r.db.Query(
+		"SELECT user_id, question_id, category, repetitions, ease_factor, interval_days, last_quality, reviewed_at, due_at FROM interview_reviews WHERE user_id = $1",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []interview.ReviewItem
+	for rows.Next() {
+		var item interview.ReviewItem
+		if err := rows.Scan(&item.UserID, &item.QuestionID, &item.Category, &item.Repetitions, &item.EaseFactor, &item.IntervalDays, &item.LastQuality, &item.ReviewedAt, &item.DueAt); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (r *postgresReviewRepo) SaveReview(item interview.ReviewItem) error {
+	_, err := r.db.Exec(`
+		INSERT INTO interview_reviews (user_id, question_id, category, repetitions, ease_factor, interval_days, last_quality, reviewed_at, due_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (user_id, question_id) DO UPDATE SET
+			category = EXCLUDED.category,
+			repetitions = EXCLUDED.repetitions,
+			ease_factor = EXCLUDED.ease_factor,
+			interval_days = EXCLUDED.interval_days,
+			last_quality = EXCLUDED.last_quality,
+			reviewed_at = EXCLUDED.reviewed_at,
+			due_at = EXCLUDED.due_at`,
+		item.UserID, item.QuestionID, item.Category, item.Repetitions, item.EaseFactor, item.IntervalDays, item.LastQuality, item.ReviewedAt, item.DueAt,
+	)
+	return err
+}
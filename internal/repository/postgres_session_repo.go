@@ -0,0 +1,444 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"altoai_mvp/interview"
+
+	"github.com/lib/pq"
+)
+
+type postgresSessionRepo struct {
+	db *sql.DB
+}
+
+// NewPostgresSessionRepo returns an interview.SessionStore backed by PostgreSQL
+func NewPostgresSessionRepo() (interview.SessionStore, error) {
+	db, err := openPostgres()
+	if err != nil {
+		return nil, err
+	}
+
+	tables := []string{
+		`CREATE TABLE IF NOT EXISTS interview_sessions (
+			id VARCHAR(36) PRIMARY KEY,
+			user_id VARCHAR(36),
+			current_question VARCHAR(255),
+			question_index INTEGER NOT NULL DEFAULT 0,
+			status VARCHAR(32) NOT NULL,
+			scores JSONB,
+			summary JSONB,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS interview_session_questions (
+			session_id VARCHAR(36) NOT NULL REFERENCES interview_sessions(id) ON DELETE CASCADE,
+			position INTEGER NOT NULL,
+			question_id VARCHAR(255) NOT NULL,
+			question JSONB NOT NULL,
+			PRIMARY KEY (session_id, position)
+		)`,
+		`CREATE TABLE IF NOT EXISTS interview_answers (
+			session_id VARCHAR(36) NOT NULL REFERENCES interview_sessions(id) ON DELETE CASCADE,
+			position INTEGER NOT NULL,
+			question_id VARCHAR(255) NOT NULL,
+			question_text TEXT NOT NULL,
+			answer_text TEXT NOT NULL,
+			eval JSONB,
+			analysis JSONB,
+			created_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (session_id, position)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_interview_sessions_user_id ON interview_sessions(user_id)`,
+		// Deleted session IDs, so a save racing the delete cannot recreate the session
+		`CREATE TABLE IF NOT EXISTS interview_deleted_sessions (
+			id VARCHAR(36) PRIMARY KEY,
+			deleted_at TIMESTAMP NOT NULL
+		)`,
+	}
+	for _, table := range tables {
+		if _, err := db.Exec(table); err != nil {
+			return nil, fmt.Errorf("error creating interview tables: %v", err)
+		}
+	}
+
+	// Migrate existing tables: add missing columns if they don't exist
+	migrations := []string{
+		`ALTER TABLE interview_sessions ADD COLUMN IF NOT EXISTS level VARCHAR(32)`,
+		`ALTER TABLE interview_answers ADD COLUMN IF NOT EXISTS grading_status VARCHAR(16) NOT NULL DEFAULT ''`,
+		`ALTER TABLE interview_sessions ADD COLUMN IF NOT EXISTS seed BIGINT NOT NULL DEFAULT 0`,
+		`ALTER TABLE interview_sessions ADD COLUMN IF NOT EXISTS visa_type VARCHAR(16) NOT NULL DEFAULT 'F-1'`,
+		`ALTER TABLE interview_sessions ADD COLUMN IF NOT EXISTS summary_attempt VARCHAR(16) NOT NULL DEFAULT ''`,
+		`ALTER TABLE interview_answers ADD COLUMN IF NOT EXISTS attempts JSONB`,
+	}
+	for _, migration := range migrations {
+		if _, err := db.Exec(migration); err != nil {
+			return nil, fmt.Errorf("error running migration: %v", err)
+		}
+	}
+
+	return &postgresSessionRepo{db: db}, nil
+}
+
+func (r *postgresSessionRepo) SaveSession(s *interview.Session) error {
+	s.UpdatedAt = time.Now()
+
+	scores, err := json.Marshal(s.Scores)
+	if err != nil {
+		return fmt.Errorf("marshal scores: %w", err)
+	}
+	summary, err := marshalNullable(s.Summary)
+	if err != nil {
+		return fmt.Errorf("marshal summary: %w", err)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Existing sessions are only updated; a session is inserted when it is new
+	// and was never deleted
+	result, err := tx.Exec(`
+		UPDATE interview_sessions SET
+			user_id = $2,
+			current_question = $3,
+			question_index = $4,
+			status = $5,
+			scores = $6,
+			summary = $7,
+			updated_at = $8,
+			level = $9,
+			seed = $10,
+			visa_type = $11,
+			summary_attempt = $12
+		WHERE id = $1`,
+		s.ID, s.UserID, s.CurrentQuestion, s.QuestionIndex, string(s.Status), string(scores), summary, s.UpdatedAt, s.Level, s.Seed, visaTypeColumn(s.VisaType), s.SummaryAttempt,
+	)
+	if err != nil {
+		return err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		var deleted bool
+		if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM interview_deleted_sessions WHERE id = $1)", s.ID).Scan(&deleted); err != nil {
+			return err
+		}
+		if deleted {
+			return interview.ErrSessionDeleted
+		}
+		_, err = tx.Exec(`
+			INSERT INTO interview_sessions (id, user_id, current_question, question_index, status, scores, summary, created_at, updated_at, level, seed, visa_type, summary_attempt)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+			s.ID, s.UserID, s.CurrentQuestion, s.QuestionIndex, string(s.Status), string(scores), summary, s.CreatedAt, s.UpdatedAt, s.Level, s.Seed, visaTypeColumn(s.VisaType), s.SummaryAttempt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Selected questions and answers are rewritten as a whole; sessions are small
+	if _, err := tx.Exec("DELETE FROM interview_session_questions WHERE session_id = $1", s.ID); err != nil {
+		return err
+	}
+	for i, q := range s.SelectedQuestions {
+		data, err := json.Marshal(q)
+		if err != nil {
+			return fmt.Errorf("marshal question: %w", err)
+		}
+		_, err = tx.Exec(
+			"INSERT INTO interview_session_questions (session_id, position, question_id, question) VALUES ($1, $2, $3, $4)",
+			s.ID, i, q.ID, string(data),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM interview_answers WHERE session_id = $1", s.ID); err != nil {
+		return err
+	}
+	for i, a := range s.Answers {
+		eval, err := marshalNullable(a.Eval)
+		if err != nil {
+			return fmt.Errorf("marshal eval: %w", err)
+		}
+		analysis, err := marshalNullable(a.Analysis)
+		if err != nil {
+			return fmt.Errorf("marshal analysis: %w", err)
+		}
+		var attempts sql.NullString
+		if len(a.Attempts) > 0 {
+			if attempts, err = marshalNullable(&a.Attempts); err != nil {
+				return fmt.Errorf("marshal attempts: %w", err)
+			}
+		}
+		_, err = tx.Exec(
+			"INSERT INTO interview_answers (session_id, position, question_id, question_text, answer_text, eval, analysis, grading_status, created_at, attempts) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)",
+			s.ID, i, a.QuestionID, a.QuestionText, a.Text, eval, analysis, a.GradingStatus, a.CreatedAt, attempts,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// sessionColumns are the interview_sessions columns read by scanSession
+const sessionColumns = "id, user_id, current_question, question_index, status, scores, summary, created_at, updated_at, level, seed, visa_type, summary_attempt"
+
+func (r *postgresSessionRepo) GetSession(id string) (*interview.Session, error) {
+	s, err := scanSession(r.db.QueryRow("SELECT "+sessionColumns+" FROM interview_sessions WHERE id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, interview.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadDetails([]*interview.Session{s}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// scanSession reads a row of sessionColumns, without questions and answers
+func scanSession(row interface{ Scan(...any) error }) (*interview.Session, error) {
+	var s interview.Session
+	var userID, currentQuestion, level sql.NullString
+	var status string
+	var scores, summary []byte
+	err := row.Scan(&s.ID, &userID, &currentQuestion, &s.QuestionIndex, &status, &scores, &summary, &s.CreatedAt, &s.UpdatedAt, &level, &s.Seed, &s.VisaType, &s.SummaryAttempt)
+	if err != nil {
+		return nil, err
+	}
+	s.UserID = userID.String
+	s.CurrentQuestion = currentQuestion.String
+	s.Level = level.String
+	s.Status = interview.SessionStatus(status)
+	if len(scores) > 0 {
+		if err := json.Unmarshal(scores, &s.Scores); err != nil {
+			return nil, fmt.Errorf("unmarshal scores: %w", err)
+		}
+	}
+	if len(summary) > 0 {
+		s.Summary = &interview.SessionSummary{}
+		if err := json.Unmarshal(summary, s.Summary); err != nil {
+			return nil, fmt.Errorf("unmarshal summary: %w", err)
+		}
+	}
+	return &s, nil
+}
+
+func (r *postgresSessionRepo) ListSessions(filter interview.SessionFilter) ([]*interview.Session, int, error) {
+	var conditions []string
+	var args []any
+	addCondition := func(cond string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(cond, len(args)))
+	}
+	if filter.UserID != "" {
+		addCondition("user_id = $%d", filter.UserID)
+	}
+	if filter.Level != "" {
+		addCondition("level = $%d", filter.Level)
+	}
+	if filter.VisaType != "" {
+		addCondition("visa_type = $%d", filter.VisaType)
+	}
+	if filter.Status != "" {
+		addCondition("status = $%d", string(filter.Status))
+	}
+	if !filter.From.IsZero() {
+		addCondition("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		addCondition("created_at < $%d", filter.To)
+	}
+	if filter.PendingGrading {
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM interview_answers a WHERE a.session_id = interview_sessions.id AND a.grading_status = '%s')",
+			interview.GradingStatusPending,
+		))
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM interview_sessions"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := "SELECT " + sessionColumns + " FROM interview_sessions" + where + " ORDER BY created_at DESC"
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET %d", filter.Offset)
+	}
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	sessions := []*interview.Session{}
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			rows.Close()
+			return nil, 0, err
+		}
+		sessions = append(sessions, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.loadDetails(sessions); err != nil {
+		return nil, 0, err
+	}
+	return sessions, total, nil
+}
+
+func (r *postgresSessionRepo) DeleteSession(id string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("DELETE FROM interview_sessions WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return interview.ErrSessionNotFound
+	}
+	if _, err := tx.Exec("INSERT INTO interview_deleted_sessions (id, deleted_at) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING", id, time.Now()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// visaTypeColumn stores sessions from before visa types as F-1, like the migration does
+func visaTypeColumn(visaType string) string {
+	if visaType == "" {
+		return interview.DefaultVisaType
+	}
+	return visaType
+}
+
+// loadDetails reads the selected questions and answers of sessions, one
+// query each for the whole batch
+func (r *postgresSessionRepo) loadDetails(sessions []*interview.Session) error {
+	if len(sessions) == 0 {
+		return nil
+	}
+	byID := make(map[string]*interview.Session, len(sessions))
+	ids := make([]string, len(sessions))
+	for i, s := range sessions {
+		s.SelectedQuestions = []interview.Question{}
+		s.Answers = []interview.Answer{}
+		byID[s.ID] = s
+		ids[i] = s.ID
+	}
+	if err := r.loadQuestions(byID, ids); err != nil {
+		return err
+	}
+	return r.loadAnswers(byID, ids)
+}
+
+func (r *postgresSessionRepo) loadQuestions(byID map[string]*interview.Session, ids []string) error {
+	rows, err := r.db.Query(
+		"SELECT session_id, question FROM interview_session_questions WHERE session_id = ANY($1) ORDER BY session_id, position",
+		pq.Array(ids),
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sessionID string
+		var data []byte
+		if err := rows.Scan(&sessionID, &data); err != nil {
+			return err
+		}
+		var q interview.Question
+		if err := json.Unmarshal(data, &q); err != nil {
+			return fmt.Errorf("unmarshal question: %w", err)
+		}
+		s := byID[sessionID]
+		s.SelectedQuestions = append(s.SelectedQuestions, q)
+	}
+	return rows.Err()
+}
+
+func (r *postgresSessionRepo) loadAnswers(byID map[string]*interview.Session, ids []string) error {
+	rows, err := r.db.Query(
+		"SELECT session_id, question_id, question_text, answer_text, eval, analysis, grading_status, created_at, attempts FROM interview_answers WHERE session_id = ANY($1) ORDER BY session_id, position",
+		pq.Array(ids),
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sessionID string
+		var a interview.Answer
+		var eval, analysis, attempts []byte
+		if err := rows.Scan(&sessionID, &a.QuestionID, &a.QuestionText, &a.Text, &eval, &analysis, &a.GradingStatus, &a.CreatedAt, &attempts); err != nil {
+			return err
+		}
+		if len(eval) > 0 {
+			a.Eval = &interview.EvalResult{}
+			if err := json.Unmarshal(eval, a.Eval); err != nil {
+				return fmt.Errorf("unmarshal eval: %w", err)
+			}
+		}
+		if len(analysis) > 0 {
+			a.Analysis = &interview.AnalysisResponse{}
+			if err := json.Unmarshal(analysis, a.Analysis); err != nil {
+				return fmt.Errorf("unmarshal analysis: %w", err)
+			}
+		}
+		if len(attempts) > 0 {
+			if err := json.Unmarshal(attempts, &a.Attempts); err != nil {
+				return fmt.Errorf("unmarshal attempts: %w", err)
+			}
+		}
+		s := byID[sessionID]
+		s.Answers = append(s.Answers, a)
+	}
+	return rows.Err()
+}
+
+// marshalNullable encodes v as JSON text, returning SQL NULL for nil pointers.
+// JSON is passed as a string because lib/pq sends []byte parameters as bytea.
+func marshalNullable[T any](v *T) (sql.NullString, error) {
+	if v == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
@@ -19,6 +19,7 @@ type UserRepo interface {
 	Create(email, name, passwordHash string) (models.User, error)
 	Update(id string, email, name *string) (models.User, error)
 	UpdateCollegeMajor(id string, college, major *string) (models.User, error)
+	UpdateRole(id string, role models.Role) (models.User, error)
 	Delete(id string) error
 	SetVerificationCode(email, code string, expiresAt time.Time) error
 	VerifyEmail(email, code string) error
@@ -78,6 +79,7 @@ func (r *userMemoryRepo) Create(email, name, passwordHash string) (models.User,
 		Name:          name,
 		Password:      passwordHash,
 		EmailVerified: false,
+		Role:          models.RoleStudent,
 		CreatedAt:     now,
 		UpdatedAt:     now,
 	}
@@ -121,6 +123,19 @@ func (r *userMemoryRepo) UpdateCollegeMajor(id string, college, major *string) (
 	return u, nil
 }
 
+func (r *userMemoryRepo) UpdateRole(id string, role models.Role) (models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.store[id]
+	if !ok {
+		return models.User{}, ErrNotFound
+	}
+	u.Role = role
+	u.UpdatedAt = time.Now().UTC()
+	r.store[id] = u
+	return u, nil
+}
+
 func (r *userMemoryRepo) Delete(id string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
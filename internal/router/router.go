@@ -2,9 +2,12 @@ package router
 
 import (
 	"fmt"
+	"log"
+	"altoai_mvp/interview"
 	"altoai_mvp/internal/auth"
 	"altoai_mvp/internal/handlers"
 	"altoai_mvp/internal/middleware"
+	"altoai_mvp/internal/models"
 	"altoai_mvp/internal/repository"
 	"altoai_mvp/internal/services"
 	"net/http"
@@ -22,12 +25,49 @@ func New() (*gin.Engine, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize PostgreSQL: %v", err)
 	}
+	sessionStore, err := repository.NewPostgresSessionRepo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize session store: %v", err)
+	}
+	interview.SetSessionStore(sessionStore)
+
+	// Admin-editable question bank, seeded from questions.json on first start
+	questionStore, err := repository.NewPostgresQuestionRepo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize question store: %v", err)
+	}
+	interview.SetQuestionStore(questionStore)
+	if err := interview.ReloadQuestionBank(); err != nil {
+		log.Printf("⚠️ Warning: Failed to load the question bank from the database: %v", err)
+	}
+
+	// Spaced-repetition schedules for targeted practice
+	reviewStore, err := repository.NewPostgresReviewRepo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize review store: %v", err)
+	}
+	interview.SetReviewStore(reviewStore)
+
+	// Applicant profiles that answers are graded against
+	profileStore, err := repository.NewPostgresProfileRepo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize profile store: %v", err)
+	}
+	interview.SetProfileStore(profileStore)
+
+	// Background grading for answers the LLM could not grade inline
+	gradingQueue := interview.NewGradingQueue(interview.GradingQueueConfigFromEnv())
+	gradingQueue.Start()
+	interview.SetGradingQueue(gradingQueue)
 
 	userSvc := services.NewUserService(userRepo)
 	authSvc := services.NewAuthService(userRepo)
 	userH := handlers.NewUserHandler(userSvc)
 	authH := handlers.NewAuthHandler(authSvc)
 	chatH := handlers.NewChatHandler(userSvc)
+	interviewH := handlers.NewInterviewHandler(userSvc)
+	questionAdminH := handlers.NewQuestionAdminHandler()
+	profileH := handlers.NewProfileHandler(userSvc)
 
 	// Initialize Google auth with the user repository
 	auth.SetUserRepo(userRepo)
@@ -78,6 +118,7 @@ func New() (*gin.Engine, error) {
 				"email": claims.Email,
 				"name": claims.Name,
 				"picture": claims.Picture,
+				"role": middleware.CurrentRole(c),
 				"college": "",
 				"major": "",
 			})
@@ -87,6 +128,7 @@ func New() (*gin.Engine, error) {
 			"email": dbUser.Email,
 			"name": dbUser.Name,
 			"picture": claims.Picture,
+			"role": services.EffectiveRole(dbUser),
 			"college": dbUser.College,
 			"major": dbUser.Major,
 		})
@@ -106,15 +148,45 @@ func New() (*gin.Engine, error) {
 		v1.POST("/auth/resend-verification", authH.ResendVerificationCode)
 		
 		// User routes
-		v1.GET("/users", userH.List)
-		v1.POST("/users", userH.Create)
-		v1.GET("/users/:id", userH.Get)
-		v1.PUT("/users/:id", middleware.JWTAuth(), userH.Update)
-		v1.DELETE("/users/:id", userH.Delete)
+		adminOnly := middleware.RequireRole(models.RoleAdmin)
+		coachOnly := middleware.RequireRole(models.RoleCoach)
+		v1.GET("/users", middleware.JWTAuth(), adminOnly, userH.List)
+		v1.POST("/users", middleware.JWTAuth(), adminOnly, userH.Create)
+		v1.GET("/users/:id", middleware.JWTAuth(), coachOnly, userH.Get)
+		v1.PUT("/users/:id", middleware.JWTAuth(), adminOnly, userH.Update)
+		v1.PUT("/users/:id/role", middleware.JWTAuth(), adminOnly, userH.UpdateRole)
+		v1.DELETE("/users/:id", middleware.JWTAuth(), adminOnly, userH.Delete)
 		v1.PUT("/users/me/profile", middleware.JWTAuth(), userH.UpdateProfile)
+		v1.GET("/users/me/applicant-profile", middleware.JWTAuth(), profileH.Get)
+		v1.PUT("/users/me/applicant-profile", middleware.JWTAuth(), profileH.Update)
+		v1.POST("/users/me/documents", middleware.JWTAuth(), profileH.UploadDocument)
 		
 		// Chat route (requires auth)
 		v1.POST("/chat", middleware.JWTAuth(), chatH.Chat)
+		v1.POST("/chat/stream", middleware.JWTAuth(), chatH.ChatStream)
+
+		// Levels and visas a chat session can be started with
+		v1.GET("/levels", interviewH.Levels)
+		v1.GET("/visa-types", interviewH.VisaTypes)
+
+		// Interview history (requires auth)
+		interviews := v1.Group("/interviews", middleware.JWTAuth())
+		interviews.GET("", interviewH.List)
+		interviews.GET("/progress", interviewH.Progress)
+		interviews.GET("/:id", interviewH.Get)
+		interviews.GET("/:id/grading", interviewH.Grading)
+		interviews.DELETE("/:id", interviewH.Delete)
+
+		// Cohort analytics (coaches and admins)
+		v1.GET("/cohort/progress", middleware.JWTAuth(), coachOnly, interviewH.CohortProgress)
+
+		// Question bank administration (admins only)
+		admin := v1.Group("/admin", middleware.JWTAuth(), adminOnly)
+		admin.GET("/questions", questionAdminH.List)
+		admin.POST("/questions", questionAdminH.Create)
+		admin.PUT("/questions/order", questionAdminH.Reorder)
+		admin.PUT("/questions/:id", questionAdminH.Update)
+		admin.POST("/questions/:id/retire", questionAdminH.Retire)
 	}
 
 	return r, nil
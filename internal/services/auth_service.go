@@ -72,9 +72,11 @@ func (s *authService) generateAccessToken(user models.User) (string, error) {
 	}
 
 	claims := jwt.MapClaims{
+		"user_id": user.ID,
 		"email":   user.Email,
 		"name":    user.Name,
 		"picture": "",
+		"role":    EffectiveRole(user),
 		"exp":     time.Now().Add(expiry).Unix(),
 		"iat":     time.Now().Unix(),
 		"iss":     "altoai_mvp",
@@ -109,6 +111,7 @@ func (s *authService) generateRefreshToken(user models.User) (string, error) {
 	}
 
 	claims := jwt.MapClaims{
+		"user_id": user.ID,
 		"email":   user.Email,
 		"name":    user.Name,
 		"picture": "",
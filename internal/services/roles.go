@@ -0,0 +1,36 @@
+package services
+
+import (
+	"os"
+	"strings"
+
+	"altoai_mvp/internal/models"
+)
+
+// IsAdminEmail reports whether email is listed in the comma-separated
+// ADMIN_EMAILS environment variable (case-insensitive)
+func IsAdminEmail(email string) bool {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return false
+	}
+	for _, admin := range strings.Split(os.Getenv("ADMIN_EMAILS"), ",") {
+		if strings.EqualFold(strings.TrimSpace(admin), email) {
+			return true
+		}
+	}
+	return false
+}
+
+// EffectiveRole is the role put in a user's access token. Users listed in
+// ADMIN_EMAILS are always admins, so the first admin can be bootstrapped
+// without touching the database; users without a stored role are students.
+func EffectiveRole(u models.User) models.Role {
+	if IsAdminEmail(u.Email) {
+		return models.RoleAdmin
+	}
+	if !u.Role.Valid() {
+		return models.RoleStudent
+	}
+	return u.Role
+}
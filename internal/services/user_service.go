@@ -15,6 +15,7 @@ type UserService interface {
 	GetByEmail(ctx context.Context, email string) (models.User, error)
 	Create(ctx context.Context, dto models.CreateUserDTO) (models.User, error)
 	Update(ctx context.Context, id string, dto models.UpdateUserDTO) (models.User, error)
+	UpdateRole(ctx context.Context, id string, role models.Role) (models.User, error)
 	Delete(ctx context.Context, id string) error
 }
 
@@ -77,6 +78,12 @@ func (s *userService) Update(ctx context.Context, id string, dto models.UpdateUs
 	return user, nil
 }
 
+func (s *userService) UpdateRole(ctx context.Context, id string, role models.Role) (models.User, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	return s.repo.UpdateRole(id, role)
+}
+
 func (s *userService) Delete(ctx context.Context, id string) error {
 	ctx, cancel := s.withTimeout(ctx)
 	defer cancel()
@@ -0,0 +1,344 @@
+package interview
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// DefaultMaxRepairAttempts is how many times the analyzer re-prompts the model
+// with validation errors before giving up on an answer
+const DefaultMaxRepairAttempts = 2
+
+// AnalysisValidationError is returned when the model never produced a valid
+// analysis, even after re-prompting it with the problems found
+type AnalysisValidationError struct {
+	Attempts int
+	Problems []string
+}
+
+func (e *AnalysisValidationError) Error() string {
+	return fmt.Sprintf("invalid analysis after %d attempts: %s", e.Attempts, strings.Join(e.Problems, "; "))
+}
+
+var validClassifications = []string{"Excellent", "Good", "Average", "Weak"}
+
+// analysisResponseSchema is the JSON schema sent to providers that support
+// structured output. It mirrors the format described in the system prompt.
+func analysisResponseSchema() *ResponseSchema {
+	scoreProps := map[string]any{
+		"total_score": map[string]any{"type": "integer"},
+	}
+	feedbackProps := map[string]any{}
+	required := []string{"total_score"}
+	for _, c := range RubricCriteria() {
+		scoreProps[c.Key] = map[string]any{"type": []string{"integer", "null"}, "minimum": c.Scale.Min, "maximum": c.Scale.Max}
+		feedbackProps[c.Key] = map[string]any{"type": "string"}
+		required = append(required, c.Key)
+	}
+
+	return &ResponseSchema{
+		Name: "visa_answer_analysis",
+		Schema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"scores": map[string]any{
+					"type":                 "object",
+					"properties":           scoreProps,
+					"required":             required,
+					"additionalProperties": false,
+				},
+				"classification": map[string]any{"type": "string", "enum": validClassifications},
+				"feedback": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"overall": map[string]any{"type": "string"},
+						"by_criterion": map[string]any{
+							"type":                 "object",
+							"properties":           feedbackProps,
+							"additionalProperties": false,
+						},
+						"improvements": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+					},
+					"required": []string{"overall", "by_criterion", "improvements"},
+				},
+				"improved_version": map[string]any{"type": "string"},
+			},
+			"required": []string{"scores", "classification", "feedback", "improved_version"},
+		},
+	}
+}
+
+// extractJSONObject strips markdown fences and returns the first balanced JSON object
+func extractJSONObject(content string) (string, error) {
+	content = strings.TrimSpace(content)
+
+	// Remove markdown code fences
+	content = strings.TrimPrefix(content, "```json")
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimSuffix(content, "```")
+	content = strings.TrimSpace(content)
+
+	// Extract JSON object more robustly - find first { and matching closing }
+	jsonStart := strings.Index(content, "{")
+	if jsonStart == -1 {
+		return "", fmt.Errorf("no JSON object found in response")
+	}
+
+	// Find the matching closing brace
+	braceCount := 0
+	for i := jsonStart; i < len(content); i++ {
+		if content[i] == '{' {
+			braceCount++
+		} else if content[i] == '}' {
+			braceCount--
+			if braceCount == 0 {
+				return content[jsonStart : i+1], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unmatched braces in JSON response")
+}
+
+// ValidateAnalysis decodes a model reply and checks it against the analysis
+// contract: rubric criteria only, scores are integers on the criterion's scale
+// or null, at least one criterion is scored, and the overall and improvement
+// feedback is present.
+// It returns the decoded analysis and the list of problems found.
+func ValidateAnalysis(content string) (*AnalysisResponse, []string) {
+	raw, err := extractJSONObject(content)
+	if err != nil {
+		return nil, []string{err.Error()}
+	}
+
+	var shape struct {
+		Scores         map[string]json.RawMessage `json:"scores"`
+		Classification string                     `json:"classification"`
+		Feedback       *struct {
+			Overall      string            `json:"overall"`
+			ByCriterion  map[string]string `json:"by_criterion"`
+			Improvements []string          `json:"improvements"`
+		} `json:"feedback"`
+	}
+	if err := json.Unmarshal([]byte(raw), &shape); err != nil {
+		return nil, []string{fmt.Sprintf("response is not valid JSON for the required format: %v", err)}
+	}
+
+	known := make(map[string]Criterion)
+	for _, c := range RubricCriteria() {
+		known[c.Key] = c
+	}
+
+	var problems []string
+	scored := map[string]bool{}
+	if shape.Scores == nil {
+		problems = append(problems, `missing "scores" object`)
+	}
+	for key, value := range shape.Scores {
+		if key == "total_score" {
+			continue
+		}
+		criterion, ok := known[key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown criterion %q in scores", key))
+			continue
+		}
+		if string(value) == "null" {
+			continue
+		}
+		var score int
+		if err := json.Unmarshal(value, &score); err != nil || score < criterion.Scale.Min || score > criterion.Scale.Max {
+			problems = append(problems, fmt.Sprintf("scores.%s must be an integer from %d to %d or null, got %s", key, criterion.Scale.Min, criterion.Scale.Max, string(value)))
+			continue
+		}
+		scored[key] = true
+	}
+	if shape.Scores != nil && len(scored) == 0 {
+		problems = append(problems, "at least one criterion must be scored")
+	}
+
+	if shape.Feedback == nil {
+		problems = append(problems, `missing "feedback" object`)
+	} else {
+		if strings.TrimSpace(shape.Feedback.Overall) == "" {
+			problems = append(problems, "feedback.overall must not be empty")
+		}
+		if len(shape.Feedback.Improvements) == 0 {
+			problems = append(problems, "feedback.improvements must list at least one suggestion")
+		}
+		for key := range shape.Feedback.ByCriterion {
+			if _, ok := known[key]; !ok {
+				problems = append(problems, fmt.Sprintf("unknown criterion %q in feedback.by_criterion", key))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, problems
+	}
+
+	var analysis AnalysisResponse
+	if err := json.Unmarshal([]byte(raw), &analysis); err != nil {
+		return nil, []string{fmt.Sprintf("failed to parse analysis: %v", err)}
+	}
+	return &analysis, nil
+}
+
+// factFigure matches the figures a rewrite most often makes up: dollar
+// amounts ($45,000, 45,000 USD), percentages (3.8%, 90 percent) and dates
+// (08/20/2025, 2025). Counts like "2 years" or "1 minute" are left alone.
+var factFigure = regexp.MustCompile(`\$\s?\d+(?:[.,]\d+)*|\d+(?:[.,]\d+)*\s?(?:%|percent\b|USD\b|dollars\b)|\b\d{1,2}/\d{1,2}/\d{2,4}\b|\b(?:19|20)\d{2}\b`)
+
+// plainNumber matches every number, e.g. 45,000 or 3.8
+var plainNumber = regexp.MustCompile(`\d+(?:[.,]\d+)*`)
+
+// unsupportedFacts lists the figures and names in improved that do not appear
+// in known, the text of what the applicant stated. Numbers are compared on
+// their own, so "45,000 dollars" supports "$45,000"; names are checked by
+// unstatedNames.
+func unsupportedFacts(improved, known string) []string {
+	stated := map[string]bool{}
+	for _, n := range plainNumber.FindAllString(known, -1) {
+		stated[normalizeFigure(n)] = true
+	}
+	var invented []string
+	seen := map[string]bool{}
+	for _, fact := range factFigure.FindAllString(improved, -1) {
+		if seen[fact] {
+			continue
+		}
+		for _, n := range plainNumber.FindAllString(fact, -1) {
+			if !stated[normalizeFigure(n)] {
+				seen[fact] = true
+				invented = append(invented, fact)
+				break
+			}
+		}
+	}
+	return append(invented, unstatedNames(improved, known)...)
+}
+
+// wordPattern matches a word with its inner apostrophes and hyphens, e.g. O'Neil or F-1
+var wordPattern = regexp.MustCompile(`[\p{L}\p{N}]+(?:['’-][\p{L}\p{N}]+)*`)
+
+// placeholder matches the [bracketed] stand-ins for facts the applicant did not give
+var placeholder = regexp.MustCompile(`\[[^\]]*\]`)
+
+// nameConnectors may join the capitalised words of one name, as in
+// "University of Michigan"
+var nameConnectors = map[string]bool{"of": true, "for": true, "de": true}
+
+// sentenceStarters are capitalised only because they open a sentence
+var sentenceStarters = wordSet(`a about after also although an and as at because before both but by
+	currently during each every finally first for from furthermore he her here his however i if in
+	instead it its moreover most my now once only or our over second she since so some specifically
+	that the their then there therefore these they this those to today ultimately upon we what when
+	where which while who why with without yes you your`)
+
+// contextNames are the names a model answer can use without the applicant
+// stating them: the country and language of the interview itself
+var contextNames = wordSet(`america american english united states usa`)
+
+// unstatedNames lists the named entities in improved, runs of capitalised
+// words such as "Fulbright" or "Ohio State University", with a word that does
+// not appear in known. Words are compared without case. Single letters and
+// words with digits (initials, visa types) are not names, a common word is not
+// one just because it opens a sentence, and [Placeholders] are skipped.
+func unstatedNames(improved, known string) []string {
+	improved = placeholder.ReplaceAllString(improved, " ")
+	stated := map[string]bool{}
+	for _, w := range wordPattern.FindAllString(known, -1) {
+		stated[nameKey(w)] = true
+	}
+	isName := func(w string) bool {
+		r := []rune(w)
+		return len(r) > 1 && unicode.IsUpper(r[0]) && !strings.ContainsAny(w, "0123456789")
+	}
+
+	var invented []string
+	seen := map[string]bool{}
+	spans := wordPattern.FindAllStringIndex(improved, -1)
+	for i := 0; i < len(spans); i++ {
+		first := improved[spans[i][0]:spans[i][1]]
+		if !isName(first) {
+			continue
+		}
+		// Extend the name over the capitalised words that follow it
+		end := i
+		for next := i + 1; next < len(spans); next++ {
+			w := improved[spans[next][0]:spans[next][1]]
+			gap := improved[spans[next-1][1]:spans[next][0]]
+			if strings.TrimSpace(gap) != "" {
+				break
+			}
+			if isName(w) {
+				end = next
+				continue
+			}
+			if !nameConnectors[w] {
+				break
+			}
+		}
+
+		unstated := false
+		for j := i; j <= end; j++ {
+			w := improved[spans[j][0]:spans[j][1]]
+			key := nameKey(w)
+			if !isName(w) || stated[key] || contextNames[key] {
+				continue
+			}
+			if j == i && sentenceStarters[key] && opensSentence(improved[:spans[i][0]]) {
+				continue
+			}
+			unstated = true
+		}
+		name := improved[spans[i][0]:spans[end][1]]
+		if unstated && !seen[name] {
+			seen[name] = true
+			invented = append(invented, name)
+		}
+		i = end
+	}
+	return invented
+}
+
+// nameKey compares words without case or a possessive 's
+func nameKey(w string) string {
+	w = strings.ToLower(w)
+	w = strings.TrimSuffix(w, "'s")
+	return strings.TrimSuffix(w, "’s")
+}
+
+// opensSentence reports whether a word preceded by before starts a sentence
+func opensSentence(before string) bool {
+	before = strings.TrimRight(before, " \t\"'“‘(")
+	return before == "" || strings.ContainsAny(before[len(before)-1:], ".!?:\n")
+}
+
+// wordSet builds a lookup set from whitespace-separated words
+func wordSet(words string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+// normalizeFigure drops thousands separators and zero cents, so "45,000"
+// and "45000.00" are the same figure
+func normalizeFigure(n string) string {
+	n = strings.ReplaceAll(n, ",", "")
+	if whole, cents, ok := strings.Cut(n, "."); ok && strings.Trim(cents, "0") == "" {
+		return whole
+	}
+	return n
+}
+
+// repairPrompt asks the model to fix its previous reply
+func repairPrompt(problems []string) string {
+	return "Your previous response did not match the required JSON format:\n- " +
+		strings.Join(problems, "\n- ") +
+		"\nRespond again with only the corrected JSON object."
+}
@@ -0,0 +1,158 @@
+package interview
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Summary attempt settings choose which attempt at a retried question counts
+// in the session summary and scores
+const (
+	SummaryAttemptLatest = "latest"
+	SummaryAttemptBest   = "best"
+)
+
+var (
+	// ErrUnknownSummaryAttempt is returned for a setting other than best or latest
+	ErrUnknownSummaryAttempt = errors.New("unknown summary attempt setting")
+	// ErrQuestionNotAnswered is returned when retrying a question the session has no answer to
+	ErrQuestionNotAnswered = errors.New("question has not been answered in this session")
+	// ErrAnswerPending is returned when retrying a question whose latest attempt is not graded yet
+	ErrAnswerPending = errors.New("answer is still being graded, retry it once it is graded")
+)
+
+// ParseSummaryAttempt checks a summary attempt setting. Empty means latest.
+func ParseSummaryAttempt(setting string) (string, error) {
+	switch s := strings.ToLower(strings.TrimSpace(setting)); s {
+	case "", SummaryAttemptLatest:
+		return SummaryAttemptLatest, nil
+	case SummaryAttemptBest:
+		return s, nil
+	}
+	return "", fmt.Errorf("%w: '%s' (use best or latest)", ErrUnknownSummaryAttempt, setting)
+}
+
+// AnswerAttempt is one attempt at a question. The latest attempt is the
+// Answer itself; earlier ones are kept in Answer.Attempts.
+type AnswerAttempt struct {
+	Text          string            `json:"text"`
+	CreatedAt     time.Time         `json:"created_at"`
+	Eval          *EvalResult       `json:"eval,omitempty"`
+	Analysis      *AnalysisResponse `json:"analysis,omitempty"`
+	GradingStatus string            `json:"grading_status,omitempty"`
+}
+
+// AttemptNumber is the number of the answer's latest attempt, starting at 1
+func (a Answer) AttemptNumber() int {
+	return len(a.Attempts) + 1
+}
+
+// latestAttempt returns the attempt held in the answer's own fields
+func (a Answer) latestAttempt() AnswerAttempt {
+	return AnswerAttempt{
+		Text:          a.Text,
+		CreatedAt:     a.CreatedAt,
+		Eval:          a.Eval,
+		Analysis:      a.Analysis,
+		GradingStatus: a.GradingStatus,
+	}
+}
+
+// withAttempt returns the answer with attempt in its own fields
+func (a Answer) withAttempt(attempt AnswerAttempt) Answer {
+	a.Text = attempt.Text
+	a.CreatedAt = attempt.CreatedAt
+	a.Eval = attempt.Eval
+	a.Analysis = attempt.Analysis
+	a.GradingStatus = attempt.GradingStatus
+	return a
+}
+
+// PreviousAttempt returns the attempt before the latest one, if the question was retried
+func (a Answer) PreviousAttempt() (AnswerAttempt, bool) {
+	if len(a.Attempts) == 0 {
+		return AnswerAttempt{}, false
+	}
+	return a.Attempts[len(a.Attempts)-1], true
+}
+
+// bestAttempt returns the graded attempt with the highest weighted score, the
+// later one on a tie. Answers without a graded attempt keep their latest.
+func (a Answer) bestAttempt() Answer {
+	best, bestPercent := a, -1.0
+	candidates := append(append([]AnswerAttempt(nil), a.Attempts...), a.latestAttempt())
+	for _, attempt := range candidates {
+		if attempt.Analysis == nil {
+			continue
+		}
+		if percent, ok := AnswerPercent(attempt.Analysis.Scores); ok && percent >= bestPercent {
+			best, bestPercent = a.withAttempt(attempt), percent
+		}
+	}
+	return best
+}
+
+// CountedAnswers returns the answers with the attempt the session's summary
+// attempt setting counts in place of the latest one
+func (s *Session) CountedAnswers() []Answer {
+	if s.SummaryAttempt != SummaryAttemptBest {
+		return s.Answers
+	}
+	answers := make([]Answer, len(s.Answers))
+	for i, a := range s.Answers {
+		answers[i] = a.bestAttempt()
+	}
+	return answers
+}
+
+// RecordAttempt stores attempt as the latest answer to its question, keeping
+// the previous attempt in the answer's history. It returns the updated answer.
+// Only the latest attempt is graded in the background, so a question whose
+// latest attempt is pending cannot be retried.
+func (s *Session) RecordAttempt(attempt Answer) (*Answer, error) {
+	for i := range s.Answers {
+		a := &s.Answers[i]
+		if a.QuestionID != attempt.QuestionID {
+			continue
+		}
+		if a.GradingStatus == GradingStatusPending {
+			return nil, ErrAnswerPending
+		}
+		history := append(append([]AnswerAttempt(nil), a.Attempts...), a.latestAttempt())
+		*a = a.withAttempt(attempt.latestAttempt())
+		a.Attempts = history
+		return a, nil
+	}
+	return nil, fmt.Errorf("%w: '%s'", ErrQuestionNotAnswered, attempt.QuestionID)
+}
+
+// FindAnswer returns the answer to a question, if the session has one
+func (s *Session) FindAnswer(questionID string) (*Answer, bool) {
+	for i := range s.Answers {
+		if s.Answers[i].QuestionID == questionID {
+			return &s.Answers[i], true
+		}
+	}
+	return nil, false
+}
+
+// AttemptScoreDelta is how many points of weighted score, from 0 to 100, the
+// latest attempt gained over the one before. It returns false when the
+// question was not retried or either attempt is not graded.
+func AttemptScoreDelta(a Answer) (float64, bool) {
+	previous, ok := a.PreviousAttempt()
+	if !ok || previous.Analysis == nil || a.Analysis == nil {
+		return 0, false
+	}
+	before, ok := AnswerPercent(previous.Analysis.Scores)
+	if !ok {
+		return 0, false
+	}
+	after, ok := AnswerPercent(a.Analysis.Scores)
+	if !ok {
+		return 0, false
+	}
+	return after - before, true
+}
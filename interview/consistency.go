@@ -0,0 +1,333 @@
+package interview
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// FactKind is a kind of claim the consistency check extracts from answers
+type FactKind struct {
+	Key         string
+	Description string
+}
+
+// FactKinds are the facts officers compare across answers
+var FactKinds = []FactKind{
+	{Key: "sponsor", Description: "who pays for the studies or trip and how they are related to the applicant"},
+	{Key: "funding_amount", Description: "amounts of money: tuition, living costs, savings, income, scholarships, loans"},
+	{Key: "university", Description: "the school or host institution"},
+	{Key: "program", Description: "the degree, major or program and its length"},
+	{Key: "gpa", Description: "grades, GPA and test scores"},
+	{Key: "post_study_plan", Description: "what the applicant will do after the program and where"},
+}
+
+// FactClaim is one fact stated in an answer, with the sentence it comes from
+type FactClaim struct {
+	Fact        string `json:"fact"`
+	Value       string `json:"value,omitempty"`
+	Quote       string `json:"quote"`
+	QuestionID  string `json:"questionId"`
+	AnswerIndex int    `json:"answerIndex"` // position in the session's answers
+}
+
+// Contradiction is a fact stated differently in two or more answers
+type Contradiction struct {
+	Fact        string      `json:"fact"`
+	Explanation string      `json:"explanation"`
+	Claims      []FactClaim `json:"claims"`
+}
+
+// ConsistencyReport is the fact sheet of a session and the contradictions found in it
+type ConsistencyReport struct {
+	Facts          []FactClaim
+	Contradictions []Contradiction
+}
+
+const consistencySystemPrompt = `You are an experienced U.S. {{visa}} consular officer reviewing the full transcript of a {{applicant}}'s interview. Officers compare answers with each other: a sponsor, amount, school or plan that changes between answers is a serious red flag.
+
+1. Extract every factual claim the {{applicant}} made into a fact sheet. Use only these facts:
+{{facts}}
+2. List contradictions: answers that state different values for the same fact that cannot both be true. Different wording or rounding is NOT a contradiction ("$40,000" and "about forty thousand dollars" agree), and neither is a fact mentioned in only one answer.
+
+Answers are numbered. For every claim give the answer number and quote the sentence it comes from exactly as written. Do not invent or infer facts.
+
+Respond ONLY with JSON:
+{"facts": [{"fact": "sponsor", "value": "father", "answer": 1, "quote": "My father is paying for my studies."}],
+ "contradictions": [{"fact": "sponsor", "explanation": "string", "claims": [{"fact": "sponsor", "value": "father", "answer": 1, "quote": "string"}, {"fact": "sponsor", "value": "uncle", "answer": 4, "quote": "string"}]}]}`
+
+func consistencySystemPromptFor(v VisaProfile) string {
+	var facts strings.Builder
+	for _, k := range FactKinds {
+		fmt.Fprintf(&facts, "- %s: %s\n", k.Key, k.Description)
+	}
+	return strings.NewReplacer(
+		"{{visa}}", v.Type+" visa",
+		"{{applicant}}", v.Applicant,
+		"{{facts}}", strings.TrimSuffix(facts.String(), "\n"),
+	).Replace(consistencySystemPrompt)
+}
+
+// consistencyReply is the reply format of the consistency prompt
+type consistencyReply struct {
+	Facts          []claimReply `json:"facts"`
+	Contradictions []struct {
+		Fact        string       `json:"fact"`
+		Explanation string       `json:"explanation"`
+		Claims      []claimReply `json:"claims"`
+	} `json:"contradictions"`
+}
+
+type claimReply struct {
+	Fact   string `json:"fact"`
+	Value  string `json:"value"`
+	Answer int    `json:"answer"` // 1-based, as numbered in the prompt
+	Quote  string `json:"quote"`
+}
+
+func consistencyResponseSchema() *ResponseSchema {
+	keys := make([]string, len(FactKinds))
+	for i, k := range FactKinds {
+		keys[i] = k.Key
+	}
+	claim := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"fact":   map[string]any{"type": "string", "enum": keys},
+			"value":  map[string]any{"type": "string"},
+			"answer": map[string]any{"type": "integer", "minimum": 1},
+			"quote":  map[string]any{"type": "string"},
+		},
+		"required":             []string{"fact", "value", "answer", "quote"},
+		"additionalProperties": false,
+	}
+	return &ResponseSchema{
+		Name: "visa_interview_consistency",
+		Schema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"facts": map[string]any{"type": "array", "items": claim},
+				"contradictions": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"fact":        map[string]any{"type": "string", "enum": keys},
+							"explanation": map[string]any{"type": "string"},
+							"claims":      map[string]any{"type": "array", "items": claim, "minItems": 2},
+						},
+						"required":             []string{"fact", "explanation", "claims"},
+						"additionalProperties": false,
+					},
+				},
+			},
+			"required":             []string{"facts", "contradictions"},
+			"additionalProperties": false,
+		},
+	}
+}
+
+// CheckConsistency extracts the facts stated across all answers of s and the
+// contradictions between them. Claims whose quote is not in the answer they
+// cite are dropped, and so are contradictions left with claims from fewer
+// than two answers.
+func (va *VisaAnalyzer) CheckConsistency(ctx context.Context, s *Session) (*ConsistencyReport, error) {
+	if !va.Enabled() {
+		return nil, ErrLLMNotConfigured
+	}
+
+	var transcript strings.Builder
+	for i, a := range s.Answers {
+		fmt.Fprintf(&transcript, "Answer %d\nQuestion: %s\nAnswer: %s\n\n", i+1, a.QuestionText, a.Text)
+	}
+
+	resp, err := va.client.Complete(ctx, LLMRequest{
+		Messages: []GPTMessage{
+			{Role: "system", Content: consistencySystemPromptFor(visaProfile(s.VisaType))},
+			{Role: "user", Content: strings.TrimSpace(transcript.String())},
+		},
+		MaxTokens:      1500,
+		Temperature:    0,
+		ResponseSchema: consistencyResponseSchema(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := extractJSONObject(resp.Content)
+	if err != nil {
+		return nil, err
+	}
+	var reply consistencyReply
+	if err := json.Unmarshal([]byte(raw), &reply); err != nil {
+		return nil, fmt.Errorf("invalid consistency JSON: %w", err)
+	}
+
+	report := &ConsistencyReport{}
+	for _, c := range reply.Facts {
+		if claim, ok := s.verifyClaim(c, ""); ok {
+			report.Facts = append(report.Facts, claim)
+		}
+	}
+	for _, c := range reply.Contradictions {
+		if !isFactKind(c.Fact) {
+			continue
+		}
+		var claims []FactClaim
+		answers := map[int]bool{}
+		for _, cr := range c.Claims {
+			if claim, ok := s.verifyClaim(cr, c.Fact); ok {
+				claims = append(claims, claim)
+				answers[claim.AnswerIndex] = true
+			}
+		}
+		if len(answers) < 2 {
+			continue
+		}
+		report.Contradictions = append(report.Contradictions, Contradiction{
+			Fact:        c.Fact,
+			Explanation: strings.TrimSpace(c.Explanation),
+			Claims:      claims,
+		})
+	}
+	return report, nil
+}
+
+// verifyClaim checks that a claim cites an answer of the session, is about a
+// known fact and quotes that answer. fact, when set, overrides the claim's own.
+func (s *Session) verifyClaim(c claimReply, fact string) (FactClaim, bool) {
+	if fact == "" {
+		fact = c.Fact
+	}
+	index := c.Answer - 1
+	if !isFactKind(fact) || index < 0 || index >= len(s.Answers) {
+		return FactClaim{}, false
+	}
+	answer := s.Answers[index]
+	quote := strings.TrimSpace(c.Quote)
+	if quote == "" || !containsQuote(answer.Text, quote) {
+		return FactClaim{}, false
+	}
+	return FactClaim{
+		Fact:        fact,
+		Value:       strings.TrimSpace(c.Value),
+		Quote:       quote,
+		QuestionID:  answer.QuestionID,
+		AnswerIndex: index,
+	}, true
+}
+
+func isFactKind(key string) bool {
+	for _, k := range FactKinds {
+		if k.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// containsQuote reports whether quote appears in text, ignoring case,
+// whitespace and punctuation the model may add or drop at the ends
+func containsQuote(text, quote string) bool {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.Join(strings.Fields(s), " "))
+	}
+	quote = strings.Trim(normalize(quote), ` .,;:!?"'`)
+	return quote != "" && strings.Contains(normalize(text), quote)
+}
+
+// consistencyKey fingerprints the answer texts a consistency check reads
+func consistencyKey(s *Session) string {
+	h := sha256.New()
+	for _, a := range s.Answers {
+		fmt.Fprintf(h, "%s\x00%s\x00", a.QuestionID, a.Text)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// needsConsistencyCheck reports whether s is finished and its answers changed
+// since the report on its summary was made
+func needsConsistencyCheck(s *Session) bool {
+	return s.Status == SessionStatusFinished && s.Summary != nil && len(s.Answers) >= 2 &&
+		s.Summary.ConsistencyKey != consistencyKey(s)
+}
+
+// consistencyCheckTimeout bounds a check run outside the grading queue
+const consistencyCheckTimeout = 2 * time.Minute
+
+// ScheduleConsistencyCheck hands the consistency check of a finished session
+// to the grading queue, or runs it in the background when no queue is
+// installed. The check keeps the values of ctx but not its cancellation, so it
+// outlives the request that finished the session. Call it without holding the
+// session lock.
+func ScheduleConsistencyCheck(ctx context.Context, sessionID string) {
+	if EnqueueGrading(sessionID) {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), consistencyCheckTimeout)
+		defer cancel()
+		RefreshConsistency(ctx, sessionID)
+	}()
+}
+
+// RefreshConsistency checks the answers of a finished session against each
+// other and attaches the report to its summary. Sessions whose answers have
+// not changed since the last report are skipped. The LLM call is made without
+// holding the session lock, so call it after releasing the lock. The summary
+// is complete without the report, so failures are only logged.
+func RefreshConsistency(ctx context.Context, sessionID string) {
+	va := GetAnalyzer()
+	if va == nil || !va.Enabled() {
+		return
+	}
+	unlock := LockSession(sessionID)
+	s, ok := GetSession(sessionID)
+	if !ok || !needsConsistencyCheck(s) {
+		unlock()
+		return
+	}
+	snapshot := &Session{ID: s.ID, UserID: s.UserID, VisaType: s.VisaType, Answers: append([]Answer(nil), s.Answers...)}
+	key := consistencyKey(snapshot)
+	unlock()
+
+	report, err := va.CheckConsistency(ctx, snapshot)
+	if err != nil {
+		if !errors.Is(err, ErrLLMNotConfigured) && ctx.Err() == nil {
+			log.Printf("Consistency check failed for session %s: %v", sessionID, err)
+		}
+		return
+	}
+
+	unlock = LockSession(sessionID)
+	defer unlock()
+	s, ok = GetSession(sessionID)
+	// Answers retried meanwhile are checked by the next refresh
+	if !ok || s.Summary == nil || consistencyKey(s) != key {
+		return
+	}
+	s.Summary.FactSheet = report.Facts
+	s.Summary.Contradictions = report.Contradictions
+	s.Summary.ConsistencyKey = key
+	if err := SaveSession(s); err != nil {
+		log.Printf("Failed to save consistency report for session %s: %v", sessionID, err)
+	}
+}
+
+// keepConsistency carries the report of the previous summary over to summary
+// while the answers it was made from are unchanged
+func keepConsistency(s *Session, summary *SessionSummary) {
+	previous := s.Summary
+	if previous == nil || previous.ConsistencyKey == "" || previous.ConsistencyKey != consistencyKey(s) {
+		return
+	}
+	summary.FactSheet = previous.FactSheet
+	summary.Contradictions = previous.Contradictions
+	summary.ConsistencyKey = previous.ConsistencyKey
+}
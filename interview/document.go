@@ -0,0 +1,210 @@
+package interview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// MaxDocumentSize caps uploaded documents, in bytes
+const MaxDocumentSize = 10 << 20
+
+// DocumentKind is the kind of paperwork a document is
+type DocumentKind string
+
+const (
+	DocumentI20             DocumentKind = "i20"
+	DocumentAdmissionLetter DocumentKind = "admission_letter"
+	DocumentBankStatement   DocumentKind = "bank_statement"
+)
+
+// Document content types accepted for upload
+const (
+	ContentTypePDF  = "application/pdf"
+	ContentTypePNG  = "image/png"
+	ContentTypeJPEG = "image/jpeg"
+)
+
+var (
+	// ErrUnknownDocumentKind is returned for a kind other than the DocumentKind constants
+	ErrUnknownDocumentKind = errors.New("unknown document kind")
+	// ErrUnsupportedDocument is returned when the extractor cannot read the file format
+	ErrUnsupportedDocument = errors.New("unsupported document format")
+	// ErrNothingExtracted is returned when no field could be read from the document
+	ErrNothingExtracted = errors.New("no fields found in document")
+)
+
+// ParseDocumentKind checks that kind is one of the known document kinds
+func ParseDocumentKind(kind string) (DocumentKind, error) {
+	switch k := DocumentKind(strings.ToLower(strings.TrimSpace(kind))); k {
+	case DocumentI20, DocumentAdmissionLetter, DocumentBankStatement:
+		return k, nil
+	}
+	return "", fmt.Errorf("%w: '%s'", ErrUnknownDocumentKind, kind)
+}
+
+// Document is an uploaded file
+type Document struct {
+	Kind        DocumentKind
+	Filename    string
+	ContentType string // sniffed from Data by NewDocument
+	Data        []byte
+}
+
+// NewDocument wraps an upload, sniffing its content type. Only PDFs and PNG
+// or JPEG images are accepted.
+func NewDocument(kind DocumentKind, filename string, data []byte) (*Document, error) {
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case ContentTypePDF, ContentTypePNG, ContentTypeJPEG:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDocument, contentType)
+	}
+	return &Document{Kind: kind, Filename: filename, ContentType: contentType, Data: data}, nil
+}
+
+// DocumentFields are the key fields read from a document. Fields the
+// document does not show are left empty.
+type DocumentFields struct {
+	School         string `json:"school,omitempty"`
+	Program        string `json:"program,omitempty"`
+	ProgramStart   string `json:"program_start,omitempty"` // YYYY-MM-DD
+	ProgramEnd     string `json:"program_end,omitempty"`
+	EstimatedCost  int    `json:"estimated_cost,omitempty"`  // yearly, in U.S. dollars
+	AvailableFunds int    `json:"available_funds,omitempty"` // balance, in U.S. dollars
+}
+
+func (f DocumentFields) empty() bool {
+	return f == DocumentFields{}
+}
+
+// shownOn keeps the fields a document of the given kind states: the I-20
+// has the school, program dates and cost, an admission letter the school,
+// program and start date, and a bank statement the available funds
+func (f DocumentFields) shownOn(kind DocumentKind) DocumentFields {
+	switch kind {
+	case DocumentI20:
+		f.AvailableFunds = 0
+	case DocumentAdmissionLetter:
+		f.ProgramEnd, f.EstimatedCost, f.AvailableFunds = "", 0, 0
+	case DocumentBankStatement:
+		f = DocumentFields{AvailableFunds: f.AvailableFunds}
+	}
+	return f
+}
+
+// DocumentExtractor reads key fields from an uploaded document. The local
+// TextPDFExtractor handles PDFs with a text layer; scanned documents and
+// images need an extractor backed by an OCR or vision service.
+type DocumentExtractor interface {
+	Extract(ctx context.Context, doc *Document) (*DocumentFields, error)
+}
+
+var (
+	documentExtractor   DocumentExtractor = NewTextPDFExtractor()
+	documentExtractorMu sync.RWMutex
+)
+
+// SetDocumentExtractor replaces the extractor used for uploaded documents
+func SetDocumentExtractor(e DocumentExtractor) {
+	documentExtractorMu.Lock()
+	defer documentExtractorMu.Unlock()
+	documentExtractor = e
+}
+
+// GetDocumentExtractor returns the extractor used for uploaded documents
+func GetDocumentExtractor() DocumentExtractor {
+	documentExtractorMu.RLock()
+	defer documentExtractorMu.RUnlock()
+	return documentExtractor
+}
+
+// ExtractorReads reports whether the configured extractor reads documents of
+// the content type. Extractors that read only some formats, like the local
+// TextPDFExtractor, say so with a ReadsContentType method; others are taken
+// to read everything NewDocument accepts.
+func ExtractorReads(contentType string) bool {
+	e, ok := GetDocumentExtractor().(interface{ ReadsContentType(string) bool })
+	return !ok || e.ReadsContentType(contentType)
+}
+
+// FieldMismatch is a profile field the document shows a different value for
+type FieldMismatch struct {
+	Field    string `json:"field"`
+	Profile  string `json:"profile"`
+	Document string `json:"document"`
+}
+
+// DocumentResult is what an upload read and changed in the applicant profile
+type DocumentResult struct {
+	Kind       DocumentKind      `json:"kind"`
+	Fields     DocumentFields    `json:"fields"`
+	Filled     []string          `json:"filled"`     // profile fields pre-filled from the document
+	Mismatches []FieldMismatch   `json:"mismatches"` // profile fields the document disagrees with
+	Profile    *ApplicantProfile `json:"profile"`
+}
+
+// ImportDocument extracts the key fields of doc and pre-fills the empty
+// fields of the user's applicant profile with them. Fields already filled in
+// are kept and reported as mismatches when the document disagrees.
+func ImportDocument(ctx context.Context, userID string, doc *Document) (*DocumentResult, error) {
+	extracted, err := GetDocumentExtractor().Extract(ctx, doc)
+	if err != nil {
+		return nil, err
+	}
+	fields := extracted.shownOn(doc.Kind)
+	if fields.empty() {
+		return nil, ErrNothingExtracted
+	}
+
+	unlock := LockProfile(userID)
+	defer unlock()
+	profile, err := GetProfile(userID)
+	if errors.Is(err, ErrProfileNotFound) {
+		profile = &ApplicantProfile{UserID: userID}
+	} else if err != nil {
+		return nil, err
+	}
+
+	result := &DocumentResult{Kind: doc.Kind, Fields: fields, Filled: []string{}, Mismatches: []FieldMismatch{}}
+	result.prefill("university", &profile.University, fields.School)
+	result.prefill("program", &profile.Program, fields.Program)
+	result.prefill("program_start", &profile.ProgramStart, fields.ProgramStart)
+	result.prefill("program_end", &profile.ProgramEnd, fields.ProgramEnd)
+	result.prefillAmount("estimated_cost", &profile.EstimatedCost, fields.EstimatedCost)
+	result.prefillAmount("available_funds", &profile.AvailableFunds, fields.AvailableFunds)
+
+	if len(result.Filled) > 0 {
+		if err := SaveProfile(profile); err != nil {
+			return nil, err
+		}
+	}
+	result.Profile = profile
+	return result, nil
+}
+
+func (r *DocumentResult) prefill(field string, current *string, value string) {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "":
+	case *current == "":
+		*current = value
+		r.Filled = append(r.Filled, field)
+	case !strings.EqualFold(*current, value):
+		r.Mismatches = append(r.Mismatches, FieldMismatch{Field: field, Profile: *current, Document: value})
+	}
+}
+
+func (r *DocumentResult) prefillAmount(field string, current *int, value int) {
+	switch {
+	case value <= 0:
+	case *current == 0:
+		*current = value
+		r.Filled = append(r.Filled, field)
+	case *current != value:
+		r.Mismatches = append(r.Mismatches, FieldMismatch{Field: field, Profile: formatDollars(*current), Document: formatDollars(value)})
+	}
+}
@@ -0,0 +1,45 @@
+package interview
+
+import (
+	"context"
+	"sync"
+)
+
+// FakeDocumentExtractor is a DocumentExtractor for tests. It returns the
+// same fields, or Err, for every document and records what it was given.
+type FakeDocumentExtractor struct {
+	Fields DocumentFields
+	Err    error
+
+	mu   sync.Mutex
+	docs []Document
+}
+
+// NewFakeDocumentExtractor returns a fake that extracts fields from every document
+func NewFakeDocumentExtractor(fields DocumentFields) *FakeDocumentExtractor {
+	return &FakeDocumentExtractor{Fields: fields}
+}
+
+// Documents returns a copy of every document received so far
+func (f *FakeDocumentExtractor) Documents() []Document {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]Document, len(f.docs))
+	copy(out, f.docs)
+	return out
+}
+
+func (f *FakeDocumentExtractor) Extract(ctx context.Context, doc *Document) (*DocumentFields, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.docs = append(f.docs, *doc)
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	fields := f.Fields
+	return &fields, nil
+}
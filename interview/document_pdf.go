@@ -0,0 +1,335 @@
+package interview
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxStreamSize caps a decompressed PDF content stream, in bytes
+	maxStreamSize = 4 << 20
+	// maxInflatedSize caps the decompressed streams of a whole PDF, in bytes
+	maxInflatedSize = 16 << 20
+	// maxPDFStreams caps the streams read from a PDF
+	maxPDFStreams = 1000
+)
+
+// TextPDFExtractor reads the text layer of PDFs locally and finds the key
+// fields by their labels. It does no OCR: images and scanned PDFs are
+// rejected with ErrUnsupportedDocument.
+type TextPDFExtractor struct{}
+
+// NewTextPDFExtractor returns the local extractor used when no other is configured
+func NewTextPDFExtractor() *TextPDFExtractor {
+	return &TextPDFExtractor{}
+}
+
+// ReadsContentType reports whether the extractor reads the content type: PDFs only
+func (e *TextPDFExtractor) ReadsContentType(contentType string) bool {
+	return contentType == ContentTypePDF
+}
+
+func (e *TextPDFExtractor) Extract(ctx context.Context, doc *Document) (*DocumentFields, error) {
+	if doc.ContentType != ContentTypePDF {
+		return nil, fmt.Errorf("%w: %s needs an OCR extractor", ErrUnsupportedDocument, doc.ContentType)
+	}
+	text := pdfText(doc.Data)
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("%w: the PDF has no text layer", ErrUnsupportedDocument)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return parseDocumentText(text), nil
+}
+
+// pdfText returns the text shown by the content streams of a PDF, one line
+// per text positioning operator. Flate-compressed streams are inflated;
+// streams with other filters (images, fonts) yield no text. Reading stops
+// after maxPDFStreams streams or maxInflatedSize inflated bytes, so a small
+// upload cannot make it allocate without bound.
+func pdfText(data []byte) string {
+	var out strings.Builder
+	inflated := 0
+	for pos, streams := 0, 0; streams < maxPDFStreams && inflated < maxInflatedSize; {
+		start := bytes.Index(data[pos:], []byte("stream"))
+		if start < 0 {
+			break
+		}
+		start += pos
+		pos = start + len("stream")
+		if start >= 3 && string(data[start-3:start]) == "end" {
+			continue
+		}
+		dict := data[max(0, start-512):start]
+		if i := bytes.LastIndex(dict, []byte("obj")); i >= 0 {
+			dict = dict[i:]
+		}
+
+		body := pos
+		if body < len(data) && data[body] == '\r' {
+			body++
+		}
+		if body < len(data) && data[body] == '\n' {
+			body++
+		}
+		end := bytes.Index(data[body:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		content := data[body : body+end]
+		pos = body + end + len("endstream")
+		streams++
+
+		switch {
+		case bytes.Contains(dict, []byte("/FlateDecode")):
+			r, err := zlib.NewReader(bytes.NewReader(content))
+			if err != nil {
+				continue
+			}
+			// A truncated stream still yields the text before the damage
+			content, _ = io.ReadAll(io.LimitReader(r, int64(min(maxStreamSize, maxInflatedSize-inflated))))
+			r.Close()
+			inflated += len(content)
+		case bytes.Contains(dict, []byte("/Filter")):
+			continue
+		}
+		out.WriteString(contentText(content))
+	}
+	return out.String()
+}
+
+// contentText interprets the text operators of a content stream: strings
+// shown with Tj, TJ, ' and " are written out and Td, TD, T*, Tm and ET start
+// a new line. Large negative kerning in TJ arrays is read as a word gap.
+func contentText(content []byte) string {
+	var out strings.Builder
+	var shown []string
+	inArray := false
+	newline := func() {
+		if s := out.String(); s != "" && !strings.HasSuffix(s, "\n") {
+			out.WriteByte('\n')
+		}
+	}
+
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '(':
+			s, n := pdfLiteralString(content[i:])
+			shown = append(shown, s)
+			i += n
+		case c == '<' && i+1 < len(content) && content[i+1] == '<':
+			i += 2
+		case c == '<':
+			end := bytes.IndexByte(content[i:], '>')
+			if end < 0 {
+				return out.String()
+			}
+			shown = append(shown, pdfHexString(content[i+1:i+end]))
+			i += end + 1
+		case c == '[':
+			inArray = true
+			i++
+		case c == ']':
+			inArray = false
+			i++
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case c == '-' || c == '.' || (c >= '0' && c <= '9'):
+			j := i + 1
+			for j < len(content) && (content[j] == '.' || (content[j] >= '0' && content[j] <= '9')) {
+				j++
+			}
+			if n, err := strconv.ParseFloat(string(content[i:j]), 64); err == nil && inArray && n < -200 {
+				shown = append(shown, " ")
+			}
+			i = j
+		case isPDFOperatorByte(c):
+			j := i + 1
+			for j < len(content) && isPDFOperatorByte(content[j]) {
+				j++
+			}
+			switch string(content[i:j]) {
+			case "Tj", "TJ":
+				out.WriteString(strings.Join(shown, ""))
+			case "'", `"`:
+				newline()
+				out.WriteString(strings.Join(shown, ""))
+			case "Td", "TD", "T*", "Tm", "ET":
+				newline()
+			}
+			shown = nil
+			i = j
+		default:
+			i++
+		}
+	}
+	newline()
+	return out.String()
+}
+
+func isPDFOperatorByte(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '*' || c == '\'' || c == '"'
+}
+
+// pdfLiteralString decodes the (...) string at the start of b and returns it
+// with the number of bytes read
+func pdfLiteralString(b []byte) (string, int) {
+	var s strings.Builder
+	depth := 0
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		switch c {
+		case '(':
+			if depth > 0 {
+				s.WriteByte(c)
+			}
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return s.String(), i + 1
+			}
+			s.WriteByte(c)
+		case '\\':
+			i++
+			if i >= len(b) {
+				return s.String(), i
+			}
+			switch e := b[i]; e {
+			case 'n':
+				s.WriteByte('\n')
+			case 'r':
+				s.WriteByte('\r')
+			case 't':
+				s.WriteByte('\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// Line continuation
+				if e == '\r' && i+1 < len(b) && b[i+1] == '\n' {
+					i++
+				}
+			default:
+				if e >= '0' && e <= '7' {
+					j := i
+					for j < len(b) && j < i+3 && b[j] >= '0' && b[j] <= '7' {
+						j++
+					}
+					n, _ := strconv.ParseUint(string(b[i:j]), 8, 8)
+					s.WriteByte(byte(n))
+					i = j - 1
+				} else {
+					s.WriteByte(e)
+				}
+			}
+		default:
+			s.WriteByte(c)
+		}
+	}
+	return s.String(), len(b)
+}
+
+// pdfHexString decodes the digits of a <...> string, one byte per pair
+func pdfHexString(b []byte) string {
+	digits := strings.Map(func(r rune) rune {
+		if strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return r
+		}
+		return -1
+	}, string(b))
+	if len(digits)%2 == 1 {
+		digits += "0"
+	}
+	var s strings.Builder
+	for i := 0; i < len(digits); i += 2 {
+		n, _ := strconv.ParseUint(digits[i:i+2], 16, 8)
+		s.WriteByte(byte(n))
+	}
+	return s.String()
+}
+
+// Labels of the key fields as printed on I-20s, admission letters and bank
+// statements. The first pattern that matches wins.
+var (
+	documentDate   = `(\d{1,2}/\d{1,2}/\d{4}|\d{4}-\d{2}-\d{2}|[A-Z][a-z]+\.? \d{1,2}, \d{4}|\d{1,2} [A-Z][a-z]+ \d{4})`
+	documentAmount = `([\d,]+(?:\.\d{2})?)`
+	labeledLine    = func(labels string) *regexp.Regexp {
+		return regexp.MustCompile(`(?im)^[ \t]*(?:` + labels + `)[ \t]*:[ \t]*(\S[^\n]*?)[ \t]*$`)
+	}
+	schoolPatterns  = []*regexp.Regexp{labeledLine(`school name|name of school|school|university|institution`)}
+	programPatterns = []*regexp.Regexp{labeledLine(`program of study|major 1|major|program|degree`)}
+	// Admission letters state both in a sentence: "admitted to the MS in
+	// Computer Science program at Ohio State University"
+	admissionSentence = regexp.MustCompile(`(?i)\badmi(?:tted|ssion) to (?:the )?([^.\n]+?) program at (?:the )?([^.,\n]+)`)
+	startPatterns     = []*regexp.Regexp{regexp.MustCompile(`(?i)(?:program start date|start date|program begins?|classes begin)[ \t]*(?:on)?:?[ \t]*` + documentDate)}
+	endPatterns       = []*regexp.Regexp{regexp.MustCompile(`(?i)(?:program end date|end date|completion date|program ends?)[ \t]*(?:on)?:?[ \t]*` + documentDate)}
+	costPatterns      = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)(?:total estimated costs?|estimated total costs?|total costs?|cost of attendance|estimated (?:average )?costs?)\b[^$\d\n]*\$[ \t]*` + documentAmount),
+	}
+	fundsPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)(?:available|closing|ending|current) balance\b[^$\d\n]*\$?[ \t]*` + documentAmount),
+		regexp.MustCompile(`(?i)(?:available funds|total funding|balance)\b[^$\d\n]*\$?[ \t]*` + documentAmount),
+	}
+)
+
+var documentDateLayouts = []string{"01/02/2006", "1/2/2006", time.DateOnly, "January 2, 2006", "Jan. 2, 2006", "Jan 2, 2006", "2 January 2006"}
+
+// parseDocumentText finds the key fields in the text of a document
+func parseDocumentText(text string) *DocumentFields {
+	fields := &DocumentFields{
+		School:  firstMatch(schoolPatterns, text),
+		Program: firstMatch(programPatterns, text),
+	}
+	if m := admissionSentence.FindStringSubmatch(text); m != nil {
+		if fields.Program == "" {
+			fields.Program = strings.TrimSpace(m[1])
+		}
+		if fields.School == "" {
+			fields.School = strings.TrimSpace(m[2])
+		}
+	}
+	fields.ProgramStart = parseDocumentDate(firstMatch(startPatterns, text))
+	fields.ProgramEnd = parseDocumentDate(firstMatch(endPatterns, text))
+	fields.EstimatedCost = parseDocumentAmount(firstMatch(costPatterns, text))
+	fields.AvailableFunds = parseDocumentAmount(firstMatch(fundsPatterns, text))
+	return fields
+}
+
+func firstMatch(patterns []*regexp.Regexp, text string) string {
+	for _, re := range patterns {
+		if m := re.FindStringSubmatch(text); m != nil {
+			return strings.TrimSpace(m[1])
+		}
+	}
+	return ""
+}
+
+// parseDocumentDate returns the date as YYYY-MM-DD, or "" when it is not a date
+func parseDocumentDate(s string) string {
+	for _, layout := range documentDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format(time.DateOnly)
+		}
+	}
+	return ""
+}
+
+// parseDocumentAmount returns whole dollars, dropping cents
+func parseDocumentAmount(s string) int {
+	s, _, _ = strings.Cut(strings.ReplaceAll(s, ",", ""), ".")
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return n
+}
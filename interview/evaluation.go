@@ -1,6 +1,8 @@
 package interview
 
-import "fmt"
+import (
+	"fmt"
+)
 
 // ScoreDelta indicates how one answer should adjust the session scores.
 type ScoreDelta struct {
@@ -32,6 +34,16 @@ func ApplyEval(s *Session, eval *EvalResult) {
 	s.Scores.OverallRisk += eval.ScoreDelta.OverallRisk
 }
 
+// RecomputeScores rebuilds the cumulative scores from the evals stored on the
+// answers, e.g. after answers were graded out of order or a question was retried.
+// Retried questions count the attempt chosen by the session's SummaryAttempt.
+func RecomputeScores(s *Session) {
+	s.Scores = Scores{}
+	for _, a := range s.CountedAnswers() {
+		ApplyEval(s, a.Eval)
+	}
+}
+
 // ApplyAnalysis applies an AnalysisResponse to the session
 // This converts the analysis to EvalResult and applies it, maintaining backward compatibility
 func ApplyAnalysis(s *Session, analysis *AnalysisResponse, q Question) {
@@ -42,7 +54,10 @@ func ApplyAnalysis(s *Session, analysis *AnalysisResponse, q Question) {
 	ApplyEval(s, eval)
 }
 
-// GenerateSessionSummary generates a summary from all answers in the session
+// GenerateSessionSummary generates a summary from all answers in the session.
+// Retried questions count the attempt chosen by the session's SummaryAttempt.
+// The consistency report of the previous summary is kept while the answers
+// are unchanged; RefreshConsistency makes a new one.
 func GenerateSessionSummary(s *Session) (*SessionSummary, error) {
 	if len(s.Answers) == 0 {
 		return nil, fmt.Errorf("no answers in session")
@@ -50,11 +65,12 @@ func GenerateSessionSummary(s *Session) (*SessionSummary, error) {
 
 	// Convert answers to analysis records
 	analyses := make([]AnalysisRecord, 0, len(s.Answers))
-	for _, answer := range s.Answers {
+	for _, answer := range s.CountedAnswers() {
 		if answer.Analysis != nil {
 			analyses = append(analyses, AnalysisRecord{
 				ID:        fmt.Sprintf("analysis_%s_%d", s.ID, len(analyses)),
 				SessionID: s.ID,
+				Category:  s.questionFor(answer).Category,
 				Question:  answer.QuestionText,
 				Answer:    answer.Text,
 				Analysis:  *answer.Analysis,
@@ -78,5 +94,6 @@ func GenerateSessionSummary(s *Session) (*SessionSummary, error) {
 	}
 
 	summary.SessionID = s.ID
+	keepConsistency(s, summary)
 	return summary, nil
 }
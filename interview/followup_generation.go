@@ -0,0 +1,146 @@
+package interview
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxGeneratedFollowupLength caps the length of a generated follow-up question, in characters
+const MaxGeneratedFollowupLength = 200
+
+// FollowupValidationError is returned when the model's follow-up question is unusable
+type FollowupValidationError struct {
+	Problems []string
+}
+
+func (e *FollowupValidationError) Error() string {
+	return "invalid generated follow-up: " + strings.Join(e.Problems, "; ")
+}
+
+// followupSystemPrompt asks for a probing question; {{visa}} and {{applicant}}
+// are filled in per visa by followupSystemPromptFor
+const followupSystemPrompt = `You are an experienced U.S. {{visa}} consular officer. The {{applicant}}'s last answer was vague or weak.
+Ask ONE short follow-up question that refers to something specific the {{applicant}} actually said, the way a real officer would probe it (e.g. "You said your uncle is sponsoring you — what does he do?").
+
+Rules:
+- One question only, ending with a question mark, at most 200 characters
+- Stay within the category of the original question
+- Do not give advice or feedback, only ask
+
+Respond ONLY with JSON:
+{"question": "string", "category": "string"}`
+
+func followupSystemPromptFor(v VisaProfile) string {
+	return strings.NewReplacer("{{visa}}", v.Type+" visa", "{{applicant}}", v.Applicant).Replace(followupSystemPrompt)
+}
+
+// generatedFollowup is the reply format of the follow-up prompt
+type generatedFollowup struct {
+	Question string `json:"question"`
+	Category string `json:"category"`
+}
+
+func followupResponseSchema() *ResponseSchema {
+	return &ResponseSchema{
+		Name: "visa_followup_question",
+		Schema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"question": map[string]any{"type": "string", "maxLength": MaxGeneratedFollowupLength},
+				"category": map[string]any{"type": "string"},
+			},
+			"required":             []string{"question", "category"},
+			"additionalProperties": false,
+		},
+	}
+}
+
+// GenerateFollowup asks the model for a one-off follow-up question about what
+// the student said in answer to current in an F-1 interview. The question is
+// checked against the visa's categories and MaxGeneratedFollowupLength and
+// returned as a synthetic Question with a stable ID derived from current's ID.
+func (va *VisaAnalyzer) GenerateFollowup(ctx context.Context, current Question, answer string) (*Question, error) {
+	return va.GenerateFollowupForVisa(ctx, DefaultVisaType, current, answer)
+}
+
+// GenerateFollowupForVisa is GenerateFollowup for an interview for the given visa type
+func (va *VisaAnalyzer) GenerateFollowupForVisa(ctx context.Context, visaType string, current Question, answer string) (*Question, error) {
+	if !va.Enabled() {
+		return nil, ErrLLMNotConfigured
+	}
+	visa := visaProfile(visaType)
+
+	resp, err := va.client.Complete(ctx, LLMRequest{
+		Messages: []GPTMessage{
+			{Role: "system", Content: followupSystemPromptFor(visa)},
+			{Role: "user", Content: fmt.Sprintf("Category: %s\nQuestion: %s\nStudent's Answer: %s", current.Category, current.Text, answer)},
+		},
+		MaxTokens:      200,
+		Temperature:    0.5,
+		ResponseSchema: followupResponseSchema(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	generated, problems := validateGeneratedFollowup(resp.Content, current, visa)
+	if len(problems) > 0 {
+		return nil, &FollowupValidationError{Problems: problems}
+	}
+	return &Question{
+		ID:         current.ID + "_gen",
+		Category:   generated.Category,
+		Text:       generated.Question,
+		VisaTypes:  []string{visa.Type},
+		IsFollowup: true,
+		ParentID:   current.ID,
+		Generated:  true,
+	}, nil
+}
+
+// validateGeneratedFollowup decodes the reply and checks it is a single short
+// question in a category of the visa. A missing category means current's.
+func validateGeneratedFollowup(content string, current Question, visa VisaProfile) (*generatedFollowup, []string) {
+	raw, err := extractJSONObject(content)
+	if err != nil {
+		return nil, []string{err.Error()}
+	}
+	var g generatedFollowup
+	if err := json.Unmarshal([]byte(raw), &g); err != nil {
+		return nil, []string{fmt.Sprintf("invalid JSON: %v", err)}
+	}
+
+	g.Question = strings.TrimSpace(g.Question)
+	g.Category = strings.TrimSpace(g.Category)
+	if g.Category == "" {
+		g.Category = current.Category
+	}
+
+	var problems []string
+	switch {
+	case g.Question == "":
+		problems = append(problems, "question is empty")
+	case utf8.RuneCountInString(g.Question) > MaxGeneratedFollowupLength:
+		problems = append(problems, fmt.Sprintf("question is longer than %d characters", MaxGeneratedFollowupLength))
+	case strings.ContainsAny(g.Question, "\r\n"):
+		problems = append(problems, "question spans several lines")
+	case !strings.HasSuffix(g.Question, "?"):
+		problems = append(problems, "question does not end with a question mark")
+	}
+	if !visa.hasCategory(g.Category) {
+		problems = append(problems, fmt.Sprintf("category '%s' is not allowed for follow-ups", g.Category))
+	}
+	return &g, problems
+}
+
+// GenerateFollowup generates a follow-up with the shared analyzer
+func GenerateFollowup(ctx context.Context, current Question, answer string) (*Question, error) {
+	va := GetAnalyzer()
+	if va == nil {
+		return nil, ErrAnalyzerNotInitialized
+	}
+	return va.GenerateFollowup(ctx, current, answer)
+}
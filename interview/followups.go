@@ -1,23 +1,155 @@
 package interview
 
-// Optional generic mapping if you want AI to specify a type like "clarify_home_ties".
-var FollowupByType = map[string][]string{
-	"clarify_purpose": {
-		"q1f_clarify_purpose",
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// MaxFollowupsPerSession is the follow-up budget of the built-in levels and of
+// sessions whose level is no longer configured; see Level.FollowupBudget
+const MaxFollowupsPerSession = 2
+
+// followupBank is the follow-up questions loaded from followups.json. It is
+// replaced as a whole on reload, never modified, so a snapshot stays
+// consistent. Follow-ups are not part of the admin-managed question bank;
+// they are edited in followups.json and reloaded with it.
+type followupBank struct {
+	// byType maps a follow-up type like "clarify_home_ties" to the IDs of the
+	// follow-up questions for it, in order of preference
+	byType map[string][]string
+	// questions holds every follow-up question by ID
+	questions map[string]Question
+}
+
+var (
+	followups   = &followupBank{byType: map[string][]string{}, questions: map[string]Question{}}
+	followupsMu sync.RWMutex
+)
+
+func swapFollowups(bank *followupBank) {
+	followupsMu.Lock()
+	defer followupsMu.Unlock()
+	followups = bank
+}
+
+func followupsSnapshot() *followupBank {
+	followupsMu.RLock()
+	defer followupsMu.RUnlock()
+	return followups
+}
+
+// LookupFollowup returns the follow-up question with the given ID
+func LookupFollowup(id string) (Question, bool) {
+	q, ok := followupsSnapshot().questions[id]
+	return q, ok
+}
+
+// FollowupsByType returns the follow-up questions of each type, in order of preference
+func FollowupsByType() map[string][]Question {
+	bank := followupsSnapshot()
+	byType := make(map[string][]Question, len(bank.byType))
+	for followupType, ids := range bank.byType {
+		for _, id := range ids {
+			byType[followupType] = append(byType[followupType], bank.questions[id])
+		}
+	}
+	return byType
+}
+
+// followupTypeByVisa is the follow-up asked when an answer in a category is
+// weak, for each visa
+var followupTypeByVisa = map[string]map[string]string{
+	VisaF1: {
+		"Purpose of Study":      "clarify_purpose",
+		"Academic Background":   "clarify_academic",
+		"University Choice":     "clarify_university",
+		"Financial Capability":  "clarify_financial",
+		"Post-Graduation Plans": "clarify_home_ties",
+		"Immigration Intent":    "clarify_home_ties",
+	},
+	VisaJ1: {
+		"Exchange Program":   "clarify_program",
+		"Program Funding":    "clarify_financial",
+		"Home Country Ties":  "clarify_home_ties",
+		"Return Requirement": "clarify_return",
 	},
-	"clarify_university": {
-		"q2f_university_exact",
+	VisaB1B2: {
+		"Purpose of Trip":   "clarify_trip",
+		"Trip Funding":      "clarify_financial",
+		"Travel History":    "clarify_travel_history",
+		"Home Country Ties": "clarify_home_ties",
 	},
-	"clarify_financial": {
-		"q5f_finance_clarify",
-		"q6f_finance_detail",
+	VisaH1B: {
+		"Job Role":       "clarify_job_role",
+		"Employer":       "clarify_employer",
+		"Qualifications": "clarify_qualifications",
+		"Salary & Terms": "clarify_employment_terms",
 	},
-	"clarify_home_ties": {
-		"q7f_home_country_career",
-		"q8f_ties_detail",
+	VisaM1: {
+		"Vocational Program": "clarify_program",
+		"Program Funding":    "clarify_financial",
+		"Career Plans":       "clarify_home_ties",
+		"Home Country Ties":  "clarify_home_ties",
 	},
 }
 
+// LoadFollowups reads follow-up questions grouped by follow-up type. Like bank
+// questions, follow-ups without visa_types are asked in F-1 sessions only.
+func LoadFollowups(path string) error {
+	bank, err := readFollowups(path)
+	if err != nil {
+		return err
+	}
+	swapFollowups(bank)
+	return nil
+}
+
+// readFollowups reads and checks a follow-ups file without installing it
+func readFollowups(path string) (*followupBank, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read followups file: %w", err)
+	}
+
+	var byType map[string][]Question
+	if err := json.Unmarshal(data, &byType); err != nil {
+		return nil, fmt.Errorf("unmarshal followups: %w", err)
+	}
+
+	followupByType := make(map[string][]string, len(byType))
+	followupQuestions := make(map[string]Question)
+	for followupType, questions := range byType {
+		for _, q := range questions {
+			if q.ID == "" || q.Text == "" {
+				return nil, fmt.Errorf("followup of type '%s' needs an id and text", followupType)
+			}
+			if err := normalizeVisaTypes(&q); err != nil {
+				return nil, err
+			}
+			q.IsFollowup = true
+			followupQuestions[q.ID] = q
+			followupByType[followupType] = append(followupByType[followupType], q.ID)
+		}
+	}
+
+	return &followupBank{byType: followupByType, questions: followupQuestions}, nil
+}
+
+// followupsNextTo reads followups.json from the directory of the questions
+// file. A missing file just disables follow-ups.
+func followupsNextTo(questionsPath string) (*followupBank, error) {
+	path := filepath.Join(filepath.Dir(questionsPath), "followups.json")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return &followupBank{byType: map[string][]string{}, questions: map[string]Question{}}, nil
+	}
+	return readFollowups(path)
+}
+
 // Utility: has this followup been asked already in this session.
 func hasAskedQuestion(s *Session, questionID string) bool {
 	for _, ans := range s.Answers {
@@ -25,37 +157,132 @@ func hasAskedQuestion(s *Session, questionID string) bool {
 			return true
 		}
 	}
+	for _, q := range s.SelectedQuestions {
+		if q.ID == questionID {
+			return true
+		}
+	}
 	return false
 }
 
+// countFollowups returns how many follow-ups were added to the session
+func countFollowups(s *Session) int {
+	n := 0
+	for _, q := range s.SelectedQuestions {
+		if q.IsFollowup {
+			n++
+		}
+	}
+	return n
+}
+
 // DecideNextQuestion uses AI eval plus graph rules to select the next question id.
 func DecideNextQuestion(current Question, s *Session, eval *EvalResult) string {
 	if eval != nil && eval.NeedsFollowup {
-		if next := pickFollowupQuestion(current, eval.SuggestedFollowup, s); next != "" {
+		if next := pickFollowupQuestion(current, followupTypeFor(s, current, eval), s); next != "" {
 			return next
 		}
 	}
 	return current.NextID
 }
 
+// InsertFollowup adds a follow-up from the question bank right after the
+// current question when the eval says the answer was weak. Follow-ups are not
+// followed up themselves and at most the level's follow-up budget is added. It must
+// be called before the session advances, and returns the inserted question or nil.
+func InsertFollowup(s *Session, current Question, eval *EvalResult) *Question {
+	if !followupAllowed(s, current, eval) {
+		return nil
+	}
+	followup := bankFollowup(s, current, eval)
+	if followup == nil {
+		return nil
+	}
+	return InsertNextQuestion(s, *followup)
+}
+
+// PlanFollowup picks the follow-up to ask after a weak answer, under the same
+// rules as InsertFollowup, without inserting it. A question generated from what
+// the student said is preferred; the question bank is the fallback when the
+// analyzer is off or its question fails validation.
+func PlanFollowup(ctx context.Context, s *Session, current Question, answer string, eval *EvalResult) *Question {
+	if !followupAllowed(s, current, eval) {
+		return nil
+	}
+	if va := GetAnalyzer(); va.Enabled() {
+		generated, err := va.GenerateFollowupForVisa(ctx, s.VisaType, current, answer)
+		if err == nil && !hasAskedQuestion(s, generated.ID) {
+			return generated
+		}
+		if err != nil {
+			log.Printf("Falling back to the follow-up bank for %s: %v", current.ID, err)
+		}
+	}
+	return bankFollowup(s, current, eval)
+}
+
+// InsertNextQuestion inserts q right after the current question and returns it
+func InsertNextQuestion(s *Session, q Question) *Question {
+	at := s.QuestionIndex + 1
+	if at > len(s.SelectedQuestions) {
+		at = len(s.SelectedQuestions)
+	}
+	s.SelectedQuestions = append(s.SelectedQuestions[:at], append([]Question{q}, s.SelectedQuestions[at:]...)...)
+	return &s.SelectedQuestions[at]
+}
+
+// followupAllowed reports whether the answer to current may be followed up
+func followupAllowed(s *Session, current Question, eval *EvalResult) bool {
+	if eval == nil || !eval.NeedsFollowup || current.IsFollowup {
+		return false
+	}
+	return countFollowups(s) < followupBudget(s)
+}
+
+// bankFollowup returns the next unused follow-up from the question bank, or nil
+func bankFollowup(s *Session, current Question, eval *EvalResult) *Question {
+	id := pickFollowupQuestion(current, followupTypeFor(s, current, eval), s)
+	if id == "" {
+		return nil
+	}
+	followup, _ := LookupFollowup(id)
+	followup.IsFollowup = true
+	followup.ParentID = current.ID
+	return &followup
+}
+
+// followupTypeFor prefers the follow-up tied to the question's category in the
+// session's visa and falls back to the type guessed from the feedback
+func followupTypeFor(s *Session, current Question, eval *EvalResult) string {
+	if t, ok := followupTypeByVisa[visaProfile(s.VisaType).Type][current.Category]; ok {
+		return t
+	}
+	return eval.SuggestedFollowup
+}
+
 func pickFollowupQuestion(current Question, followupType string, s *Session) string {
 	if followupType == "" {
 		return ""
 	}
 
-	// Allowed followups for this question
+	// Allowed followups for this question; no list means any of the type for the visa
+	visaType := visaProfile(s.VisaType).Type
 	allowed := map[string]bool{}
 	for _, id := range current.FollowupCandidates {
 		allowed[id] = true
 	}
 
-	candidates, ok := FollowupByType[followupType]
+	bank := followupsSnapshot()
+	candidates, ok := bank.byType[followupType]
 	if !ok {
 		return ""
 	}
 
 	for _, id := range candidates {
-		if !allowed[id] {
+		if len(allowed) > 0 && !allowed[id] {
+			continue
+		}
+		if q, ok := bank.questions[id]; !ok || !q.AppliesTo(visaType) {
 			continue
 		}
 		if hasAskedQuestion(s, id) {
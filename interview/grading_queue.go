@@ -0,0 +1,382 @@
+package interview
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// GradingQueueConfig tunes the background grading workers
+type GradingQueueConfig struct {
+	Workers       int           // concurrent sessions being graded
+	RetryInterval time.Duration // delay before retrying while the LLM is unavailable
+	ScanInterval  time.Duration // how often the store is scanned for pending answers
+}
+
+// GradingQueueConfigFromEnv reads GRADING_WORKERS, GRADING_RETRY_INTERVAL and
+// GRADING_SCAN_INTERVAL, defaulting to 2 workers, 30s and 1m
+func GradingQueueConfigFromEnv() GradingQueueConfig {
+	cfg := GradingQueueConfig{Workers: 2, RetryInterval: 30 * time.Second, ScanInterval: time.Minute}
+	if n, err := strconv.Atoi(os.Getenv("GRADING_WORKERS")); err == nil && n > 0 {
+		cfg.Workers = n
+	}
+	if d, err := time.ParseDuration(os.Getenv("GRADING_RETRY_INTERVAL")); err == nil && d > 0 {
+		cfg.RetryInterval = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("GRADING_SCAN_INTERVAL")); err == nil && d > 0 {
+		cfg.ScanInterval = d
+	}
+	return cfg
+}
+
+// GradingQueue grades pending answers in the background. Sessions are queued
+// by ID; a worker grades every pending answer of the session, recomputes the
+// scores and summary, saves it and wakes up anyone waiting on the session.
+// Finished sessions then get their consistency check, outside the lock.
+// Pending answers left over from a restart are found by a periodic store scan.
+type GradingQueue struct {
+	cfg    GradingQueueConfig
+	jobs   chan string
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	queued  map[string]bool
+	waiters map[string][]chan struct{}
+}
+
+// NewGradingQueue creates a stopped queue; call Start to run the workers
+func NewGradingQueue(cfg GradingQueueConfig) *GradingQueue {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = 30 * time.Second
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &GradingQueue{
+		cfg:     cfg,
+		jobs:    make(chan string, 1024),
+		ctx:     ctx,
+		cancel:  cancel,
+		queued:  make(map[string]bool),
+		waiters: make(map[string][]chan struct{}),
+	}
+}
+
+// Start launches the workers and the recovery scan
+func (q *GradingQueue) Start() {
+	for i := 0; i < q.cfg.Workers; i++ {
+		q.wg.Add(1)
+		go q.worker()
+	}
+	q.wg.Add(1)
+	go q.scanLoop()
+}
+
+// Stop cancels in-flight grading and waits for the workers to exit.
+// Unfinished answers stay pending and are picked up by the next scan.
+func (q *GradingQueue) Stop() {
+	q.cancel()
+	q.wg.Wait()
+}
+
+// Enqueue schedules a session for grading. It never blocks; a session that is
+// already queued is not queued twice.
+func (q *GradingQueue) Enqueue(sessionID string) {
+	q.mu.Lock()
+	if q.queued[sessionID] {
+		q.mu.Unlock()
+		return
+	}
+	q.queued[sessionID] = true
+	q.mu.Unlock()
+
+	select {
+	case q.jobs <- sessionID:
+	default:
+		// Full: the next scan will find the session again
+		q.mu.Lock()
+		delete(q.queued, sessionID)
+		q.mu.Unlock()
+		log.Printf("Grading queue full, deferring session %s to the next scan", sessionID)
+	}
+}
+
+// Watch returns a channel that is closed the next time the session's grading
+// state changes. Call stop when no longer interested. Watch before checking
+// the session so a change in between is not missed.
+func (q *GradingQueue) Watch(sessionID string) (changed <-chan struct{}, stop func()) {
+	ch := make(chan struct{})
+	q.mu.Lock()
+	q.waiters[sessionID] = append(q.waiters[sessionID], ch)
+	q.mu.Unlock()
+
+	return ch, func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		list := q.waiters[sessionID]
+		for i, c := range list {
+			if c == ch {
+				q.waiters[sessionID] = append(list[:i], list[i+1:]...)
+				break
+			}
+		}
+		if len(q.waiters[sessionID]) == 0 {
+			delete(q.waiters, sessionID)
+		}
+	}
+}
+
+func (q *GradingQueue) notify(sessionID string) {
+	q.mu.Lock()
+	list := q.waiters[sessionID]
+	delete(q.waiters, sessionID)
+	q.mu.Unlock()
+	for _, ch := range list {
+		close(ch)
+	}
+}
+
+func (q *GradingQueue) worker() {
+	defer q.wg.Done()
+	for {
+		select {
+		case <-q.ctx.Done():
+			return
+		case id := <-q.jobs:
+			q.mu.Lock()
+			delete(q.queued, id)
+			q.mu.Unlock()
+			q.gradeSession(id)
+		}
+	}
+}
+
+func (q *GradingQueue) scanLoop() {
+	defer q.wg.Done()
+	q.scan()
+	if q.cfg.ScanInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(q.cfg.ScanInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-q.ctx.Done():
+			return
+		case <-ticker.C:
+			q.scan()
+		}
+	}
+}
+
+// scan queues every stored session that still has pending answers
+func (q *GradingQueue) scan() {
+	sessions, _, err := ListSessions(SessionFilter{PendingGrading: true})
+	if err != nil {
+		log.Printf("Grading scan failed: %v", err)
+		return
+	}
+	for _, s := range sessions {
+		q.Enqueue(s.ID)
+	}
+}
+
+// pendingAnswer is a snapshot of an answer to grade outside the session lock
+type pendingAnswer struct {
+	index     int
+	question  Question
+	text      string
+	createdAt time.Time
+	history   Session // the session as it was before this answer
+}
+
+type gradingResult struct {
+	analysis *AnalysisResponse
+	err      error
+}
+
+func (q *GradingQueue) gradeSession(sessionID string) {
+	unlock := LockSession(sessionID)
+	s, ok := GetSession(sessionID)
+	if !ok {
+		unlock()
+		return
+	}
+	var pending []pendingAnswer
+	for i, a := range s.Answers {
+		if a.GradingStatus != GradingStatusPending {
+			continue
+		}
+		// Copy the slices: follow-ups are inserted into the live session while
+		// the snapshot is graded outside the lock
+		history := *s
+		history.SelectedQuestions = append([]Question(nil), s.SelectedQuestions...)
+		history.Answers = append([]Answer(nil), s.Answers[:i]...)
+		history.Summary = nil
+		pending = append(pending, pendingAnswer{
+			index:     i,
+			question:  s.questionFor(a),
+			text:      a.Text,
+			createdAt: a.CreatedAt,
+			history:   history,
+		})
+	}
+	unlock()
+	if len(pending) == 0 {
+		RefreshConsistency(q.ctx, sessionID)
+		return
+	}
+
+	// Grade without holding the lock so the interview can continue meanwhile
+	results := make(map[int]gradingResult, len(pending))
+	unavailable := false
+	for _, p := range pending {
+		analysis, err := AnalyzeAnswerContext(q.ctx, &p.history, p.question, p.text)
+		if q.ctx.Err() != nil {
+			return
+		}
+		if errors.Is(err, ErrGradingUnavailable) {
+			// Still down: keep the rest pending and try again later
+			unavailable = true
+			break
+		}
+		results[p.index] = gradingResult{analysis: analysis, err: err}
+	}
+
+	if len(results) > 0 {
+		unlock = LockSession(sessionID)
+		if s, ok := GetSession(sessionID); ok {
+			applyGradingResults(s, pending, results)
+			if err := SaveSession(s); err != nil {
+				log.Printf("Failed to save graded session %s: %v", sessionID, err)
+			}
+		}
+		unlock()
+		q.notify(sessionID)
+	}
+
+	if unavailable {
+		time.AfterFunc(q.cfg.RetryInterval, func() {
+			if q.ctx.Err() == nil {
+				q.Enqueue(sessionID)
+			}
+		})
+		return
+	}
+	RefreshConsistency(q.ctx, sessionID)
+}
+
+// applyGradingResults stores the grades on the answers that are still pending,
+// then recomputes the session scores and, for finished sessions, the summary
+func applyGradingResults(s *Session, pending []pendingAnswer, results map[int]gradingResult) {
+	for _, p := range pending {
+		r, ok := results[p.index]
+		if !ok || p.index >= len(s.Answers) {
+			continue
+		}
+		a := &s.Answers[p.index]
+		// The answer may have been replaced while it was graded
+		if a.QuestionID != p.question.ID || a.GradingStatus != GradingStatusPending ||
+			a.Text != p.text || !a.CreatedAt.Equal(p.createdAt) {
+			continue
+		}
+		if r.err != nil || r.analysis == nil {
+			log.Printf("Deferred grading failed for session %s, question %s: %v", s.ID, a.QuestionID, r.err)
+			a.GradingStatus = GradingStatusFailed
+			continue
+		}
+		a.Analysis = r.analysis
+		a.Eval = ConvertAnalysisToEval(r.analysis, p.question)
+		a.GradingStatus = GradingStatusGraded
+		// Retries are not reviews: the schedule follows the first attempt
+		if len(a.Attempts) == 0 {
+			RecordReview(s.UserID, p.question, r.analysis)
+		}
+	}
+
+	RecomputeScores(s)
+	if s.Status == SessionStatusFinished {
+		if summary, err := GenerateSessionSummary(s); err == nil && summary != nil {
+			s.Summary = summary
+		}
+	}
+}
+
+// questionFor returns the selected question an answer belongs to, falling back
+// to the text stored on the answer
+func (s *Session) questionFor(a Answer) Question {
+	for _, q := range s.SelectedQuestions {
+		if q.ID == a.QuestionID {
+			return q
+		}
+	}
+	return Question{ID: a.QuestionID, Text: a.QuestionText}
+}
+
+// HasPendingGrading reports whether any answer is waiting to be graded
+func (s *Session) HasPendingGrading() bool {
+	for _, a := range s.Answers {
+		if a.GradingStatus == GradingStatusPending {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	gradingQueue   *GradingQueue
+	gradingQueueMu sync.RWMutex
+)
+
+// SetGradingQueue installs the queue used by EnqueueGrading and WaitForGrading
+func SetGradingQueue(q *GradingQueue) {
+	gradingQueueMu.Lock()
+	defer gradingQueueMu.Unlock()
+	gradingQueue = q
+}
+
+// GetGradingQueue returns the installed queue, or nil when grading is synchronous only
+func GetGradingQueue() *GradingQueue {
+	gradingQueueMu.RLock()
+	defer gradingQueueMu.RUnlock()
+	return gradingQueue
+}
+
+// EnqueueGrading schedules the session for background grading. It reports
+// false when no queue is installed.
+func EnqueueGrading(sessionID string) bool {
+	q := GetGradingQueue()
+	if q == nil {
+		return false
+	}
+	q.Enqueue(sessionID)
+	return true
+}
+
+// WatchGrading is GradingQueue.Watch on the installed queue. Without a queue
+// nothing will change, so it returns a nil channel that never fires.
+func WatchGrading(sessionID string) (<-chan struct{}, func()) {
+	q := GetGradingQueue()
+	if q == nil {
+		return nil, func() {}
+	}
+	return q.Watch(sessionID)
+}
+
+// sessionLocks holds the lock of each session in use
+var sessionLocks keyedLocks
+
+// LockSession serializes changes to one session between request handlers and
+// grading workers. Call the returned function once to release the lock. Locks
+// are dropped when nobody holds or waits for them, so finished and deleted
+// sessions do not keep one.
+func LockSession(sessionID string) func() {
+	return sessionLocks.lock(sessionID)
+}
@@ -7,16 +7,43 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// CurrentUser resolves the ID of the authenticated caller. The HTTP layer
+// supplies it, so this package does not read auth state itself.
+type CurrentUser func(c *gin.Context) (string, error)
+
+// callerID resolves the caller, answering 401 when there is none. An empty ID
+// would own every session saved without a user, so it is rejected too.
+func callerID(c *gin.Context, currentUser CurrentUser) (string, bool) {
+	userID, err := currentUser(c)
+	if err != nil || userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
+		return "", false
+	}
+	return userID, true
+}
+
 type CreateSessionResponse struct {
 	SessionID    string `json:"session_id"`
 	QuestionID   string `json:"question_id"`
 	QuestionText string `json:"question_text"`
 }
 
-func CreateSessionHandler(c *gin.Context) {
-	// In future you can extract userID from auth
-	s := NewSession("")
-	SaveSession(s)
+func CreateSessionHandler(currentUser CurrentUser) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := callerID(c, currentUser)
+		if !ok {
+			return
+		}
+		createSession(c, userID)
+	}
+}
+
+func createSession(c *gin.Context, userID string) {
+	s := NewSession(userID)
+	if err := SaveSession(s); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save session"})
+		return
+	}
 
 	if len(s.SelectedQuestions) == 0 {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "no questions selected for session"})
@@ -46,7 +73,17 @@ type SubmitAnswerResponse struct {
 	CurrentQuestion  string `json:"current_question,omitempty"`
 }
 
-func SubmitAnswerHandler(c *gin.Context) {
+func SubmitAnswerHandler(currentUser CurrentUser) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := callerID(c, currentUser)
+		if !ok {
+			return
+		}
+		submitAnswer(c, userID)
+	}
+}
+
+func submitAnswer(c *gin.Context, userID string) {
 	sessionID := c.Param("id")
 	if sessionID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "missing session id"})
@@ -59,11 +96,18 @@ func SubmitAnswerHandler(c *gin.Context) {
 		return
 	}
 
+	unlock := LockSession(sessionID)
+	defer unlock()
+
 	s, ok := GetSession(sessionID)
 	if !ok {
 		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
 		return
 	}
+	if !s.OwnedBy(userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": ErrSessionForbidden.Error()})
+		return
+	}
 
 	if s.Status != SessionStatusActive {
 		resp := SubmitAnswerResponse{
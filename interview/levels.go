@@ -0,0 +1,219 @@
+package interview
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrUnknownLevel is returned when a session asks for a level that is not configured
+var ErrUnknownLevel = errors.New("unknown level")
+
+// LevelCategory is how many questions a level asks from one category
+type LevelCategory struct {
+	Category string `json:"category"`
+	Count    int    `json:"count"`
+}
+
+// Level defines how the questions of a session are selected. Categories are
+// asked in the order listed.
+type Level struct {
+	Name       string          `json:"name"`
+	Strategy   string          `json:"strategy,omitempty"` // random (default) or targeted
+	Categories []LevelCategory `json:"categories"`
+	// Questions is the session length of a targeted level, where Count is the
+	// most questions asked from a category
+	Questions int `json:"questions,omitempty"`
+	// ExtraRandom adds that many questions from randomly picked categories of the level
+	ExtraRandom int `json:"extra_random,omitempty"`
+	// FollowupBudget caps how many follow-up questions the session can add
+	FollowupBudget int `json:"followup_budget"`
+	// Time limits for the client to enforce; 0 means no limit
+	AnswerTimeLimitSeconds  int `json:"answer_time_limit_seconds,omitempty"`
+	SessionTimeLimitSeconds int `json:"session_time_limit_seconds,omitempty"`
+}
+
+// LevelConfig is the levels.json format
+type LevelConfig struct {
+	Default string  `json:"default"` // level used when a session does not name one
+	Levels  []Level `json:"levels"`
+}
+
+var (
+	levelConfig   = defaultLevelConfig()
+	levelConfigMu sync.RWMutex
+)
+
+// defaultLevelConfig is used when no levels.json sits next to questions.json
+func defaultLevelConfig() *LevelConfig {
+	every := func(count int) []LevelCategory {
+		categories := make([]LevelCategory, 0, len(CategoryOrder))
+		for _, c := range CategoryOrder {
+			categories = append(categories, LevelCategory{Category: c, Count: count})
+		}
+		return categories
+	}
+	return &LevelConfig{
+		Default: "hard",
+		Levels: []Level{
+			{
+				Name: "easy",
+				Categories: []LevelCategory{
+					{"Purpose of Study", 1},
+					{"Academic Background", 1},
+					{"University Choice", 1},
+					{"Post-Graduation Plans", 1},
+				},
+				FollowupBudget: MaxFollowupsPerSession,
+			},
+			{Name: "medium", Categories: every(1), ExtraRandom: 1, FollowupBudget: MaxFollowupsPerSession},
+			{Name: "hard", Categories: every(2), FollowupBudget: MaxFollowupsPerSession},
+			{Name: "targeted", Strategy: LevelStrategyTargeted, Categories: every(2), Questions: 8, FollowupBudget: MaxFollowupsPerSession},
+		},
+	}
+}
+
+// LoadLevels reads level definitions and makes them the configured levels
+func LoadLevels(path string) error {
+	config, err := readLevels(path)
+	if err != nil {
+		return err
+	}
+	SetLevelConfig(config)
+	return nil
+}
+
+// readLevels reads and validates a levels file without installing it
+func readLevels(path string) (*LevelConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read levels file: %w", err)
+	}
+
+	var config LevelConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("unmarshal levels: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// levelsNextTo reads levels.json from the directory of questions.json,
+// falling back to the built-in levels when there is none
+func levelsNextTo(questionsPath string) (*LevelConfig, error) {
+	path := filepath.Join(filepath.Dir(questionsPath), "levels.json")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return defaultLevelConfig(), nil
+	}
+	return readLevels(path)
+}
+
+// Validate checks that level names are unique, every level selects at least
+// one question and the default level exists
+func (c *LevelConfig) Validate() error {
+	if len(c.Levels) == 0 {
+		return errors.New("levels file defines no levels")
+	}
+	names := make(map[string]bool, len(c.Levels))
+	for _, l := range c.Levels {
+		if l.Name == "" {
+			return errors.New("level needs a name")
+		}
+		if names[l.Name] {
+			return fmt.Errorf("duplicate level '%s'", l.Name)
+		}
+		names[l.Name] = true
+		if len(l.Categories) == 0 {
+			return fmt.Errorf("level '%s' has no categories", l.Name)
+		}
+		for _, lc := range l.Categories {
+			if lc.Category == "" || lc.Count <= 0 {
+				return fmt.Errorf("level '%s' needs a category and a positive count for every entry", l.Name)
+			}
+		}
+		switch l.Strategy {
+		case "", LevelStrategyRandom:
+		case LevelStrategyTargeted:
+			if l.Questions <= 0 {
+				return fmt.Errorf("targeted level '%s' needs a positive number of questions", l.Name)
+			}
+		default:
+			return fmt.Errorf("level '%s' has unknown strategy '%s'", l.Name, l.Strategy)
+		}
+		if l.ExtraRandom < 0 || l.FollowupBudget < 0 || l.AnswerTimeLimitSeconds < 0 || l.SessionTimeLimitSeconds < 0 {
+			return fmt.Errorf("level '%s' has a negative limit", l.Name)
+		}
+	}
+	if !names[c.Default] {
+		return fmt.Errorf("default level '%s' is not defined", c.Default)
+	}
+	return nil
+}
+
+// SetLevelConfig replaces the configured levels. The config must be valid.
+func SetLevelConfig(config *LevelConfig) {
+	levelConfigMu.Lock()
+	defer levelConfigMu.Unlock()
+	levelConfig = config
+}
+
+// Levels returns the configured levels in config order
+func Levels() []Level {
+	levelConfigMu.RLock()
+	defer levelConfigMu.RUnlock()
+	return append([]Level(nil), levelConfig.Levels...)
+}
+
+// LookupLevel returns the level with the given name, or the default level when
+// name is empty. Unknown names return ErrUnknownLevel.
+func LookupLevel(name string) (Level, error) {
+	levelConfigMu.RLock()
+	defer levelConfigMu.RUnlock()
+	if name == "" {
+		name = levelConfig.Default
+	}
+	for _, l := range levelConfig.Levels {
+		if l.Name == name {
+			return l, nil
+		}
+	}
+	return Level{}, fmt.Errorf("%w '%s'", ErrUnknownLevel, name)
+}
+
+// levelCategories lists every category used by a configured level. The
+// question bank must keep questions in all of them.
+func levelCategories() []string {
+	levelConfigMu.RLock()
+	defer levelConfigMu.RUnlock()
+	return levelConfig.categories()
+}
+
+// categories lists the categories of every level, in level order
+func (c *LevelConfig) categories() []string {
+	seen := make(map[string]bool)
+	var categories []string
+	for _, l := range c.Levels {
+		for _, lc := range l.Categories {
+			if !seen[lc.Category] {
+				seen[lc.Category] = true
+				categories = append(categories, lc.Category)
+			}
+		}
+	}
+	return categories
+}
+
+// followupBudget is the follow-up budget of the session's level. Sessions
+// whose level is no longer configured get MaxFollowupsPerSession.
+func followupBudget(s *Session) int {
+	level, err := LookupLevel(s.Level)
+	if err != nil {
+		return MaxFollowupsPerSession
+	}
+	return level.FollowupBudget
+}
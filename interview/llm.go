@@ -1,40 +1,57 @@
 package interview
 
 import (
-	"fmt"
-	"os"
+	"context"
 	"strings"
 	"sync"
 )
 
 var (
-	analyzer     *VisaAnalyzer
-	analyzerOnce sync.Once
+	analyzer   *VisaAnalyzer
+	analyzerMu sync.Mutex
 )
 
-// GetAnalyzer returns a singleton VisaAnalyzer instance
+// GetAnalyzer returns the shared VisaAnalyzer, creating it from the environment
+// (see LLMConfigFromEnv) on first use
 func GetAnalyzer() *VisaAnalyzer {
-	analyzerOnce.Do(func() {
-		apiKey := os.Getenv("OPENAI_API_KEY")
-		if apiKey == "" {
-			apiKey = os.Getenv("GPT_API_KEY")
-		}
-		analyzer = NewVisaAnalyzer(apiKey)
-	})
+	analyzerMu.Lock()
+	defer analyzerMu.Unlock()
+	if analyzer == nil {
+		analyzer = NewVisaAnalyzer("")
+	}
 	return analyzer
 }
 
+// SetAnalyzer replaces the shared VisaAnalyzer, e.g. with one using a fake client in tests
+func SetAnalyzer(va *VisaAnalyzer) {
+	analyzerMu.Lock()
+	defer analyzerMu.Unlock()
+	analyzer = va
+}
+
 // AnalyzeAnswer analyzes a question-answer pair using the VisaAnalyzer with session context
 // This replaces the old CallLLM function and provides detailed feedback
 func AnalyzeAnswer(session *Session, q Question, answer string) (*AnalysisResponse, error) {
+	return AnalyzeAnswerContext(context.Background(), session, q, answer)
+}
+
+// AnalyzeAnswerContext is AnalyzeAnswer bounded by ctx, e.g. the HTTP request's context.
+// Errors matching ErrGradingUnavailable mean the answer can be graded later.
+func AnalyzeAnswerContext(ctx context.Context, session *Session, q Question, answer string) (*AnalysisResponse, error) {
+	return AnalyzeAnswerStream(ctx, session, q, answer, nil)
+}
+
+// AnalyzeAnswerStream is AnalyzeAnswerContext that also passes the model's
+// output to onDelta while it is being generated
+func AnalyzeAnswerStream(ctx context.Context, session *Session, q Question, answer string, onDelta func(string)) (*AnalysisResponse, error) {
 	va := GetAnalyzer()
 	if va == nil {
 		return nil, ErrAnalyzerNotInitialized
 	}
-	if va.apiKey == "" {
-		return nil, fmt.Errorf("API key not set for analyzer")
+	if !va.Enabled() {
+		return nil, ErrLLMNotConfigured
 	}
-	return va.AnalyzeAnswerWithSession(session, q.Category, q.Text, answer)
+	return va.AnalyzeAnswerWithSessionStream(ctx, session, q.Category, q.Text, answer, onDelta)
 }
 
 // CallLLM is kept for backward compatibility but now uses the new analyzer
@@ -60,43 +77,10 @@ func ConvertAnalysisToEval(analysis *AnalysisResponse, q Question) *EvalResult {
 // convertAnalysisToEval converts the new AnalysisResponse to the old EvalResult format
 // This allows backward compatibility with existing code
 func convertAnalysisToEval(analysis *AnalysisResponse, q Question) *EvalResult {
-	// New grading system: scores are on a 3–15 scale (via AnalysisScores.TotalScore)
-	// We convert this to a 0–100 percentage using ScoreToPercentage, then to 0–10 buckets.
-
-	// Safeguard if scores are missing
-	totalScore := 0
-	if analysis.Scores.TotalScore != 0 {
-		totalScore = analysis.Scores.TotalScore
-	}
-
-	// Count relevant criteria
-	criteriaCount := 0
-	if analysis.Scores.MigrationIntent != nil {
-		criteriaCount++
-	}
-	if analysis.Scores.FinancialUnderstanding != nil {
-		criteriaCount++
-	}
-	if analysis.Scores.AcademicCredibility != nil {
-		criteriaCount++
-	}
-	if analysis.Scores.SpecificityResearch != nil {
-		criteriaCount++
-	}
-	if analysis.Scores.Consistency != nil {
-		criteriaCount++
-	}
-	if analysis.Scores.CommunicationQuality != nil {
-		criteriaCount++
-	}
-	if analysis.Scores.RedFlags != nil {
-		criteriaCount++
-	}
-	if criteriaCount == 0 {
-		criteriaCount = 1 // Avoid division by zero
-	}
-
-	percentage := int(ScoreToPercentage(totalScore, criteriaCount)) // 0–100
+	// The weighted score from the scoring module is a 0–100 percentage,
+	// converted to the 0–10 buckets of the old format
+	weighted, _ := AnswerPercent(analysis.Scores)
+	percentage := int(weighted)
 
 	// Map overall percentage (0–100) to quality (0–10)
 	quality := percentage / 10
@@ -107,16 +91,16 @@ func convertAnalysisToEval(analysis *AnalysisResponse, q Question) *EvalResult {
 	// Map criteria scores (1–5) to 0–10 scale using simple *2 scaling
 	// Use communication_quality as a proxy for clarity
 	clarity := 0
-	if analysis.Scores.CommunicationQuality != nil {
-		clarity = *analysis.Scores.CommunicationQuality * 2
+	if score := analysis.Scores.Score("communication_quality"); score != nil {
+		clarity = *score * 2
 		if clarity > 10 {
 			clarity = 10
 		}
 	}
 	// Use specificity_research as a proxy for confidence
 	confidence := 0
-	if analysis.Scores.SpecificityResearch != nil {
-		confidence = *analysis.Scores.SpecificityResearch * 2
+	if score := analysis.Scores.Score("specificity_research"); score != nil {
+		confidence = *score * 2
 		if confidence > 10 {
 			confidence = 10
 		}
@@ -134,7 +118,7 @@ func convertAnalysisToEval(analysis *AnalysisResponse, q Question) *EvalResult {
 	suggestedFollowup := ""
 	if needsFollowup {
 		// Use feedback text to guess the main followup area
-		feedbackText := analysis.Feedback.Overall + " " + analysis.Feedback.ByCriterion.SpecificityResearch
+		feedbackText := analysis.Feedback.Overall + " " + analysis.Feedback.ByCriterion["specificity_research"]
 		if contains(feedbackText, "purpose", "study", "why", "goal") {
 			suggestedFollowup = "clarify_purpose"
 		} else if contains(feedbackText, "university", "school", "college", "program") {
@@ -0,0 +1,109 @@
+package interview
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultAnthropicBaseURL = "https://api.anthropic.com/v1"
+	defaultAnthropicModel   = "claude-3-5-haiku-latest"
+	anthropicAPIVersion     = "2023-06-01"
+	// The Messages API requires max_tokens on every request
+	defaultAnthropicMaxTokens = 1024
+)
+
+// AnthropicClient talks to Anthropic's Messages API
+type AnthropicClient struct {
+	apiKey     string
+	baseURL    string
+	model      string
+	httpClient *http.Client
+}
+
+// NewAnthropicClient creates a Messages API client. Empty baseURL and model
+// fall back to the public endpoint and a default model.
+func NewAnthropicClient(apiKey, baseURL, model string, httpClient *http.Client) *AnthropicClient {
+	if baseURL == "" {
+		baseURL = defaultAnthropicBaseURL
+	}
+	if model == "" {
+		model = defaultAnthropicModel
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &AnthropicClient{
+		apiKey:     apiKey,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		model:      model,
+		httpClient: httpClient,
+	}
+}
+
+func (c *AnthropicClient) Complete(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	type anthropicRequest struct {
+		Model       string       `json:"model"`
+		System      string       `json:"system,omitempty"`
+		Messages    []GPTMessage `json:"messages"`
+		MaxTokens   int          `json:"max_tokens"`
+		Temperature float64      `json:"temperature"`
+	}
+
+	type anthropicResponse struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+
+	system, turns := systemAndTurns(req.Messages)
+	maxTokens := req.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultAnthropicMaxTokens
+	}
+
+	reqBody, err := json.Marshal(anthropicRequest{
+		Model:       c.model,
+		System:      system,
+		Messages:    turns,
+		MaxTokens:   maxTokens,
+		Temperature: req.Temperature,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/messages", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", c.apiKey)
+	httpReq.Header.Set("anthropic-version", anthropicAPIVersion)
+
+	body, err := doLLMRequest(c.httpClient, httpReq, ProviderAnthropic)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp anthropicResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	var text strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return nil, fmt.Errorf("empty response from API")
+	}
+	return &LLMResponse{Content: text.String()}, nil
+}
@@ -0,0 +1,209 @@
+package interview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LLMClient sends a conversation to a language model and returns its reply.
+// Implementations exist for OpenAI-compatible endpoints, Anthropic's Messages
+// API, Ollama-style local servers and a scripted fake for tests.
+type LLMClient interface {
+	Complete(ctx context.Context, req LLMRequest) (*LLMResponse, error)
+}
+
+// StreamingLLMClient is implemented by clients that can deliver the reply
+// incrementally. onDelta is called with each text fragment as it arrives and
+// the returned response holds the full text.
+type StreamingLLMClient interface {
+	LLMClient
+	Stream(ctx context.Context, req LLMRequest, onDelta func(string)) (*LLMResponse, error)
+}
+
+// streamOrComplete streams through client when it supports streaming and
+// onDelta is set, and falls back to a single Complete call otherwise
+func streamOrComplete(ctx context.Context, client LLMClient, req LLMRequest, onDelta func(string)) (*LLMResponse, error) {
+	if sc, ok := client.(StreamingLLMClient); ok && onDelta != nil {
+		return sc.Stream(ctx, req, onDelta)
+	}
+	return client.Complete(ctx, req)
+}
+
+// LLMRequest is a provider-neutral chat completion request.
+// System messages may appear anywhere in Messages; providers that take the
+// system prompt separately (Anthropic) lift them out.
+type LLMRequest struct {
+	Messages    []GPTMessage
+	MaxTokens   int
+	Temperature float64
+	// ResponseSchema asks for JSON matching the schema. Providers with
+	// structured output support enforce it; the others ignore it.
+	ResponseSchema *ResponseSchema
+}
+
+// ResponseSchema is a named JSON schema for structured output
+type ResponseSchema struct {
+	Name   string
+	Schema map[string]any
+}
+
+// Structured output modes accepted by LLMConfig.ResponseFormat
+const (
+	ResponseFormatJSONSchema = "json_schema" // send the full schema
+	ResponseFormatJSONObject = "json_object" // only ask for a JSON object
+	ResponseFormatNone       = "none"        // rely on the prompt alone
+)
+
+// LLMResponse is the text returned by the model
+type LLMResponse struct {
+	Content string
+}
+
+// LLM provider names accepted by LLMConfig.Provider
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderOllama    = "ollama"
+	ProviderFake      = "fake"
+)
+
+// ErrLLMNotConfigured is returned when the selected provider is missing credentials
+var ErrLLMNotConfigured = errors.New("LLM provider not configured: API key not set")
+
+// LLMAPIError is returned when a provider answers with a non-200 status
+type LLMAPIError struct {
+	Provider   string
+	StatusCode int
+	Body       string
+	// RetryAfter is the delay requested by the provider's Retry-After header, if any
+	RetryAfter time.Duration
+}
+
+func (e *LLMAPIError) Error() string {
+	return fmt.Sprintf("%s API error (status %d): %s", e.Provider, e.StatusCode, e.Body)
+}
+
+// Temporary reports whether the request may succeed if retried (rate limits and server errors)
+func (e *LLMAPIError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// LLMConfig selects and configures an LLM provider
+type LLMConfig struct {
+	Provider string        // openai (default), anthropic, ollama or fake
+	APIKey   string        // not needed for ollama or fake
+	BaseURL  string        // overrides the provider's default endpoint
+	Model    string        // overrides the provider's default model
+	Timeout  time.Duration // HTTP timeout, defaults to 60s
+	// ResponseFormat is json_schema, json_object or none. Defaults to
+	// json_object for openai (older models reject full schemas) and
+	// json_schema for ollama.
+	ResponseFormat string
+}
+
+// LLMConfigFromEnv reads LLM_PROVIDER, LLM_API_KEY, LLM_BASE_URL, LLM_MODEL,
+// LLM_TIMEOUT and LLM_RESPONSE_FORMAT. For backward compatibility the OpenAI key also falls back to
+// OPENAI_API_KEY and GPT_API_KEY, and the Anthropic key to ANTHROPIC_API_KEY.
+func LLMConfigFromEnv() LLMConfig {
+	cfg := LLMConfig{
+		Provider: strings.ToLower(strings.TrimSpace(os.Getenv("LLM_PROVIDER"))),
+		APIKey:   os.Getenv("LLM_API_KEY"),
+		BaseURL:  os.Getenv("LLM_BASE_URL"),
+		Model:    os.Getenv("LLM_MODEL"),
+
+		ResponseFormat: strings.ToLower(strings.TrimSpace(os.Getenv("LLM_RESPONSE_FORMAT"))),
+	}
+	if cfg.Provider == "" {
+		cfg.Provider = ProviderOpenAI
+	}
+	if cfg.APIKey == "" {
+		switch cfg.Provider {
+		case ProviderOpenAI:
+			cfg.APIKey = os.Getenv("OPENAI_API_KEY")
+			if cfg.APIKey == "" {
+				cfg.APIKey = os.Getenv("GPT_API_KEY")
+			}
+		case ProviderAnthropic:
+			cfg.APIKey = os.Getenv("ANTHROPIC_API_KEY")
+		}
+	}
+	if d, err := time.ParseDuration(os.Getenv("LLM_TIMEOUT")); err == nil {
+		cfg.Timeout = d
+	}
+	return cfg
+}
+
+// NewLLMClient builds the client for the configured provider
+func NewLLMClient(cfg LLMConfig) (LLMClient, error) {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	httpClient := &http.Client{Timeout: timeout}
+
+	switch cfg.Provider {
+	case "", ProviderOpenAI:
+		if cfg.APIKey == "" {
+			return nil, ErrLLMNotConfigured
+		}
+		c := NewOpenAIClient(cfg.APIKey, cfg.BaseURL, cfg.Model, httpClient)
+		if cfg.ResponseFormat != "" {
+			c.ResponseFormat = cfg.ResponseFormat
+		}
+		return c, nil
+	case ProviderAnthropic:
+		if cfg.APIKey == "" {
+			return nil, ErrLLMNotConfigured
+		}
+		return NewAnthropicClient(cfg.APIKey, cfg.BaseURL, cfg.Model, httpClient), nil
+	case ProviderOllama:
+		c := NewOllamaClient(cfg.BaseURL, cfg.Model, httpClient)
+		if cfg.ResponseFormat != "" {
+			c.ResponseFormat = cfg.ResponseFormat
+		}
+		return c, nil
+	case ProviderFake:
+		return NewFakeLLMClient(), nil
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q", cfg.Provider)
+	}
+}
+
+// systemAndTurns splits a conversation into the concatenated system prompt and
+// the remaining user/assistant turns
+func systemAndTurns(messages []GPTMessage) (string, []GPTMessage) {
+	var system []string
+	turns := make([]GPTMessage, 0, len(messages))
+	for _, m := range messages {
+		if m.Role == "system" {
+			system = append(system, m.Content)
+			continue
+		}
+		turns = append(turns, m)
+	}
+	return strings.Join(system, "\n\n"), turns
+}
@@ -0,0 +1,116 @@
+package interview
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// fakeDefaultResponse is returned once the script is exhausted. It is a valid
+// "Average" analysis so the whole interview flow works offline.
+const fakeDefaultResponse = `{
+  "scores": {
+    "migration_intent": null,
+    "financial_understanding": null,
+    "academic_credibility": null,
+    "specificity_research": null,
+    "consistency": null,
+    "communication_quality": 3,
+    "red_flags": 3,
+    "total_score": 6
+  },
+  "classification": "Average",
+  "feedback": {
+    "overall": "Offline grading: this answer was scored by the local fake provider.",
+    "by_criterion": {
+      "communication_quality": "Not evaluated by a real model.",
+      "red_flags": "Not evaluated by a real model."
+    },
+    "improvements": ["Configure a real LLM provider to get detailed feedback."]
+  }
+}`
+
+// FakeResponse is one scripted reply. When Err is set it is returned instead of Content.
+type FakeResponse struct {
+	Content string
+	Err     error
+	Delay   time.Duration // held back this long, like a slow model
+}
+
+// FakeLLMClient is a deterministic LLMClient for tests and offline development.
+// It replays scripted responses in order, then falls back to a fixed analysis.
+// Every request is recorded so tests can assert on the prompts sent.
+type FakeLLMClient struct {
+	mu       sync.Mutex
+	script   []FakeResponse
+	requests []LLMRequest
+}
+
+// NewFakeLLMClient returns a fake that replies with the given contents in order
+func NewFakeLLMClient(responses ...string) *FakeLLMClient {
+	f := &FakeLLMClient{}
+	for _, r := range responses {
+		f.script = append(f.script, FakeResponse{Content: r})
+	}
+	return f
+}
+
+// Enqueue appends scripted replies
+func (f *FakeLLMClient) Enqueue(responses ...FakeResponse) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.script = append(f.script, responses...)
+}
+
+// Requests returns a copy of every request received so far
+func (f *FakeLLMClient) Requests() []LLMRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]LLMRequest, len(f.requests))
+	copy(out, f.requests)
+	return out
+}
+
+// Stream replays the next scripted reply in small chunks
+func (f *FakeLLMClient) Stream(ctx context.Context, req LLMRequest, onDelta func(string)) (*LLMResponse, error) {
+	resp, err := f.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	const chunkSize = 16
+	for i := 0; i < len(resp.Content); i += chunkSize {
+		end := min(i+chunkSize, len(resp.Content))
+		if onDelta != nil {
+			onDelta(resp.Content[i:end])
+		}
+	}
+	return resp, nil
+}
+
+func (f *FakeLLMClient) Complete(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	f.requests = append(f.requests, req)
+	if len(f.script) == 0 {
+		f.mu.Unlock()
+		return &LLMResponse{Content: fakeDefaultResponse}, nil
+	}
+	next := f.script[0]
+	f.script = f.script[1:]
+	f.mu.Unlock()
+
+	if next.Delay > 0 {
+		select {
+		case <-time.After(next.Delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if next.Err != nil {
+		return nil, next.Err
+	}
+	return &LLMResponse{Content: next.Content}, nil
+}
@@ -0,0 +1,114 @@
+package interview
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultOllamaBaseURL = "http://localhost:11434"
+	defaultOllamaModel   = "llama3.1"
+)
+
+// OllamaClient talks to a local Ollama-style server through its /api/chat endpoint
+type OllamaClient struct {
+	baseURL    string
+	model      string
+	httpClient *http.Client
+	// ResponseFormat controls how LLMRequest.ResponseSchema is sent:
+	// json_schema (default), json_object or none
+	ResponseFormat string
+}
+
+// NewOllamaClient creates a client for a local model server. Empty baseURL and
+// model fall back to localhost:11434 and llama3.1.
+func NewOllamaClient(baseURL, model string, httpClient *http.Client) *OllamaClient {
+	if baseURL == "" {
+		baseURL = defaultOllamaBaseURL
+	}
+	if model == "" {
+		model = defaultOllamaModel
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &OllamaClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		model:      model,
+		httpClient: httpClient,
+
+		ResponseFormat: ResponseFormatJSONSchema,
+	}
+}
+
+func (c *OllamaClient) Complete(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	type ollamaOptions struct {
+		Temperature float64 `json:"temperature"`
+		NumPredict  int     `json:"num_predict,omitempty"`
+	}
+
+	type ollamaRequest struct {
+		Model    string        `json:"model"`
+		Messages []GPTMessage  `json:"messages"`
+		Stream   bool          `json:"stream"`
+		Options  ollamaOptions `json:"options"`
+		Format   any           `json:"format,omitempty"`
+	}
+
+	type ollamaResponse struct {
+		Message GPTMessage `json:"message"`
+	}
+
+	reqBody, err := json.Marshal(ollamaRequest{
+		Model:    c.model,
+		Messages: req.Messages,
+		Stream:   false,
+		Options: ollamaOptions{
+			Temperature: req.Temperature,
+			NumPredict:  req.MaxTokens,
+		},
+		Format: c.format(req.ResponseSchema),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/chat", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	body, err := doLLMRequest(c.httpClient, httpReq, ProviderOllama)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp ollamaResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if resp.Message.Content == "" {
+		return nil, fmt.Errorf("empty response from API")
+	}
+	return &LLMResponse{Content: resp.Message.Content}, nil
+}
+
+// format builds the format field: the schema itself, or "json" for plain JSON mode
+func (c *OllamaClient) format(schema *ResponseSchema) any {
+	if schema == nil {
+		return nil
+	}
+	switch c.ResponseFormat {
+	case ResponseFormatJSONSchema:
+		return schema.Schema
+	case ResponseFormatJSONObject:
+		return "json"
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,233 @@
+package interview
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultOpenAIBaseURL = "https://api.openai.com/v1"
+	defaultOpenAIModel   = "gpt-3.5-turbo"
+)
+
+// OpenAIClient talks to the OpenAI chat completions API or any server that
+// implements the same protocol (Azure-style proxies, vLLM, LM Studio, ...)
+type OpenAIClient struct {
+	apiKey     string
+	baseURL    string
+	model      string
+	httpClient *http.Client
+	// ResponseFormat controls how LLMRequest.ResponseSchema is sent:
+	// json_object (default), json_schema or none
+	ResponseFormat string
+}
+
+// NewOpenAIClient creates an OpenAI-compatible client. Empty baseURL and model
+// fall back to the public OpenAI endpoint and gpt-3.5-turbo.
+func NewOpenAIClient(apiKey, baseURL, model string, httpClient *http.Client) *OpenAIClient {
+	if baseURL == "" {
+		baseURL = defaultOpenAIBaseURL
+	}
+	if model == "" {
+		model = defaultOpenAIModel
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &OpenAIClient{
+		apiKey:     apiKey,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		model:      model,
+		httpClient: httpClient,
+
+		ResponseFormat: ResponseFormatJSONObject,
+	}
+}
+
+func (c *OpenAIClient) Complete(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	type openAIResponse struct {
+		Choices []struct {
+			Message GPTMessage `json:"message"`
+		} `json:"choices"`
+	}
+
+	httpReq, err := c.newRequest(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := doLLMRequest(c.httpClient, httpReq, ProviderOpenAI)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp openAIResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("empty response from API")
+	}
+	return &LLMResponse{Content: resp.Choices[0].Message.Content}, nil
+}
+
+// Stream sends the request with stream=true and passes each content delta of
+// the server-sent events to onDelta
+func (c *OpenAIClient) Stream(ctx context.Context, req LLMRequest, onDelta func(string)) (*LLMResponse, error) {
+	type openAIChunk struct {
+		Choices []struct {
+			Delta struct {
+				Content string `json:"content"`
+			} `json:"delta"`
+		} `json:"choices"`
+	}
+
+	httpReq, err := c.newRequest(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, newLLMAPIError(resp, ProviderOpenAI)
+	}
+
+	var content strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+		var chunk openAIChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal stream chunk: %w", err)
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+			content.WriteString(choice.Delta.Content)
+			if onDelta != nil {
+				onDelta(choice.Delta.Content)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+	if content.Len() == 0 {
+		return nil, fmt.Errorf("empty response from API")
+	}
+	return &LLMResponse{Content: content.String()}, nil
+}
+
+// newRequest builds the chat completions HTTP request
+func (c *OpenAIClient) newRequest(ctx context.Context, req LLMRequest, stream bool) (*http.Request, error) {
+	type openAIRequest struct {
+		Model       string       `json:"model"`
+		MaxTokens   int          `json:"max_tokens,omitempty"`
+		Messages    []GPTMessage `json:"messages"`
+		Temperature float64      `json:"temperature"`
+		Stream      bool         `json:"stream,omitempty"`
+		// ResponseFormat is the OpenAI response_format object
+		ResponseFormat map[string]any `json:"response_format,omitempty"`
+	}
+
+	reqBody, err := json.Marshal(openAIRequest{
+		Model:       c.model,
+		MaxTokens:   req.MaxTokens,
+		Messages:    req.Messages,
+		Temperature: req.Temperature,
+		Stream:      stream,
+
+		ResponseFormat: c.responseFormat(req.ResponseSchema),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/chat/completions", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	return httpReq, nil
+}
+
+// doLLMRequest sends the request and returns the body of a 200 response, or an
+// *LLMAPIError for any other status
+func doLLMRequest(httpClient *http.Client, req *http.Request, provider string) ([]byte, error) {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &LLMAPIError{
+			Provider:   provider,
+			StatusCode: resp.StatusCode,
+			Body:       string(body),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+	return body, nil
+}
+
+// newLLMAPIError reads an error response into an *LLMAPIError
+func newLLMAPIError(resp *http.Response, provider string) error {
+	body, _ := io.ReadAll(resp.Body)
+	return &LLMAPIError{
+		Provider:   provider,
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// responseFormat builds the response_format field for the configured mode
+func (c *OpenAIClient) responseFormat(schema *ResponseSchema) map[string]any {
+	if schema == nil {
+		return nil
+	}
+	switch c.ResponseFormat {
+	case ResponseFormatJSONSchema:
+		return map[string]any{
+			"type": "json_schema",
+			"json_schema": map[string]any{
+				"name":   schema.Name,
+				"schema": schema.Schema,
+				"strict": false,
+			},
+		}
+	case ResponseFormatJSONObject:
+		return map[string]any{"type": "json_object"}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,233 @@
+package interview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrGradingUnavailable is returned when the LLM cannot be reached right now.
+// Callers should keep the answer and grade it later instead of dropping it.
+var ErrGradingUnavailable = errors.New("grading temporarily unavailable")
+
+// ErrCircuitOpen is returned without calling the provider while the circuit
+// breaker is open. It wraps ErrGradingUnavailable.
+var ErrCircuitOpen = fmt.Errorf("%w: circuit breaker open", ErrGradingUnavailable)
+
+// ResilienceConfig tunes retries and the circuit breaker around an LLMClient
+type ResilienceConfig struct {
+	MaxAttempts      int           // total attempts per call, including the first
+	AttemptTimeout   time.Duration // deadline for each attempt
+	BaseBackoff      time.Duration // delay before the first retry, doubled each time
+	MaxBackoff       time.Duration // cap for backoff and Retry-After delays
+	FailureThreshold int           // consecutive failed calls that open the breaker
+	OpenDuration     time.Duration // how long the breaker stays open before a trial call
+}
+
+// DefaultResilienceConfig returns the settings used when nothing is configured
+func DefaultResilienceConfig() ResilienceConfig {
+	return ResilienceConfig{
+		MaxAttempts:      3,
+		AttemptTimeout:   30 * time.Second,
+		BaseBackoff:      500 * time.Millisecond,
+		MaxBackoff:       10 * time.Second,
+		FailureThreshold: 5,
+		OpenDuration:     30 * time.Second,
+	}
+}
+
+// ResilienceConfigFromEnv reads LLM_MAX_ATTEMPTS, LLM_ATTEMPT_TIMEOUT,
+// LLM_BREAKER_THRESHOLD and LLM_BREAKER_COOLDOWN over the defaults
+func ResilienceConfigFromEnv() ResilienceConfig {
+	cfg := DefaultResilienceConfig()
+	if n, err := strconv.Atoi(os.Getenv("LLM_MAX_ATTEMPTS")); err == nil && n > 0 {
+		cfg.MaxAttempts = n
+	}
+	if d, err := time.ParseDuration(os.Getenv("LLM_ATTEMPT_TIMEOUT")); err == nil && d > 0 {
+		cfg.AttemptTimeout = d
+	}
+	if n, err := strconv.Atoi(os.Getenv("LLM_BREAKER_THRESHOLD")); err == nil && n > 0 {
+		cfg.FailureThreshold = n
+	}
+	if d, err := time.ParseDuration(os.Getenv("LLM_BREAKER_COOLDOWN")); err == nil && d > 0 {
+		cfg.OpenDuration = d
+	}
+	return cfg
+}
+
+// ResilientClient wraps an LLMClient with per-attempt deadlines, exponential
+// backoff that honors Retry-After, and a circuit breaker that fails fast while
+// the provider is down
+type ResilientClient struct {
+	next LLMClient
+	cfg  ResilienceConfig
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	trialBusy bool
+}
+
+// NewResilientClient wraps next. Zero fields in cfg fall back to the defaults.
+func NewResilientClient(next LLMClient, cfg ResilienceConfig) *ResilientClient {
+	def := DefaultResilienceConfig()
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = def.MaxAttempts
+	}
+	if cfg.AttemptTimeout <= 0 {
+		cfg.AttemptTimeout = def.AttemptTimeout
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = def.BaseBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = def.MaxBackoff
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = def.FailureThreshold
+	}
+	if cfg.OpenDuration <= 0 {
+		cfg.OpenDuration = def.OpenDuration
+	}
+	return &ResilientClient{next: next, cfg: cfg}
+}
+
+func (c *ResilientClient) Complete(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	return c.call(ctx, func(ctx context.Context) (*LLMResponse, error) {
+		return c.next.Complete(ctx, req)
+	}, nil)
+}
+
+// Stream streams through the wrapped client when it supports streaming.
+// Failed attempts are only retried while nothing has been streamed yet, so
+// onDelta never sees the same reply twice.
+func (c *ResilientClient) Stream(ctx context.Context, req LLMRequest, onDelta func(string)) (*LLMResponse, error) {
+	streamed := false
+	return c.call(ctx, func(ctx context.Context) (*LLMResponse, error) {
+		return streamOrComplete(ctx, c.next, req, func(delta string) {
+			streamed = true
+			onDelta(delta)
+		})
+	}, func() bool { return !streamed })
+}
+
+// call runs attempt under the breaker and retry policy. canRetry, when set,
+// can veto further attempts.
+func (c *ResilientClient) call(ctx context.Context, attempt func(context.Context) (*LLMResponse, error), canRetry func() bool) (*LLMResponse, error) {
+	trial, err := c.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.completeWithRetry(ctx, attempt, canRetry)
+	c.record(trial, err)
+	return resp, err
+}
+
+func (c *ResilientClient) completeWithRetry(ctx context.Context, call func(context.Context) (*LLMResponse, error), canRetry func() bool) (*LLMResponse, error) {
+	var lastErr error
+	for attempt := 1; attempt <= c.cfg.MaxAttempts; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, c.cfg.AttemptTimeout)
+		resp, err := call(attemptCtx)
+		cancel()
+		if err == nil {
+			return resp, nil
+		}
+
+		// The caller gave up: don't retry and don't blame the provider
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !isTransientLLMError(err) {
+			return nil, err
+		}
+		lastErr = err
+		if attempt == c.cfg.MaxAttempts || (canRetry != nil && !canRetry()) {
+			break
+		}
+
+		delay := c.backoff(attempt, err)
+		log.Printf("LLM call failed (attempt %d/%d), retrying in %s: %v", attempt, c.cfg.MaxAttempts, delay, err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return nil, fmt.Errorf("%w: %w", ErrGradingUnavailable, lastErr)
+}
+
+// backoff returns the delay before the next attempt: the provider's
+// Retry-After when given, otherwise BaseBackoff doubled per attempt
+func (c *ResilientClient) backoff(attempt int, err error) time.Duration {
+	delay := c.cfg.BaseBackoff << (attempt - 1)
+	var apiErr *LLMAPIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		delay = apiErr.RetryAfter
+	}
+	if delay > c.cfg.MaxBackoff || delay <= 0 {
+		delay = c.cfg.MaxBackoff
+	}
+	return delay
+}
+
+// isTransientLLMError reports whether err is worth retrying: rate limits,
+// server errors, timeouts and network failures. Other API errors (bad request,
+// auth) and configuration errors are permanent.
+func isTransientLLMError(err error) bool {
+	var apiErr *LLMAPIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+	if errors.Is(err, ErrLLMNotConfigured) {
+		return false
+	}
+	return true
+}
+
+// allow checks the breaker. While open it fails fast; once the cooldown has
+// passed a single trial call is let through to probe the provider.
+func (c *ResilientClient) allow() (trial bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failures < c.cfg.FailureThreshold {
+		return false, nil
+	}
+	if time.Now().Before(c.openUntil) || c.trialBusy {
+		return false, ErrCircuitOpen
+	}
+	c.trialBusy = true
+	return true, nil
+}
+
+// record updates the breaker with the outcome of a call. Only exhausted
+// transient failures count; a permanent error means the provider answered, and
+// a call cancelled by the caller says nothing about the provider.
+func (c *ResilientClient) record(trial bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if trial {
+		c.trialBusy = false
+	}
+	switch {
+	case errors.Is(err, ErrGradingUnavailable):
+		c.failures++
+		if c.failures >= c.cfg.FailureThreshold {
+			if c.failures == c.cfg.FailureThreshold || trial {
+				log.Printf("LLM circuit breaker open for %s after %d failed calls", c.cfg.OpenDuration, c.failures)
+			}
+			c.openUntil = time.Now().Add(c.cfg.OpenDuration)
+		}
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		// cancelled by the caller
+	default:
+		c.failures = 0
+	}
+}
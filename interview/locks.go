@@ -0,0 +1,43 @@
+package interview
+
+import "sync"
+
+// keyedLock is the lock of one key and the number of callers holding or
+// waiting for it
+type keyedLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// keyedLocks is a set of mutexes by key, like one per session. A key's lock
+// is dropped when nobody holds or waits for it. The zero value is ready to use.
+type keyedLocks struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+// lock takes the lock of key and returns the function releasing it, which
+// must be called once
+func (k *keyedLocks) lock(key string) func() {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*keyedLock)
+	}
+	l := k.locks[key]
+	if l == nil {
+		l = &keyedLock{}
+		k.locks[key] = l
+	}
+	l.refs++
+	k.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		k.mu.Lock()
+		defer k.mu.Unlock()
+		if l.refs--; l.refs == 0 {
+			delete(k.locks, key)
+		}
+	}
+}
@@ -10,6 +10,13 @@ type Question struct {
 	NextID             string   `json:"next_id"`             // linear next question in normal flow
 	FollowupCandidates []string `json:"followup_candidates"` // allowed followups from this node
 	Tags               []string `json:"tags"`                // semantic tags: ["purpose", "intent", "risk"]
+	Difficulty         string   `json:"difficulty,omitempty"`    // easy, medium or hard
+	VisaTypes          []string `json:"visa_types,omitempty"`    // visas the question applies to, e.g. ["F-1"]
+	OfficerNotes       string   `json:"officer_notes,omitempty"` // what a consular officer listens for
+	RubricHints        []string `json:"rubric_hints,omitempty"`  // what a strong answer contains
+	IsFollowup         bool     `json:"is_followup,omitempty"` // inserted because the previous answer was weak
+	ParentID           string   `json:"parent_id,omitempty"`   // question this follow-up digs into
+	Generated          bool     `json:"generated,omitempty"`   // written by the model from the student's answer
 }
 
 // Answer is one student response.
@@ -22,8 +29,20 @@ type Answer struct {
 	Eval *EvalResult `json:"eval,omitempty"`
 	// New grading system analysis
 	Analysis *AnalysisResponse `json:"analysis,omitempty"`
+	// GradingStatus is graded, pending (LLM unavailable, grade later) or failed
+	GradingStatus string `json:"grading_status,omitempty"`
+	// Attempts are the earlier attempts at a retried question, oldest first;
+	// the fields above hold the latest
+	Attempts []AnswerAttempt `json:"attempts,omitempty"`
 }
 
+// Answer grading states
+const (
+	GradingStatusGraded  = "graded"
+	GradingStatusPending = "pending"
+	GradingStatusFailed  = "failed"
+)
+
 // Scores are cumulative across the entire session.
 type Scores struct {
 	Academic       int `json:"academic"`
@@ -44,7 +63,11 @@ const (
 // Session holds the state of one full interview attempt.
 type Session struct {
 	ID                string        `json:"id"`
-	UserID            string        `json:"user_id,omitempty"`  // if you later have accounts
+	UserID            string        `json:"user_id,omitempty"`  // owner of the session
+	Level             string        `json:"level,omitempty"`    // difficulty level the questions were selected for
+	VisaType          string        `json:"visa_type,omitempty"` // visa being practiced; empty means DefaultVisaType
+	Seed              int64         `json:"seed"`               // seeds question selection; same seed, level and bank select the same questions
+	SummaryAttempt    string        `json:"summary_attempt,omitempty"` // attempt at a retried question the summary counts: best or latest (empty)
 	CurrentQuestion   string        `json:"current_question"`   // question ID
 	SelectedQuestions []Question    `json:"selected_questions"` // questions selected for this session
 	QuestionIndex     int           `json:"question_index"`     // current question index in SelectedQuestions
@@ -57,29 +80,16 @@ type Session struct {
 	Summary *SessionSummary `json:"summary,omitempty"`
 }
 
-// AnalysisScores represents the dynamic grading system for a single answer
-// Criteria are nullable (*int) to support N/A when not relevant to the question
+// AnalysisScores represents the dynamic grading system for a single answer.
+// Criteria are keyed by rubric criterion and nullable to support N/A when not
+// relevant to the question. In JSON they sit next to total_score.
 type AnalysisScores struct {
-	MigrationIntent       *int `json:"migration_intent"`        // 1–5 or null
-	FinancialUnderstanding *int `json:"financial_understanding"` // 1–5 or null
-	AcademicCredibility   *int `json:"academic_credibility"`    // 1–5 or null
-	SpecificityResearch   *int `json:"specificity_research"`    // 1–5 or null
-	Consistency           *int `json:"consistency"`             // 1–5 or null
-	CommunicationQuality  *int `json:"communication_quality"`   // 1–5 or null
-	RedFlags              *int `json:"red_flags"`               // 1–5 or null (inverted: 5 = no flags, 1 = major flags)
-	TotalScore            int  `json:"total_score"`              // Sum of non-null criteria
+	Criteria   map[string]*int // score on the criterion's scale, or nil
+	TotalScore int             // Sum of non-null criteria
 }
 
-// FeedbackByCriterion contains feedback for each scoring criterion
-type FeedbackByCriterion struct {
-	MigrationIntent       string `json:"migration_intent"`
-	FinancialUnderstanding string `json:"financial_understanding"`
-	AcademicCredibility   string `json:"academic_credibility"`
-	SpecificityResearch   string `json:"specificity_research"`
-	Consistency           string `json:"consistency"`
-	CommunicationQuality  string `json:"communication_quality"`
-	RedFlags              string `json:"red_flags"`
-}
+// FeedbackByCriterion contains feedback for each scoring criterion, keyed by rubric criterion
+type FeedbackByCriterion map[string]string
 
 // StructuredFeedback contains detailed feedback in the new format
 type StructuredFeedback struct {
@@ -93,12 +103,16 @@ type AnalysisResponse struct {
 	Scores         AnalysisScores     `json:"scores"`
 	Classification string             `json:"classification"` // Excellent, Good, Average, Weak
 	Feedback       StructuredFeedback `json:"feedback"`       // Structured feedback with overall, by_criterion, and improvements
+	// ImprovedVersion rewrites the answer as a strong one using only facts the
+	// applicant stated or declared in their profile
+	ImprovedVersion string `json:"improved_version,omitempty"`
 }
 
 // AnalysisRecord stores a complete analysis record
 type AnalysisRecord struct {
 	ID        string           `json:"id"`
 	SessionID string           `json:"sessionId,omitempty"`
+	Category  string           `json:"category,omitempty"` // weighs the answer in the session score
 	Question  string           `json:"question"`
 	Answer    string           `json:"answer"`
 	Analysis  AnalysisResponse `json:"analysis"`
@@ -110,10 +124,16 @@ type SessionSummary struct {
 	SessionID      string    `json:"sessionId"`
 	TotalQuestions int       `json:"totalQuestions"`
 	AverageScore   float64   `json:"averageScore"`
+	ScorePercent   float64   `json:"scorePercent"` // weighted score, 0–100, that OverallGrade is read from
 	OverallGrade   string    `json:"overallGrade"`
 	StrongAreas    []string  `json:"strongAreas"`
 	WeakAreas      []string  `json:"weakAreas"`
 	CommonRedFlags []string  `json:"commonRedFlags"`
 	Recommendation string    `json:"recommendation"`
 	CompletedAt    time.Time `json:"completedAt"`
+	// FactSheet lists the facts stated across answers and Contradictions the
+	// ones that changed between answers; both are empty when the check did not run
+	FactSheet      []FactClaim     `json:"factSheet,omitempty"`
+	Contradictions []Contradiction `json:"contradictions,omitempty"`
+	ConsistencyKey string          `json:"consistencyKey,omitempty"` // fingerprint of the answers the report was made from
 }
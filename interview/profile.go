@@ -0,0 +1,286 @@
+package interview
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrProfileNotFound is returned when a user has not filled in an applicant profile
+var ErrProfileNotFound = errors.New("applicant profile not found")
+
+// ErrInvalidProfile is returned when an applicant profile fails validation
+var ErrInvalidProfile = errors.New("invalid applicant profile")
+
+// ApplicantProfile is what the applicant declared on the DS-160 and in their
+// documents. The analyzer treats it as ground truth: answers that contradict
+// it are graded down on consistency and red flags.
+type ApplicantProfile struct {
+	UserID       string `json:"user_id"`
+	University   string `json:"university,omitempty"`    // school that admitted the applicant
+	Program      string `json:"program,omitempty"`       // degree and major, e.g. "MS Computer Science"
+	ProgramStart string `json:"program_start,omitempty"` // YYYY-MM-DD, as on the I-20
+	ProgramEnd   string `json:"program_end,omitempty"`
+	// EstimatedCost is the yearly cost of attendance on the I-20, in U.S. dollars
+	EstimatedCost int             `json:"estimated_cost,omitempty"`
+	Funding       []FundingSource `json:"funding,omitempty"`
+	// AvailableFunds is the balance shown on bank statements, in U.S. dollars
+	AvailableFunds int `json:"available_funds,omitempty"`
+	// Sponsor is the person or organization paying, SponsorRelationship how
+	// they are related to the applicant ("father", "employer")
+	Sponsor             string            `json:"sponsor,omitempty"`
+	SponsorRelationship string            `json:"sponsor_relationship,omitempty"`
+	HomeCountry         string            `json:"home_country,omitempty"`
+	HomeTies            []string          `json:"home_ties,omitempty"`       // family, property, job offers
+	PriorUSTravel       []string          `json:"prior_us_travel,omitempty"` // one entry per trip
+	TestScores          map[string]string `json:"test_scores,omitempty"`     // e.g. {"TOEFL": "104", "GRE": "320"}
+	UpdatedAt           time.Time         `json:"updated_at"`
+}
+
+// FundingSource is one source of money for the program, yearly in U.S. dollars
+type FundingSource struct {
+	Source string `json:"source"` // e.g. "family savings", "university scholarship"
+	Amount int    `json:"amount"`
+}
+
+// Validate checks that amounts are not negative, that funding sources are
+// named and that program dates are YYYY-MM-DD with the end after the start
+func (p *ApplicantProfile) Validate() error {
+	if p.EstimatedCost < 0 {
+		return fmt.Errorf("%w: estimated_cost must not be negative", ErrInvalidProfile)
+	}
+	if p.AvailableFunds < 0 {
+		return fmt.Errorf("%w: available_funds must not be negative", ErrInvalidProfile)
+	}
+	for i, f := range p.Funding {
+		if strings.TrimSpace(f.Source) == "" {
+			return fmt.Errorf("%w: funding source %d needs a name", ErrInvalidProfile, i+1)
+		}
+		if f.Amount < 0 {
+			return fmt.Errorf("%w: funding '%s' must not be negative", ErrInvalidProfile, f.Source)
+		}
+	}
+	var start, end time.Time
+	for _, d := range []struct {
+		name  string
+		value string
+		into  *time.Time
+	}{{"program_start", p.ProgramStart, &start}, {"program_end", p.ProgramEnd, &end}} {
+		if d.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.DateOnly, d.value)
+		if err != nil {
+			return fmt.Errorf("%w: %s must be YYYY-MM-DD", ErrInvalidProfile, d.name)
+		}
+		*d.into = t
+	}
+	if !start.IsZero() && !end.IsZero() && !end.After(start) {
+		return fmt.Errorf("%w: program_end must be after program_start", ErrInvalidProfile)
+	}
+	return nil
+}
+
+// TotalFunding is the sum of the funding sources
+func (p *ApplicantProfile) TotalFunding() int {
+	total := 0
+	for _, f := range p.Funding {
+		total += f.Amount
+	}
+	return total
+}
+
+// promptText lists the filled-in fields of the profile for the analyzer, or
+// returns "" when there is nothing to compare answers against
+func (p *ApplicantProfile) promptText() string {
+	var lines []string
+	add := func(label, value string) {
+		if strings.TrimSpace(value) != "" {
+			lines = append(lines, fmt.Sprintf("- %s: %s", label, value))
+		}
+	}
+	add("Admitted university", p.University)
+	add("Program", p.Program)
+	switch {
+	case p.ProgramStart != "" && p.ProgramEnd != "":
+		add("Program dates", p.ProgramStart+" to "+p.ProgramEnd)
+	case p.ProgramStart != "":
+		add("Program dates", "from "+p.ProgramStart)
+	case p.ProgramEnd != "":
+		add("Program dates", "until "+p.ProgramEnd)
+	}
+	if p.EstimatedCost > 0 {
+		add("Estimated cost per year", formatDollars(p.EstimatedCost))
+	}
+	if len(p.Funding) > 0 {
+		sources := make([]string, len(p.Funding))
+		for i, f := range p.Funding {
+			sources[i] = fmt.Sprintf("%s %s", f.Source, formatDollars(f.Amount))
+		}
+		add("Funding per year", fmt.Sprintf("%s (total %s)", strings.Join(sources, ", "), formatDollars(p.TotalFunding())))
+	}
+	if p.AvailableFunds > 0 {
+		add("Funds on bank statements", formatDollars(p.AvailableFunds))
+	}
+	sponsor := p.Sponsor
+	if p.SponsorRelationship != "" {
+		sponsor = strings.TrimSpace(sponsor + " (" + p.SponsorRelationship + ")")
+	}
+	add("Sponsor", sponsor)
+	add("Home country", p.HomeCountry)
+	add("Home-country ties", strings.Join(p.HomeTies, "; "))
+	add("Prior U.S. travel", strings.Join(p.PriorUSTravel, "; "))
+	if len(p.TestScores) > 0 {
+		tests := make([]string, 0, len(p.TestScores))
+		for test, score := range p.TestScores {
+			tests = append(tests, test+" "+score)
+		}
+		sort.Strings(tests)
+		add("Test scores", strings.Join(tests, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatDollars writes whole dollars with thousands separators, e.g. $45,000
+func formatDollars(amount int) string {
+	digits := fmt.Sprint(amount)
+	var b strings.Builder
+	for i, r := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	return "$" + b.String()
+}
+
+// ProfileStore persists applicant profiles
+type ProfileStore interface {
+	// GetProfile returns the profile of a user or ErrProfileNotFound
+	GetProfile(userID string) (*ApplicantProfile, error)
+	// SaveProfile inserts or replaces the profile of p.UserID
+	SaveProfile(p *ApplicantProfile) error
+}
+
+var (
+	profileStore   ProfileStore = NewMemoryProfileStore()
+	profileStoreMu sync.RWMutex
+)
+
+// SetProfileStore replaces the store used for applicant profiles
+func SetProfileStore(store ProfileStore) {
+	profileStoreMu.Lock()
+	defer profileStoreMu.Unlock()
+	profileStore = store
+}
+
+// GetProfileStore returns the store used for applicant profiles
+func GetProfileStore() ProfileStore {
+	profileStoreMu.RLock()
+	defer profileStoreMu.RUnlock()
+	return profileStore
+}
+
+// memoryProfileStore keeps applicant profiles in process memory
+type memoryProfileStore struct {
+	mu       sync.RWMutex
+	profiles map[string]ApplicantProfile
+}
+
+// NewMemoryProfileStore returns a ProfileStore backed by an in-process map
+func NewMemoryProfileStore() ProfileStore {
+	return &memoryProfileStore{profiles: make(map[string]ApplicantProfile)}
+}
+
+func (m *memoryProfileStore) GetProfile(userID string) (*ApplicantProfile, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	p, ok := m.profiles[userID]
+	if !ok {
+		return nil, ErrProfileNotFound
+	}
+	return &p, nil
+}
+
+func (m *memoryProfileStore) SaveProfile(p *ApplicantProfile) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.profiles[p.UserID] = *p
+	return nil
+}
+
+// GetProfile returns the applicant profile of a user or ErrProfileNotFound
+func GetProfile(userID string) (*ApplicantProfile, error) {
+	return GetProfileStore().GetProfile(userID)
+}
+
+// profileLocks holds the lock of each profile being changed
+var profileLocks keyedLocks
+
+// LockProfile serializes changes to one user's applicant profile, so a
+// document import and a profile update do not overwrite each other. Call the
+// returned function once to release the lock.
+func LockProfile(userID string) func() {
+	return profileLocks.lock(userID)
+}
+
+// SaveProfile validates p and stores it as the profile of p.UserID
+func SaveProfile(p *ApplicantProfile) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	p.UpdatedAt = time.Now()
+	return GetProfileStore().SaveProfile(p)
+}
+
+// profileFor returns the applicant profile of the session's owner, or nil
+// when the session is anonymous or the owner has none
+func profileFor(s *Session) *ApplicantProfile {
+	if s == nil || s.UserID == "" {
+		return nil
+	}
+	p, err := GetProfileStore().GetProfile(s.UserID)
+	if err != nil {
+		if !errors.Is(err, ErrProfileNotFound) {
+			log.Printf("Failed to load the applicant profile of %s: %v", s.UserID, err)
+		}
+		return nil
+	}
+	return p
+}
+
+// profilePrompt tells the analyzer to grade answers against the profile of
+// the session's owner. It returns "" when there is no profile to compare with.
+func profilePrompt(s *Session) string {
+	p := profileFor(s)
+	if p == nil {
+		return ""
+	}
+	facts := p.promptText()
+	if facts == "" {
+		return ""
+	}
+	return "Applicant profile, as declared on the DS-160 and in the applicant's documents. Treat it as ground truth:\n" +
+		facts +
+		"\n\nWhen an answer contradicts the profile (a different university, program, cost, amount, sponsor or travel history), " +
+		"score consistency and red_flags down even in categories where consistency is otherwise not evaluated, " +
+		"and name the fact that differs in the feedback for those criteria."
+}
+
+// statedFacts is everything the applicant has told the analyzer before the
+// answer being graded: earlier answers of the session and their profile
+func statedFacts(s *Session) string {
+	var b strings.Builder
+	for _, a := range s.Answers {
+		b.WriteString(a.Text)
+		b.WriteByte('\n')
+	}
+	if p := profileFor(s); p != nil {
+		b.WriteString(p.promptText())
+	}
+	return b.String()
+}
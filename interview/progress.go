@@ -0,0 +1,136 @@
+package interview
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultProgressWindow is the number of sessions averaged by the moving average
+const DefaultProgressWindow = 3
+
+// ProgressPoint is one session's average score for a criterion
+type ProgressPoint struct {
+	SessionID     string    `json:"session_id"`
+	Date          time.Time `json:"date"`
+	Average       float64   `json:"average"`        // mean 1–5 score across the session's answers
+	MovingAverage float64   `json:"moving_average"` // mean of the last Window session averages
+	Samples       int       `json:"samples"`        // number of answers that scored this criterion
+}
+
+// CriterionProgress is the time series for one criterion across sessions
+type CriterionProgress struct {
+	Criterion string          `json:"criterion"`
+	Label     string          `json:"label"`
+	Points    []ProgressPoint `json:"points"`
+	Latest    float64         `json:"latest"` // latest moving average
+	Change    float64         `json:"change"` // latest minus first moving average
+}
+
+// ProgressReport aggregates a user's finished sessions per criterion
+type ProgressReport struct {
+	SessionCount int                 `json:"session_count"`
+	Window       int                 `json:"window"`
+	Criteria     []CriterionProgress `json:"criteria"`
+	MostImproved []string            `json:"most_improved"`
+	StillWeak    []string            `json:"still_weak"`
+	GeneratedAt  time.Time           `json:"generated_at"`
+}
+
+// BuildProgressReport turns a user's sessions into per-criterion time series.
+// Only finished sessions count, each retried question with the attempt its
+// session's summary counts; window <= 0 uses DefaultProgressWindow.
+func BuildProgressReport(sessions []*Session, window int) *ProgressReport {
+	if window <= 0 {
+		window = DefaultProgressWindow
+	}
+
+	finished := make([]*Session, 0, len(sessions))
+	for _, s := range sessions {
+		if s.Status == SessionStatusFinished {
+			finished = append(finished, s)
+		}
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].CreatedAt.Before(finished[j].CreatedAt)
+	})
+
+	report := &ProgressReport{
+		SessionCount: len(finished),
+		Window:       window,
+		Criteria:     []CriterionProgress{},
+		MostImproved: []string{},
+		StillWeak:    []string{},
+		GeneratedAt:  time.Now(),
+	}
+
+	weak := make([]CriterionProgress, 0)
+	for _, criterion := range RubricCriteria() {
+		progress := CriterionProgress{
+			Criterion: criterion.Key,
+			Label:     criterion.Label,
+			Points:    []ProgressPoint{},
+		}
+		for _, s := range finished {
+			sum, samples := 0, 0
+			for _, answer := range s.CountedAnswers() {
+				if answer.Analysis == nil {
+					continue
+				}
+				if score := answer.Analysis.Scores.Score(criterion.Key); score != nil {
+					sum += *score
+					samples++
+				}
+			}
+			if samples == 0 {
+				continue
+			}
+			progress.Points = append(progress.Points, ProgressPoint{
+				SessionID: s.ID,
+				Date:      s.CreatedAt,
+				Average:   float64(sum) / float64(samples),
+				Samples:   samples,
+			})
+		}
+		if len(progress.Points) == 0 {
+			continue
+		}
+
+		for i := range progress.Points {
+			start := i - window + 1
+			if start < 0 {
+				start = 0
+			}
+			total := 0.0
+			for _, p := range progress.Points[start : i+1] {
+				total += p.Average
+			}
+			progress.Points[i].MovingAverage = total / float64(i+1-start)
+		}
+		first := progress.Points[0].MovingAverage
+		progress.Latest = progress.Points[len(progress.Points)-1].MovingAverage
+		progress.Change = progress.Latest - first
+
+		report.Criteria = append(report.Criteria, progress)
+		// Weak as in extractCommonWeaknesses, e.g. 3 or less on a 1–5 scale
+		if criterion.weakAverage(progress.Latest) {
+			weak = append(weak, progress)
+		}
+	}
+
+	improved := make([]CriterionProgress, 0)
+	for _, p := range report.Criteria {
+		if len(p.Points) >= 2 && p.Change > 0 {
+			improved = append(improved, p)
+		}
+	}
+	sort.SliceStable(improved, func(i, j int) bool { return improved[i].Change > improved[j].Change })
+	sort.SliceStable(weak, func(i, j int) bool { return weak[i].Latest < weak[j].Latest })
+	for _, p := range improved {
+		report.MostImproved = append(report.MostImproved, p.Criterion)
+	}
+	for _, p := range weak {
+		report.StillWeak = append(report.StillWeak, p.Criterion)
+	}
+
+	return report
+}
@@ -0,0 +1,406 @@
+package interview
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrQuestionNotFound is returned when no bank question has the given ID
+var ErrQuestionNotFound = errors.New("question not found")
+
+// ErrQuestionExists is returned when creating a question with an ID already in use
+var ErrQuestionExists = errors.New("question id already exists")
+
+// BankQuestion is a question bank entry as managed by admins. Position
+// orders the category, in the admin listing and in the bank installed for new
+// sessions, which draw from a category at random. Retired
+// questions are kept so sessions and history can still refer to their IDs,
+// but they are no longer selected for new sessions.
+type BankQuestion struct {
+	Question
+	Position  int       `json:"position"` // order within the category
+	Retired   bool      `json:"retired"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// QuestionStore persists the editable question bank
+type QuestionStore interface {
+	// ListQuestions returns every question, retired ones included
+	ListQuestions() ([]BankQuestion, error)
+	// SaveQuestions inserts or updates the questions in a single transaction
+	SaveQuestions(questions []BankQuestion) error
+}
+
+var (
+	questionStore   QuestionStore = NewMemoryQuestionStore()
+	questionStoreMu sync.RWMutex
+
+	// questionAdminMu serializes bank edits so each one validates against the latest bank
+	questionAdminMu sync.Mutex
+)
+
+// SetQuestionStore replaces the store used for admin edits of the question bank
+func SetQuestionStore(store QuestionStore) {
+	questionStoreMu.Lock()
+	defer questionStoreMu.Unlock()
+	questionStore = store
+}
+
+// GetQuestionStore returns the store used for admin edits of the question bank
+func GetQuestionStore() QuestionStore {
+	questionStoreMu.RLock()
+	defer questionStoreMu.RUnlock()
+	return questionStore
+}
+
+// ReloadQuestionBank installs the questions of the configured store. Questions
+// of the bank loaded from questions.json that the store does not have yet, such
+// as those of a newly supported visa, are first added to it.
+//
+// The bank is swapped in per process: an admin edit applies right away on the
+// replica that made it, while other replicas see it after their next reload,
+// which happens on restart.
+func ReloadQuestionBank() error {
+	questionAdminMu.Lock()
+	defer questionAdminMu.Unlock()
+
+	questions, err := loadBankQuestions(true)
+	if err != nil {
+		return err
+	}
+	return installQuestionBank(activeQuestionBank(questions))
+}
+
+// ListBankQuestions returns the bank ordered by category and position. Bank
+// questions the store does not have yet are listed but not saved.
+func ListBankQuestions() ([]BankQuestion, error) {
+	questionAdminMu.Lock()
+	defer questionAdminMu.Unlock()
+	return loadBankQuestions(false)
+}
+
+// CreateBankQuestion adds a question at the end of its category. An empty ID
+// is derived from the category like the IDs of the legacy format.
+func CreateBankQuestion(q Question) (*BankQuestion, error) {
+	questionAdminMu.Lock()
+	defer questionAdminMu.Unlock()
+
+	questions, err := loadBankQuestions(true)
+	if err != nil {
+		return nil, err
+	}
+	if q.ID == "" {
+		q.ID = nextQuestionID(questions, q.Category)
+	}
+	if err := normalizeVisaTypes(&q); err != nil {
+		return nil, err
+	}
+	if findBankQuestion(questions, q.ID) >= 0 {
+		return nil, ErrQuestionExists
+	}
+
+	created := BankQuestion{Question: q, Position: nextPosition(questions, q.Category), UpdatedAt: time.Now()}
+	questions = append(questions, created)
+	if err := commitBankQuestions(questions, created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateBankQuestion replaces the content of a question, keeping its ID. A
+// question moved to another category goes to the end of it.
+func UpdateBankQuestion(id string, q Question) (*BankQuestion, error) {
+	questionAdminMu.Lock()
+	defer questionAdminMu.Unlock()
+
+	questions, err := loadBankQuestions(true)
+	if err != nil {
+		return nil, err
+	}
+	i := findBankQuestion(questions, id)
+	if i < 0 {
+		return nil, ErrQuestionNotFound
+	}
+
+	updated := questions[i]
+	q.ID = id
+	if err := normalizeVisaTypes(&q); err != nil {
+		return nil, err
+	}
+	if q.Category != updated.Category {
+		updated.Position = nextPosition(questions, q.Category)
+	}
+	updated.Question = q
+	updated.UpdatedAt = time.Now()
+	questions[i] = updated
+	if err := commitBankQuestions(questions, updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// RetireBankQuestion stops a question from being selected for new sessions
+func RetireBankQuestion(id string) (*BankQuestion, error) {
+	questionAdminMu.Lock()
+	defer questionAdminMu.Unlock()
+
+	questions, err := loadBankQuestions(true)
+	if err != nil {
+		return nil, err
+	}
+	i := findBankQuestion(questions, id)
+	if i < 0 {
+		return nil, ErrQuestionNotFound
+	}
+	if questions[i].Retired {
+		retired := questions[i]
+		return &retired, nil
+	}
+
+	retired := questions[i]
+	retired.Retired = true
+	retired.UpdatedAt = time.Now()
+	questions[i] = retired
+	if err := commitBankQuestions(questions, retired); err != nil {
+		return nil, err
+	}
+	return &retired, nil
+}
+
+// ReorderBankQuestions sets the order of a category. ids must list every
+// active question of the category exactly once; retired ones are moved last.
+func ReorderBankQuestions(category string, ids []string) ([]BankQuestion, error) {
+	questionAdminMu.Lock()
+	defer questionAdminMu.Unlock()
+
+	questions, err := loadBankQuestions(true)
+	if err != nil {
+		return nil, err
+	}
+	order := make(map[string]int, len(ids))
+	for i, id := range ids {
+		if _, dup := order[id]; dup {
+			return nil, fmt.Errorf("%w: question '%s' listed twice", ErrInvalidQuestion, id)
+		}
+		order[id] = i
+	}
+
+	var changed []BankQuestion
+	retired := len(ids)
+	for i := range questions {
+		q := &questions[i]
+		if q.Category != category {
+			continue
+		}
+		position, listed := order[q.ID]
+		switch {
+		case q.Retired:
+			position = retired
+			retired++
+		case !listed:
+			return nil, fmt.Errorf("%w: active question '%s' missing from the order", ErrInvalidQuestion, q.ID)
+		}
+		delete(order, q.ID)
+		q.Position = position
+		q.UpdatedAt = time.Now()
+		changed = append(changed, *q)
+	}
+	if len(order) > 0 {
+		unknown := make([]string, 0, len(order))
+		for id := range order {
+			unknown = append(unknown, id)
+		}
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("%w: not active questions of '%s': %s", ErrInvalidQuestion, category, strings.Join(unknown, ", "))
+	}
+
+	if err := commitBankQuestions(questions, changed...); err != nil {
+		return nil, err
+	}
+	sortBankQuestions(changed)
+	return changed, nil
+}
+
+// loadBankQuestions lists the store together with the questions of the
+// installed bank it does not have: all of them for an empty store, and new
+// bank questions at the end of their category for one seeded by an older
+// questions.json. With seed set those are saved to the store first; reads
+// leave the store untouched. Questions edited or retired in the store are left
+// as they are. The caller holds questionAdminMu.
+func loadBankQuestions(seed bool) ([]BankQuestion, error) {
+	store := GetQuestionStore()
+	questions, err := store.ListQuestions()
+	if err != nil {
+		return nil, err
+	}
+
+	var added []BankQuestion
+	now := time.Now()
+	byCategory := questionsSnapshot()
+	for _, category := range sortedCategories(byCategory) {
+		for _, q := range byCategory[category] {
+			if findBankQuestion(questions, q.ID) >= 0 {
+				continue
+			}
+			bq := BankQuestion{Question: q, Position: nextPosition(questions, q.Category), UpdatedAt: now}
+			questions = append(questions, bq)
+			added = append(added, bq)
+		}
+	}
+	if seed && len(added) > 0 {
+		if err := store.SaveQuestions(added); err != nil {
+			return nil, fmt.Errorf("seed question store: %w", err)
+		}
+		log.Printf("Seeded question store with %d questions", len(added))
+	}
+	sortBankQuestions(questions)
+	return questions, nil
+}
+
+// commitBankQuestions validates the bank that results from an edit, saves the
+// changed questions and swaps the bank in for new sessions
+func commitBankQuestions(questions []BankQuestion, changed ...BankQuestion) error {
+	sortBankQuestions(questions)
+	bank := activeQuestionBank(questions)
+	if err := bank.Validate(); err != nil {
+		return err
+	}
+	byCategory, byID, err := indexQuestionBank(bank, levelCategories())
+	if err != nil {
+		return err
+	}
+
+	if err := GetQuestionStore().SaveQuestions(changed); err != nil {
+		return err
+	}
+	swapQuestionBank(byCategory, byID)
+	return nil
+}
+
+// activeQuestionBank is the bank new sessions select from
+func activeQuestionBank(questions []BankQuestion) *QuestionBank {
+	bank := &QuestionBank{Version: QuestionBankVersion}
+	for _, q := range questions {
+		if !q.Retired {
+			bank.Questions = append(bank.Questions, q.Question)
+		}
+	}
+	return bank
+}
+
+// normalizeVisaTypes writes the visa types of q as the supported visas spell them
+func normalizeVisaTypes(q *Question) error {
+	for i, visaType := range q.VisaTypes {
+		v, err := LookupVisaType(visaType)
+		if err != nil || visaType == "" {
+			return fmt.Errorf("%w: question '%s' has unknown visa type '%s'", ErrInvalidQuestion, q.ID, visaType)
+		}
+		q.VisaTypes[i] = v.Type
+	}
+	return nil
+}
+
+func findBankQuestion(questions []BankQuestion, id string) int {
+	for i, q := range questions {
+		if q.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func nextPosition(questions []BankQuestion, category string) int {
+	next := 0
+	for _, q := range questions {
+		if q.Category == category && q.Position >= next {
+			next = q.Position + 1
+		}
+	}
+	return next
+}
+
+// nextQuestionID returns the first free "<category>_<n>" ID
+func nextQuestionID(questions []BankQuestion, category string) string {
+	for i := 0; ; i++ {
+		id := legacyQuestionID(category, i)
+		if findBankQuestion(questions, id) < 0 {
+			return id
+		}
+	}
+}
+
+// categoryRank orders categories as in CategoryOrder, others last
+func categoryRank(category string) int {
+	for i, c := range CategoryOrder {
+		if c == category {
+			return i
+		}
+	}
+	return len(CategoryOrder)
+}
+
+// sortBankQuestions orders by category (CategoryOrder first, then by name) and position
+func sortBankQuestions(questions []BankQuestion) {
+	sort.SliceStable(questions, func(i, j int) bool {
+		a, b := questions[i], questions[j]
+		if a.Category != b.Category {
+			ra, rb := categoryRank(a.Category), categoryRank(b.Category)
+			if ra != rb {
+				return ra < rb
+			}
+			return a.Category < b.Category
+		}
+		return a.Position < b.Position
+	})
+}
+
+// sortedCategories lists the categories in CategoryOrder, then any others by name
+func sortedCategories(byCategory map[string][]Question) []string {
+	categories := make([]string, 0, len(byCategory))
+	for category := range byCategory {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		ri, rj := categoryRank(categories[i]), categoryRank(categories[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return categories[i] < categories[j]
+	})
+	return categories
+}
+
+// memoryQuestionStore keeps the bank in process memory; edits are lost on restart
+type memoryQuestionStore struct {
+	mu        sync.RWMutex
+	questions map[string]BankQuestion
+}
+
+// NewMemoryQuestionStore returns an empty QuestionStore backed by an in-process map
+func NewMemoryQuestionStore() QuestionStore {
+	return &memoryQuestionStore{questions: make(map[string]BankQuestion)}
+}
+
+func (m *memoryQuestionStore) ListQuestions() ([]BankQuestion, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	questions := make([]BankQuestion, 0, len(m.questions))
+	for _, q := range m.questions {
+		questions = append(questions, q)
+	}
+	return questions, nil
+}
+
+func (m *memoryQuestionStore) SaveQuestions(questions []BankQuestion) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, q := range questions {
+		m.questions[q.ID] = q
+	}
+	return nil
+}
@@ -2,15 +2,41 @@ package interview
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
 	"path/filepath"
-	"time"
+	"sort"
+	"strings"
+	"sync"
 )
 
-// QuestionsByCategory stores questions organized by category
-var QuestionsByCategory map[string][]string
+// QuestionBankVersion is the questions.json schema version written by this code.
+// Version 1 is the legacy flat format mapping each category to question texts.
+const QuestionBankVersion = 2
+
+// QuestionBank is the questions.json format
+type QuestionBank struct {
+	Version   int        `json:"version"`
+	Questions []Question `json:"questions"`
+}
+
+// ErrInvalidQuestion is returned for question bank entries that fail validation
+var ErrInvalidQuestion = errors.New("invalid question")
+
+// QuestionsByCategory stores questions organized by category, in bank order.
+// It is replaced as a whole when the bank changes; see installQuestionBank.
+var QuestionsByCategory map[string][]Question
+
+// questionsByID indexes every question of the bank by its stable ID; read it
+// with LookupQuestion
+var questionsByID map[string]Question
+
+var questionBankMu sync.RWMutex
+
+// questionDifficulties are the accepted values of Question.Difficulty
+var questionDifficulties = map[string]bool{"": true, "easy": true, "medium": true, "hard": true}
 
 // InitQuestions tries to load questions from the questions.json file
 // It tries multiple possible paths to find the file
@@ -56,18 +82,8 @@ func InitQuestions() error {
 	return fmt.Errorf("could not load questions.json from any of the tried paths: %w", lastErr)
 }
 
-// QuestionSelectionRules defines how many questions to ask from each category
-// For hard level, we use 1 question per category (6 total)
-var QuestionSelectionRules = map[string]int{
-	"Purpose of Study":       1,
-	"Academic Background":    1,
-	"University Choice":      1,
-	"Financial Capability":   1,
-	"Post-Graduation Plans":  1,
-	"Immigration Intent":     1,
-}
-
-// CategoryOrder defines the order in which categories should be asked
+// CategoryOrder is the order categories are listed in the question bank.
+// The order they are asked in comes from the session's level.
 var CategoryOrder = []string{
 	"Purpose of Study",
 	"Academic Background",
@@ -77,210 +93,292 @@ var CategoryOrder = []string{
 	"Immigration Intent",
 }
 
+// LoadQuestions reads the question bank, in either the versioned format or the
+// legacy flat one, and replaces QuestionsByCategory and the index read by
+// LookupQuestion. The levels, rubric and follow-ups next to it are read too;
+// all four are checked before any is installed, so a bad file leaves the
+// previous configuration in place.
 func LoadQuestions(path string) error {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("read questions file: %w", err)
 	}
 
-	var categories map[string][]string
-	if err := json.Unmarshal(data, &categories); err != nil {
-		return fmt.Errorf("unmarshal questions: %w", err)
+	bank, err := ParseQuestionBank(data)
+	if err != nil {
+		return err
+	}
+	levels, err := levelsNextTo(path)
+	if err != nil {
+		return err
+	}
+	rubric, err := rubricNextTo(path)
+	if err != nil {
+		return err
+	}
+	// Follow-up questions live next to the main question bank
+	followups, err := followupsNextTo(path)
+	if err != nil {
+		return err
+	}
+	// Levels decide which categories the bank must have
+	byCategory, byID, err := indexQuestionBank(bank, levels.categories())
+	if err != nil {
+		return err
 	}
 
-	QuestionsByCategory = make(map[string][]string)
-	for category, questions := range categories {
-		QuestionsByCategory[category] = questions
+	SetLevelConfig(levels)
+	SetRubric(rubric)
+	swapQuestionBank(byCategory, byID)
+	swapFollowups(followups)
+	return nil
+}
+
+// installQuestionBank swaps in a new bank in one step. Sessions keep the
+// questions they already selected; only new sessions see the change.
+func installQuestionBank(bank *QuestionBank) error {
+	byCategory, byID, err := indexQuestionBank(bank, levelCategories())
+	if err != nil {
+		return err
 	}
+	swapQuestionBank(byCategory, byID)
+	return nil
+}
 
-	// Validate that all required categories exist
-	for category := range QuestionSelectionRules {
-		if _, ok := QuestionsByCategory[category]; !ok {
-			return fmt.Errorf("required category '%s' not found in questions file", category)
-		}
+// indexQuestionBank builds the lookup maps of a bank and checks that every
+// category used by a level (levelCategories) or a visa has questions
+func indexQuestionBank(bank *QuestionBank, levelCategories []string) (map[string][]Question, map[string]Question, error) {
+	byCategory := make(map[string][]Question)
+	byID := make(map[string]Question, len(bank.Questions))
+	for _, q := range bank.Questions {
+		byCategory[q.Category] = append(byCategory[q.Category], q)
+		byID[q.ID] = q
 	}
 
-	return nil
+	if err := checkCategoryCoverage(byCategory, levelCategories); err != nil {
+		return nil, nil, err
+	}
+	return byCategory, byID, nil
 }
 
-// SelectQuestionsForSession selects questions according to the rules
-// level can be "easy", "medium", "hard", or "" for default
-func SelectQuestionsForSession(level string) []Question {
-	var selectedQuestions []Question
-	rand.Seed(time.Now().UnixNano())
-
-	// For easy level, select exactly 1 question from each of 4 specific categories
-	if level == "easy" {
-		easyCategories := []string{
-			"Purpose of Study",
-			"Academic Background",
-			"University Choice",
-			"Post-Graduation Plans",
+// checkCategoryCoverage checks that every category a level or a visa asks has
+// questions for that visa
+func checkCategoryCoverage(byCategory map[string][]Question, levelCategories []string) error {
+	covered := func(category, visaType string) bool {
+		for _, q := range byCategory[category] {
+			if q.AppliesTo(visaType) {
+				return true
+			}
 		}
-
-		for _, category := range easyCategories {
-			questions, ok := QuestionsByCategory[category]
-			if !ok || len(questions) == 0 {
-				continue
+		return false
+	}
+	for _, category := range levelCategories {
+		if !covered(category, DefaultVisaType) {
+			return fmt.Errorf("%w: required category '%s' has no questions", ErrInvalidQuestion, category)
+		}
+	}
+	for _, v := range visaProfiles {
+		for _, c := range v.Categories {
+			if !covered(c, v.Type) {
+				return fmt.Errorf("%w: required category '%s' has no %s questions", ErrInvalidQuestion, c, v.Type)
 			}
-
-			// Select one random question from this category
-			available := make([]string, len(questions))
-			copy(available, questions)
-			
-			// Shuffle and take 1 question
-			rand.Shuffle(len(available), func(i, j int) {
-				available[i], available[j] = available[j], available[i]
-			})
-
-			questionID := fmt.Sprintf("q%d_%s", len(selectedQuestions)+1, sanitizeCategory(category))
-			selectedQuestions = append(selectedQuestions, Question{
-				ID:       questionID,
-				Category: category,
-				Text:     available[0],
-			})
 		}
-
-		return selectedQuestions
 	}
+	return nil
+}
 
-	// For medium level, select exactly 1 question from each of 6 categories, then 1 extra from a random category
-	if level == "medium" {
-		allCategories := []string{
-			"Purpose of Study",
-			"Academic Background",
-			"University Choice",
-			"Financial Capability",
-			"Post-Graduation Plans",
-			"Immigration Intent",
-		}
+func swapQuestionBank(byCategory map[string][]Question, byID map[string]Question) {
+	questionBankMu.Lock()
+	defer questionBankMu.Unlock()
+	QuestionsByCategory = byCategory
+	questionsByID = byID
+}
 
-		selectedTexts := make(map[string]bool) // Track selected questions to avoid duplicates
+// LookupQuestion returns the installed bank question with the given ID
+func LookupQuestion(id string) (Question, bool) {
+	questionBankMu.RLock()
+	defer questionBankMu.RUnlock()
+	q, ok := questionsByID[id]
+	return q, ok
+}
 
-		// First, select 1 question from each category
-		for _, category := range allCategories {
-			questions, ok := QuestionsByCategory[category]
-			if !ok || len(questions) == 0 {
-				continue
-			}
+// questionsSnapshot returns the installed questions by category. The maps are
+// replaced, never modified, so the snapshot stays consistent.
+func questionsSnapshot() map[string][]Question {
+	questionBankMu.RLock()
+	defer questionBankMu.RUnlock()
+	return QuestionsByCategory
+}
 
-			// Select one random question from this category
-			available := make([]string, len(questions))
-			copy(available, questions)
-			
-			// Shuffle and take 1 question
-			rand.Shuffle(len(available), func(i, j int) {
-				available[i], available[j] = available[j], available[i]
-			})
-
-			selectedText := available[0]
-			selectedTexts[selectedText] = true
-
-			questionID := fmt.Sprintf("q%d_%s", len(selectedQuestions)+1, sanitizeCategory(category))
-			selectedQuestions = append(selectedQuestions, Question{
-				ID:       questionID,
-				Category: category,
-				Text:     selectedText,
-			})
-		}
+// ParseQuestionBank decodes and validates a question bank. The legacy flat
+// format is converted with IDs derived from the category and position, so
+// "Purpose of Study"[0] becomes "purpose_of_study_1".
+func ParseQuestionBank(data []byte) (*QuestionBank, error) {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("unmarshal questions: %w", err)
+	}
 
-		// Add 1 extra question from a random category (avoid duplicates)
-		if len(allCategories) > 0 {
-			// Pick a random category
-			randomCategory := allCategories[rand.Intn(len(allCategories))]
-			questions, ok := QuestionsByCategory[randomCategory]
-			
-			if ok && len(questions) > 0 {
-				// Filter out already selected questions
-				available := make([]string, 0)
-				for _, q := range questions {
-					if !selectedTexts[q] {
-						available = append(available, q)
-					}
-				}
-
-				// If there are available questions, select one
-				if len(available) > 0 {
-					rand.Shuffle(len(available), func(i, j int) {
-						available[i], available[j] = available[j], available[i]
-					})
-
-					questionID := fmt.Sprintf("q%d_%s", len(selectedQuestions)+1, sanitizeCategory(randomCategory))
-					selectedQuestions = append(selectedQuestions, Question{
-						ID:       questionID,
-						Category: randomCategory,
-						Text:     available[0],
-					})
-				}
+	var bank QuestionBank
+	if _, ok := probe["questions"]; ok {
+		if err := json.Unmarshal(data, &bank); err != nil {
+			return nil, fmt.Errorf("unmarshal questions: %w", err)
+		}
+		if bank.Version > QuestionBankVersion {
+			return nil, fmt.Errorf("questions file version %d is newer than supported version %d", bank.Version, QuestionBankVersion)
+		}
+	} else {
+		var categories map[string][]string
+		if err := json.Unmarshal(data, &categories); err != nil {
+			return nil, fmt.Errorf("unmarshal questions: %w", err)
+		}
+		bank.Version = 1
+		// Keep the file order stable across loads
+		names := make([]string, 0, len(categories))
+		for category := range categories {
+			names = append(names, category)
+		}
+		sort.Strings(names)
+		for _, category := range names {
+			for i, text := range categories[category] {
+				bank.Questions = append(bank.Questions, Question{
+					ID:       legacyQuestionID(category, i),
+					Category: category,
+					Text:     text,
+				})
 			}
 		}
+	}
 
-		return selectedQuestions
+	if err := bank.Validate(); err != nil {
+		return nil, err
 	}
+	return &bank, nil
+}
 
-	// For hard level, select exactly 2 questions from each of 6 categories (check for duplicates)
-	if level == "hard" || level == "" {
-		allCategories := []string{
-			"Purpose of Study",
-			"Academic Background",
-			"University Choice",
-			"Financial Capability",
-			"Post-Graduation Plans",
-			"Immigration Intent",
+// Validate checks that every question has an ID, category and text, that IDs
+// are unique and that difficulties and visa types are known
+func (b *QuestionBank) Validate() error {
+	seen := make(map[string]bool, len(b.Questions))
+	for i, q := range b.Questions {
+		if q.ID == "" || q.Category == "" || q.Text == "" {
+			return fmt.Errorf("%w: question %d needs an id, category and text", ErrInvalidQuestion, i+1)
 		}
-
-		selectedTexts := make(map[string]bool) // Track selected questions to avoid duplicates
-
-		for _, category := range allCategories {
-			questions, ok := QuestionsByCategory[category]
-			if !ok || len(questions) == 0 {
-				continue
+		if seen[q.ID] {
+			return fmt.Errorf("%w: duplicate question id '%s'", ErrInvalidQuestion, q.ID)
+		}
+		seen[q.ID] = true
+		if !questionDifficulties[q.Difficulty] {
+			return fmt.Errorf("%w: question '%s' has unknown difficulty '%s'", ErrInvalidQuestion, q.ID, q.Difficulty)
+		}
+		for _, visaType := range q.VisaTypes {
+			if v, err := LookupVisaType(visaType); err != nil || v.Type != visaType {
+				return fmt.Errorf("%w: question '%s' has unknown visa type '%s'", ErrInvalidQuestion, q.ID, visaType)
 			}
+		}
+	}
+	return nil
+}
 
-			// Filter out already selected questions from this category
-			available := make([]string, 0)
-			for _, q := range questions {
-				if !selectedTexts[q] {
-					available = append(available, q)
-				}
-			}
+// legacyQuestionID is the stable ID of the i-th question of a category in the flat format
+func legacyQuestionID(category string, i int) string {
+	return fmt.Sprintf("%s_%d", strings.ToLower(sanitizeCategory(category)), i+1)
+}
 
-			// If we don't have enough questions, use what we have
-			if len(available) == 0 {
-				continue
-			}
+// SelectQuestionsForSession selects questions as defined by the named level,
+// or the default level when level is empty. Unknown levels select nothing;
+// check them with LookupLevel first.
+func SelectQuestionsForSession(level string) []Question {
+	return SelectQuestionsWithSeed(level, newSessionSeed())
+}
 
-			// Shuffle available questions
-			rand.Shuffle(len(available), func(i, j int) {
-				available[i], available[j] = available[j], available[i]
-			})
+// SelectQuestionsWithSeed is SelectQuestionsForSession with a fixed seed. The
+// same seed, level and question bank always select the same questions in the
+// same order.
+func SelectQuestionsWithSeed(level string, seed int64) []Question {
+	return SelectQuestionsForUser("", SessionOptions{Level: level, Seed: &seed})
+}
 
-			// Select up to 2 questions from this category
-			count := 2
-			if len(available) < count {
-				count = len(available)
-			}
+// SelectQuestionsForUser selects the questions of a user's session for the
+// level and visa in opts, with a random seed when opts has none. Targeted
+// levels weigh questions by the user's weak criteria and review schedule, so
+// replaying one selects the same questions only while the user's history is
+// unchanged. Unknown levels and visas select nothing.
+func SelectQuestionsForUser(userID string, opts SessionOptions) []Question {
+	def, err := LookupLevel(opts.Level)
+	if err != nil {
+		return nil
+	}
+	visa, err := LookupVisaType(opts.VisaType)
+	if err != nil {
+		return nil
+	}
+	seed := newSessionSeed()
+	if opts.Seed != nil {
+		seed = *opts.Seed
+	}
 
-			for i := 0; i < count; i++ {
-				selectedText := available[i]
-				selectedTexts[selectedText] = true
+	def.Categories = def.categoriesFor(visa)
+	questions := questionsForVisa(questionsSnapshot(), visa.Type)
+	rng := rand.New(rand.NewSource(seed))
+	if def.Strategy == LevelStrategyTargeted {
+		return selectTargetedQuestions(def, questions, rng, loadTargetingProfile(userID))
+	}
+	return selectQuestions(def, questions, rng)
+}
 
-				questionID := fmt.Sprintf("q%d_%s", len(selectedQuestions)+1, sanitizeCategory(category))
-				selectedQuestions = append(selectedQuestions, Question{
-					ID:       questionID,
-					Category: category,
-					Text:     selectedText,
-				})
+// newSessionSeed draws a seed from the shared, concurrency-safe source
+func newSessionSeed() int64 {
+	return rand.Int63()
+}
+
+// selectQuestions picks Count random questions from each category of the level
+// in order, then ExtraRandom more from random categories of the level. The same
+// text is never asked twice. All randomness comes from rng.
+func selectQuestions(level Level, questionsByCategory map[string][]Question, rng *rand.Rand) []Question {
+	var selectedQuestions []Question
+	selectedTexts := make(map[string]bool) // Track selected questions to avoid duplicates
+
+	// unused returns the questions of a category not selected yet, shuffled
+	unused := func(category string) []Question {
+		available := make([]Question, 0)
+		for _, q := range questionsByCategory[category] {
+			if !selectedTexts[q.Text] {
+				available = append(available, q)
 			}
 		}
+		rng.Shuffle(len(available), func(i, j int) {
+			available[i], available[j] = available[j], available[i]
+		})
+		return available
+	}
+
+	for _, lc := range level.Categories {
+		available := unused(lc.Category)
+		// If we don't have enough questions, use what we have
+		count := lc.Count
+		if len(available) < count {
+			count = len(available)
+		}
+		for i := 0; i < count; i++ {
+			selectedTexts[available[i].Text] = true
+			selectedQuestions = append(selectedQuestions, available[i])
+		}
+	}
 
-		return selectedQuestions
+	// An extra question is skipped when its category has none left
+	for i := 0; i < level.ExtraRandom; i++ {
+		category := level.Categories[rng.Intn(len(level.Categories))].Category
+		if available := unused(category); len(available) > 0 {
+			selectedTexts[available[0].Text] = true
+			selectedQuestions = append(selectedQuestions, available[0])
+		}
 	}
 
 	return selectedQuestions
 }
 
-// sanitizeCategory converts category name to a valid ID suffix
+// sanitizeCategory converts category name to a valid ID part
 func sanitizeCategory(category string) string {
 	// Simple sanitization - replace spaces and special chars
 	result := ""
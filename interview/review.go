@@ -0,0 +1,177 @@
+package interview
+
+import (
+	"log"
+	"math"
+	"sync"
+	"time"
+)
+
+// SM-2 parameters. Quality is graded 0–5 and 3 is the lowest passing grade.
+const (
+	initialEaseFactor   = 2.5
+	minEaseFactor       = 1.3
+	passingQuality      = 3
+	maxQuality          = 5
+	firstIntervalDays   = 1
+	secondIntervalDays  = 6
+	reviewDay           = 24 * time.Hour
+	maxOverdueIntervals = 3.0 // overdue items stop gaining weight after this many intervals
+)
+
+// ReviewItem is the spaced-repetition schedule of one bank question for one user
+type ReviewItem struct {
+	UserID       string    `json:"user_id"`
+	QuestionID   string    `json:"question_id"`
+	Category     string    `json:"category"`
+	Repetitions  int       `json:"repetitions"` // passing reviews in a row
+	EaseFactor   float64   `json:"ease_factor"`
+	IntervalDays int       `json:"interval_days"`
+	LastQuality  int       `json:"last_quality"` // 0–5
+	ReviewedAt   time.Time `json:"reviewed_at"`
+	DueAt        time.Time `json:"due_at"`
+}
+
+// Review updates the schedule with a new answer of the given quality, SM-2 style:
+// a failed answer starts the question over, a passed one pushes it further out.
+func (r *ReviewItem) Review(quality int, at time.Time) {
+	if quality < 0 {
+		quality = 0
+	}
+	if quality > maxQuality {
+		quality = maxQuality
+	}
+	if r.EaseFactor == 0 {
+		r.EaseFactor = initialEaseFactor
+	}
+
+	if quality < passingQuality {
+		r.Repetitions = 0
+		r.IntervalDays = firstIntervalDays
+	} else {
+		switch r.Repetitions {
+		case 0:
+			r.IntervalDays = firstIntervalDays
+		case 1:
+			r.IntervalDays = secondIntervalDays
+		default:
+			r.IntervalDays = int(math.Round(float64(r.IntervalDays) * r.EaseFactor))
+		}
+		r.Repetitions++
+	}
+
+	miss := float64(maxQuality - quality)
+	r.EaseFactor = math.Max(minEaseFactor, r.EaseFactor+0.1-miss*(0.08+miss*0.02))
+	r.LastQuality = quality
+	r.ReviewedAt = at
+	r.DueAt = at.Add(time.Duration(r.IntervalDays) * reviewDay)
+}
+
+// ReviewStore persists review schedules
+type ReviewStore interface {
+	// ListReviews returns every review item of a user
+	ListReviews(userID string) ([]ReviewItem, error)
+	// SaveReview inserts or updates one review item
+	SaveReview(item ReviewItem) error
+}
+
+var (
+	reviewStore   ReviewStore = NewMemoryReviewStore()
+	reviewStoreMu sync.RWMutex
+)
+
+// SetReviewStore replaces the store used for spaced-repetition schedules
+func SetReviewStore(store ReviewStore) {
+	reviewStoreMu.Lock()
+	defer reviewStoreMu.Unlock()
+	reviewStore = store
+}
+
+// GetReviewStore returns the store used for spaced-repetition schedules
+func GetReviewStore() ReviewStore {
+	reviewStoreMu.RLock()
+	defer reviewStoreMu.RUnlock()
+	return reviewStore
+}
+
+// memoryReviewStore keeps review schedules in process memory
+type memoryReviewStore struct {
+	mu    sync.RWMutex
+	items map[string]map[string]ReviewItem // user ID -> question ID -> item
+}
+
+// NewMemoryReviewStore returns a ReviewStore backed by an in-process map
+func NewMemoryReviewStore() ReviewStore {
+	return &memoryReviewStore{items: make(map[string]map[string]ReviewItem)}
+}
+
+func (m *memoryReviewStore) ListReviews(userID string) ([]ReviewItem, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	items := make([]ReviewItem, 0, len(m.items[userID]))
+	for _, item := range m.items[userID] {
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (m *memoryReviewStore) SaveReview(item ReviewItem) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.items[item.UserID] == nil {
+		m.items[item.UserID] = make(map[string]ReviewItem)
+	}
+	m.items[item.UserID][item.QuestionID] = item
+	return nil
+}
+
+// answerQuality turns an analysis into an SM-2 quality: the mean of the
+// scored criteria, each placed on its own scale, mapped onto 1–5 and rounded.
+// On the built-in 1–5 scales that is the mean score itself. It returns false
+// when nothing was scored.
+func answerQuality(analysis *AnalysisResponse) (int, bool) {
+	scores := analysis.Scores.ByCriterion()
+	sum, n := 0.0, 0
+	for _, c := range RubricCriteria() {
+		if score := scores[c.Key]; score != nil {
+			sum += c.normalize(*score)
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return int(math.Round(1 + (maxQuality-1)*sum/float64(n))), true
+}
+
+// RecordReview schedules the next review of a bank question from the grade of
+// the user's answer. Follow-ups are not scheduled; they are not in the bank.
+// Failures are logged, as the answer itself is already saved.
+func RecordReview(userID string, q Question, analysis *AnalysisResponse) {
+	if userID == "" || q.IsFollowup || analysis == nil {
+		return
+	}
+	quality, ok := answerQuality(analysis)
+	if !ok {
+		return
+	}
+
+	store := GetReviewStore()
+	item := ReviewItem{UserID: userID, QuestionID: q.ID, Category: q.Category}
+	items, err := store.ListReviews(userID)
+	if err != nil {
+		log.Printf("Failed to load reviews of user %s: %v", userID, err)
+		return
+	}
+	for _, existing := range items {
+		if existing.QuestionID == q.ID {
+			item = existing
+			break
+		}
+	}
+	item.Category = q.Category
+	item.Review(quality, time.Now())
+	if err := store.SaveReview(item); err != nil {
+		log.Printf("Failed to save review of question %s for user %s: %v", q.ID, userID, err)
+	}
+}
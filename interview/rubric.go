@@ -0,0 +1,232 @@
+package interview
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ScoreScale is the range of scores a criterion can get; higher is better
+type ScoreScale struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// Criterion is one dimension answers are graded on
+type Criterion struct {
+	Key   string `json:"key"`   // name in analysis scores and feedback, e.g. "migration_intent"
+	Label string `json:"label"` // shown in progress reports
+	// Area names the criterion in session summaries ("Strong return intent")
+	Area string `json:"area"`
+	// RedFlag is listed in session summaries when an answer scores at the bottom of the scale
+	RedFlag string     `json:"red_flag"`
+	Scale   ScoreScale `json:"scale"`
+	// Weight is the criterion's share of an answer's weighted score
+	Weight float64 `json:"weight"`
+	// Categories are the question categories that test the criterion; empty
+	// means it is graded for every answer
+	Categories []string `json:"categories,omitempty"`
+	// Prompt is the scoring guide sent to the model, one line per score band
+	Prompt []string `json:"prompt"`
+}
+
+// Rubric is the rubric.json format. Criteria are listed in display order.
+type Rubric struct {
+	Criteria []Criterion `json:"criteria"`
+	// CategoryWeights weigh answers in the session score by question
+	// category; unlisted categories weigh 1
+	CategoryWeights map[string]float64 `json:"category_weights,omitempty"`
+	// GradeBands classify weighted scores, highest threshold first. The
+	// built-in bands are used when rubric.json has none.
+	GradeBands []GradeBand `json:"grade_bands,omitempty"`
+}
+
+var (
+	rubric   = defaultRubric()
+	rubricMu sync.RWMutex
+)
+
+// builtinRubric is the rubric.json shipped next to this file
+//
+//go:embed rubric.json
+var builtinRubric []byte
+
+// defaultRubric is the built-in rubric.json, used when no rubric.json sits
+// next to questions.json
+func defaultRubric() *Rubric {
+	var r Rubric
+	if err := json.Unmarshal(builtinRubric, &r); err != nil {
+		panic(fmt.Sprintf("built-in rubric.json: %v", err))
+	}
+	if err := r.Validate(); err != nil {
+		panic(fmt.Sprintf("built-in rubric.json: %v", err))
+	}
+	return &r
+}
+
+// LoadRubric reads criterion definitions and makes them the configured rubric
+func LoadRubric(path string) error {
+	r, err := readRubric(path)
+	if err != nil {
+		return err
+	}
+	SetRubric(r)
+	return nil
+}
+
+// readRubric reads and validates a rubric file without installing it
+func readRubric(path string) (*Rubric, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read rubric file: %w", err)
+	}
+
+	var r Rubric
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("unmarshal rubric: %w", err)
+	}
+	if len(r.GradeBands) == 0 {
+		r.GradeBands = defaultGradeBands()
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// rubricNextTo reads rubric.json from the directory of questions.json,
+// falling back to the built-in rubric when there is none
+func rubricNextTo(questionsPath string) (*Rubric, error) {
+	path := filepath.Join(filepath.Dir(questionsPath), "rubric.json")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return defaultRubric(), nil
+	}
+	return readRubric(path)
+}
+
+// Validate checks that criterion keys are unique, that every criterion has a
+// label, a scale with room for more than one score and a positive weight, that
+// category weights are positive and that the grade bands cover every score
+func (r *Rubric) Validate() error {
+	if len(r.Criteria) == 0 {
+		return errors.New("rubric defines no criteria")
+	}
+	keys := make(map[string]bool, len(r.Criteria))
+	for _, c := range r.Criteria {
+		if c.Key == "" || c.Key == "total_score" {
+			return fmt.Errorf("criterion needs a key other than 'total_score', got '%s'", c.Key)
+		}
+		if keys[c.Key] {
+			return fmt.Errorf("duplicate criterion '%s'", c.Key)
+		}
+		keys[c.Key] = true
+		if c.Label == "" {
+			return fmt.Errorf("criterion '%s' needs a label", c.Key)
+		}
+		if c.Scale.Max <= c.Scale.Min {
+			return fmt.Errorf("criterion '%s' needs a scale with max above min", c.Key)
+		}
+		if c.Weight <= 0 {
+			return fmt.Errorf("criterion '%s' needs a positive weight", c.Key)
+		}
+	}
+	for category, w := range r.CategoryWeights {
+		if w <= 0 {
+			return fmt.Errorf("category '%s' needs a positive weight", category)
+		}
+	}
+	return validateGradeBands(r.GradeBands)
+}
+
+// SetRubric replaces the configured rubric. The rubric must be valid.
+func SetRubric(r *Rubric) {
+	rubricMu.Lock()
+	defer rubricMu.Unlock()
+	rubric = r
+}
+
+// RubricCriteria returns the configured criteria in display order
+func RubricCriteria() []Criterion {
+	rubricMu.RLock()
+	defer rubricMu.RUnlock()
+	return append([]Criterion(nil), rubric.Criteria...)
+}
+
+// CriterionKeys lists the keys of the configured criteria in display order
+func CriterionKeys() []string {
+	criteria := RubricCriteria()
+	keys := make([]string, len(criteria))
+	for i, c := range criteria {
+		keys[i] = c.Key
+	}
+	return keys
+}
+
+// LookupCriterion returns the configured criterion with the given key
+func LookupCriterion(key string) (Criterion, bool) {
+	for _, c := range RubricCriteria() {
+		if c.Key == key {
+			return c, true
+		}
+	}
+	return Criterion{}, false
+}
+
+// AppliesTo reports whether the criterion is graded for answers in category
+func (c Criterion) AppliesTo(category string) bool {
+	return len(c.Categories) == 0 || containsString(c.Categories, category)
+}
+
+// strong, weak and critical place a score on the criterion's scale. On a 1–5
+// scale they are 4 and up, 3 and below, and 2 and below.
+func (c Criterion) strong(score int) bool   { return score >= c.Scale.Max-1 }
+func (c Criterion) weak(score int) bool     { return c.weakAverage(float64(score)) }
+func (c Criterion) critical(score int) bool { return score <= c.Scale.Min+1 }
+
+// weakAverage is weak for an average of scores, such as a moving average
+func (c Criterion) weakAverage(avg float64) bool { return avg <= float64(c.Scale.Max-2) }
+
+// Score returns the score of a criterion, or nil when it was not graded
+func (s AnalysisScores) Score(key string) *int {
+	return s.Criteria[key]
+}
+
+// ByCriterion returns the per-criterion scores keyed by their JSON names
+func (s AnalysisScores) ByCriterion() map[string]*int {
+	return s.Criteria
+}
+
+// MarshalJSON writes the criteria next to total_score, as in the analysis format
+func (s AnalysisScores) MarshalJSON() ([]byte, error) {
+	flat := make(map[string]any, len(s.Criteria)+1)
+	for key, score := range s.Criteria {
+		flat[key] = score
+	}
+	flat["total_score"] = s.TotalScore
+	return json.Marshal(flat)
+}
+
+// UnmarshalJSON reads every key but total_score as a criterion, so analyses
+// stored before a criterion was added or removed still decode
+func (s *AnalysisScores) UnmarshalJSON(data []byte) error {
+	var flat map[string]*int
+	if err := json.Unmarshal(data, &flat); err != nil {
+		return err
+	}
+	s.Criteria = make(map[string]*int, len(flat))
+	s.TotalScore = 0
+	for key, score := range flat {
+		if key == "total_score" {
+			if score != nil {
+				s.TotalScore = *score
+			}
+			continue
+		}
+		s.Criteria[key] = score
+	}
+	return nil
+}
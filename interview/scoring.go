@@ -0,0 +1,129 @@
+package interview
+
+import (
+	"errors"
+	"fmt"
+)
+
+// GradeBand maps weighted scores of at least MinPercent to a classification
+// for answers and a letter grade and recommendation for session summaries
+type GradeBand struct {
+	Classification string  `json:"classification"` // Excellent, Good, Average or Weak
+	Grade          string  `json:"grade"`          // A, B, C or D
+	MinPercent     float64 `json:"min_percent"`
+	Recommendation string  `json:"recommendation"`
+}
+
+// defaultGradeBands are the bands of the built-in rubric.json, used by a
+// rubric.json without grade_bands. They are calibrated on the 1–5 scale:
+// answers averaging about 4.2 are Excellent, 3.4 Good and 2.4 Average.
+func defaultGradeBands() []GradeBand {
+	return defaultRubric().GradeBands
+}
+
+// validateGradeBands checks that bands use the known classifications, are
+// listed from the highest threshold down and that the last one catches every score
+func validateGradeBands(bands []GradeBand) error {
+	if len(bands) == 0 {
+		return errors.New("rubric defines no grade bands")
+	}
+	for i, b := range bands {
+		if !containsString(validClassifications, b.Classification) {
+			return fmt.Errorf("grade band %d has unknown classification '%s'", i+1, b.Classification)
+		}
+		if b.Grade == "" {
+			return fmt.Errorf("grade band '%s' needs a grade", b.Classification)
+		}
+		if b.MinPercent < 0 || b.MinPercent > 100 {
+			return fmt.Errorf("grade band '%s' needs min_percent between 0 and 100", b.Classification)
+		}
+		if i > 0 && b.MinPercent >= bands[i-1].MinPercent {
+			return errors.New("grade bands must be listed by decreasing min_percent")
+		}
+	}
+	if last := bands[len(bands)-1]; last.MinPercent != 0 {
+		return fmt.Errorf("last grade band '%s' must start at 0", last.Classification)
+	}
+	return nil
+}
+
+// AnswerPercent is the weighted score of one answer from 0 to 100: each scored
+// criterion is placed on its scale and weighted by the rubric. Criteria no
+// longer in the rubric are ignored. It returns false when nothing was scored.
+func AnswerPercent(scores AnalysisScores) (float64, bool) {
+	total, weights := 0.0, 0.0
+	for _, c := range RubricCriteria() {
+		score := scores.Score(c.Key)
+		if score == nil {
+			continue
+		}
+		total += c.Weight * c.normalize(*score)
+		weights += c.Weight
+	}
+	if weights == 0 {
+		return 0, false
+	}
+	return 100 * total / weights, true
+}
+
+// SessionPercent averages the answer percentages of a session, weighting each
+// answer by the rubric weight of its question category. Answers without
+// scores do not count.
+func SessionPercent(analyses []AnalysisRecord) (float64, bool) {
+	total, weights := 0.0, 0.0
+	for _, record := range analyses {
+		percent, ok := AnswerPercent(record.Analysis.Scores)
+		if !ok {
+			continue
+		}
+		weight := answerCategoryWeight(record.Category)
+		total += weight * percent
+		weights += weight
+	}
+	if weights == 0 {
+		return 0, false
+	}
+	return total / weights, true
+}
+
+// GradeBandFor returns the highest band whose threshold the percentage reaches
+func GradeBandFor(percent float64) GradeBand {
+	bands := gradeBands()
+	for _, b := range bands {
+		if percent >= b.MinPercent {
+			return b
+		}
+	}
+	return bands[len(bands)-1]
+}
+
+// classifyAnswer is the classification of an answer, Weak when nothing was scored
+func classifyAnswer(scores AnalysisScores) (string, float64) {
+	percent, ok := AnswerPercent(scores)
+	if !ok {
+		bands := gradeBands()
+		return bands[len(bands)-1].Classification, 0
+	}
+	return GradeBandFor(percent).Classification, percent
+}
+
+// normalize places a score on the criterion's scale, from 0 at Min to 1 at Max
+func (c Criterion) normalize(score int) float64 {
+	return float64(score-c.Scale.Min) / float64(c.Scale.Max-c.Scale.Min)
+}
+
+func gradeBands() []GradeBand {
+	rubricMu.RLock()
+	defer rubricMu.RUnlock()
+	return rubric.GradeBands
+}
+
+// answerCategoryWeight is the rubric weight of a question category, 1 when not listed
+func answerCategoryWeight(category string) float64 {
+	rubricMu.RLock()
+	defer rubricMu.RUnlock()
+	if w, ok := rubric.CategoryWeights[category]; ok {
+		return w
+	}
+	return 1
+}
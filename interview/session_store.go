@@ -1,57 +1,281 @@
 package interview
 
 import (
+	"errors"
+	"log"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// ErrSessionNotFound is returned by a SessionStore when no session has the given ID.
+var ErrSessionNotFound = errors.New("session not found")
+
+// ErrSessionDeleted is returned by SaveSession for a session that has been deleted,
+// so a save racing the delete cannot bring it back.
+var ErrSessionDeleted = errors.New("session was deleted")
+
+// ErrSessionForbidden is returned when a user accesses a session owned by someone else.
+var ErrSessionForbidden = errors.New("session belongs to another user")
+
+// SessionStore persists interview sessions so they survive restarts and can be
+// shared between replicas.
+type SessionStore interface {
+	// SaveSession creates or updates a session. It returns ErrSessionDeleted
+	// for a session that has been deleted.
+	SaveSession(s *Session) error
+	GetSession(id string) (*Session, error)
+	// ListSessions returns one page of matching sessions, newest first, and the total number of matches
+	ListSessions(filter SessionFilter) ([]*Session, int, error)
+	DeleteSession(id string) error
+}
+
+// SessionFilter narrows down ListSessions. Zero values match everything.
+type SessionFilter struct {
+	UserID   string
+	Level    string
+	VisaType string
+	Status   SessionStatus
+	From     time.Time // sessions created at or after From
+	To       time.Time // sessions created before To
+	// PendingGrading keeps only sessions with answers waiting to be graded
+	PendingGrading bool
+	Limit          int
+	Offset         int
+}
+
+// Matches reports whether the session satisfies the filter (ignoring pagination)
+func (f SessionFilter) Matches(s *Session) bool {
+	if f.UserID != "" && s.UserID != f.UserID {
+		return false
+	}
+	if f.Level != "" && s.Level != f.Level {
+		return false
+	}
+	if f.VisaType != "" && visaProfile(s.VisaType).Type != f.normalized().VisaType {
+		return false
+	}
+	if f.Status != "" && s.Status != f.Status {
+		return false
+	}
+	if !f.From.IsZero() && s.CreatedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !s.CreatedAt.Before(f.To) {
+		return false
+	}
+	if f.PendingGrading && !s.HasPendingGrading() {
+		return false
+	}
+	return true
+}
+
+// normalized returns the filter with its visa type in the form sessions store
+// it, so every store can compare it as is
+func (f SessionFilter) normalized() SessionFilter {
+	if f.VisaType == "" {
+		return f
+	}
+	if v, err := LookupVisaType(f.VisaType); err == nil {
+		f.VisaType = v.Type
+	}
+	return f
+}
+
 var (
-	sessions   = make(map[string]*Session)
-	sessionsMu sync.RWMutex
+	sessionStore   SessionStore = NewMemorySessionStore()
+	sessionStoreMu sync.RWMutex
 )
 
+// SetSessionStore replaces the store used by SaveSession and GetSession
+func SetSessionStore(store SessionStore) {
+	sessionStoreMu.Lock()
+	defer sessionStoreMu.Unlock()
+	sessionStore = store
+}
+
+// GetSessionStore returns the store currently used by SaveSession and GetSession
+func GetSessionStore() SessionStore {
+	sessionStoreMu.RLock()
+	defer sessionStoreMu.RUnlock()
+	return sessionStore
+}
+
 func NewSession(userID string) *Session {
 	return NewSessionWithLevel(userID, "")
 }
 
+// SessionOptions choose the questions of a new session. Zero values mean the
+// default level, the default visa and a random seed.
+type SessionOptions struct {
+	Level    string
+	VisaType string
+	Seed     *int64 // replays the selection of an earlier session
+	// SummaryAttempt is best or latest, the attempt at a retried question the summary counts
+	SummaryAttempt string
+}
+
+// NewSessionWithLevel starts a session with questions selected for the named
+// level. An empty level means the default level, which is recorded on the session.
 func NewSessionWithLevel(userID string, level string) *Session {
+	return NewSessionWithOptions(userID, SessionOptions{Level: level})
+}
+
+// NewSessionWithSeed is NewSessionWithLevel with a fixed selection seed, used
+// to replay a session with the questions it was asked
+func NewSessionWithSeed(userID string, level string, seed int64) *Session {
+	return NewSessionWithOptions(userID, SessionOptions{Level: level, Seed: &seed})
+}
+
+// NewSessionWithOptions starts a session for the level, visa and seed in opts.
+// Unknown levels and visas start a session without questions; check them with
+// LookupLevel and LookupVisaType first.
+func NewSessionWithOptions(userID string, opts SessionOptions) *Session {
 	now := time.Now()
-	
-	// Select questions for this session based on level
-	selectedQuestions := SelectQuestionsForSession(level)
-	
+	if opts.Seed == nil {
+		seed := newSessionSeed()
+		opts.Seed = &seed
+	}
+
+	// Select questions for this session based on level and visa
+	selectedQuestions := SelectQuestionsForUser(userID, opts)
+	level, visaType := opts.Level, opts.VisaType
+	if def, err := LookupLevel(level); err == nil {
+		level = def.Name
+	}
+	if visa, err := LookupVisaType(visaType); err == nil {
+		visaType = visa.Type
+	}
+	summaryAttempt, _ := ParseSummaryAttempt(opts.SummaryAttempt)
+
 	session := &Session{
-		ID:               uuid.NewString(),
-		UserID:           userID,
+		ID:                uuid.NewString(),
+		UserID:            userID,
+		Level:             level,
+		VisaType:          visaType,
+		Seed:              *opts.Seed,
+		SummaryAttempt:    summaryAttempt,
 		SelectedQuestions: selectedQuestions,
-		QuestionIndex:    0,
-		Answers:          []Answer{},
-		Scores:           Scores{},
-		Status:           SessionStatusActive,
-		CreatedAt:        now,
-		UpdatedAt:        now,
-	}
-	
+		QuestionIndex:     0,
+		Answers:           []Answer{},
+		Scores:            Scores{},
+		Status:            SessionStatusActive,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
 	// Set current question to first selected question
 	if len(selectedQuestions) > 0 {
 		session.CurrentQuestion = selectedQuestions[0].ID
 	}
-	
+
 	return session
 }
 
-func SaveSession(s *Session) {
-	sessionsMu.Lock()
-	defer sessionsMu.Unlock()
-	s.UpdatedAt = time.Now()
-	sessions[s.ID] = s
+// OwnedBy reports whether the session belongs to the given user
+func (s *Session) OwnedBy(userID string) bool {
+	return s.UserID == userID
 }
 
+// SaveSession persists the session in the configured store
+func SaveSession(s *Session) error {
+	if err := GetSessionStore().SaveSession(s); err != nil {
+		log.Printf("Error saving session %s: %v", s.ID, err)
+		return err
+	}
+	return nil
+}
+
+// GetSession loads a session from the configured store
 func GetSession(id string) (*Session, bool) {
-	sessionsMu.RLock()
-	defer sessionsMu.RUnlock()
-	s, ok := sessions[id]
-	return s, ok
+	s, err := GetSessionStore().GetSession(id)
+	if err != nil {
+		if !errors.Is(err, ErrSessionNotFound) {
+			log.Printf("Error loading session %s: %v", id, err)
+		}
+		return nil, false
+	}
+	return s, true
+}
+
+// ListSessions returns matching sessions from the configured store. The
+// filter's visa type is matched case-insensitively, like session options.
+func ListSessions(filter SessionFilter) ([]*Session, int, error) {
+	return GetSessionStore().ListSessions(filter.normalized())
+}
+
+// DeleteSession removes a session from the configured store
+func DeleteSession(id string) error {
+	return GetSessionStore().DeleteSession(id)
+}
+
+// memorySessionStore keeps sessions in process memory. Sessions are lost on restart.
+type memorySessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string]*Session
+	deleted  map[string]bool
+}
+
+// NewMemorySessionStore returns a SessionStore backed by an in-process map
+func NewMemorySessionStore() SessionStore {
+	return &memorySessionStore{sessions: make(map[string]*Session), deleted: make(map[string]bool)}
+}
+
+func (m *memorySessionStore) SaveSession(s *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.deleted[s.ID] {
+		return ErrSessionDeleted
+	}
+	s.UpdatedAt = time.Now()
+	m.sessions[s.ID] = s
+	return nil
+}
+
+func (m *memorySessionStore) GetSession(id string) (*Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.sessions[id]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	return s, nil
+}
+
+func (m *memorySessionStore) ListSessions(filter SessionFilter) ([]*Session, int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var matched []*Session
+	for _, s := range m.sessions {
+		if filter.Matches(s) {
+			matched = append(matched, s)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].CreatedAt.After(matched[j].CreatedAt)
+	})
+
+	total := len(matched)
+	if filter.Offset >= total {
+		return []*Session{}, total, nil
+	}
+	matched = matched[filter.Offset:]
+	if filter.Limit > 0 && len(matched) > filter.Limit {
+		matched = matched[:filter.Limit]
+	}
+	return matched, total, nil
+}
+
+func (m *memorySessionStore) DeleteSession(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.sessions[id]; !ok {
+		return ErrSessionNotFound
+	}
+	delete(m.sessions, id)
+	m.deleted[id] = true
+	return nil
 }
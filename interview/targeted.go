@@ -0,0 +1,148 @@
+package interview
+
+import (
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Level strategies
+const (
+	LevelStrategyRandom   = "random"   // Count random questions per category (default)
+	LevelStrategyTargeted = "targeted" // Questions picks weighted by the user's weak areas and review schedule
+)
+
+// Weights of the targeted strategy
+const (
+	unseenQuestionWeight  = 1.0  // questions the user never answered
+	minNotDueWeight       = 0.05 // a question just reviewed successfully
+	maxNotDueWeight       = 0.3  // a question almost due again
+	maxCategoryWeakWeight = 2.0  // added to a category whose criteria average the bottom of their scale
+)
+
+// targetingSessions is how many of the user's newest finished sessions the
+// targeted strategy reads. Criterion averages only use the last
+// DefaultProgressWindow sessions that graded the criterion, so a few more
+// cover criteria that not every session grades.
+const targetingSessions = 4 * DefaultProgressWindow
+
+// targetingProfile is what the targeted strategy knows about a user
+type targetingProfile struct {
+	reviews map[string]ReviewItem // by question ID
+	// criterionAverages is the latest moving average of each criterion, on its scale
+	criterionAverages map[string]float64
+	now               time.Time
+}
+
+// loadTargetingProfile reads the user's review schedule and newest finished sessions.
+// Errors are logged and leave the profile partly empty, which only makes the
+// selection less targeted.
+func loadTargetingProfile(userID string) *targetingProfile {
+	p := &targetingProfile{
+		reviews:           make(map[string]ReviewItem),
+		criterionAverages: make(map[string]float64),
+		now:               time.Now(),
+	}
+	if userID == "" {
+		return p
+	}
+
+	items, err := GetReviewStore().ListReviews(userID)
+	if err != nil {
+		log.Printf("Failed to load reviews of user %s: %v", userID, err)
+	}
+	for _, item := range items {
+		p.reviews[item.QuestionID] = item
+	}
+
+	sessions, _, err := ListSessions(SessionFilter{UserID: userID, Status: SessionStatusFinished, Limit: targetingSessions})
+	if err != nil {
+		log.Printf("Failed to load sessions of user %s: %v", userID, err)
+	}
+	for _, c := range BuildProgressReport(sessions, DefaultProgressWindow).Criteria {
+		p.criterionAverages[c.Criterion] = c.Latest
+	}
+	return p
+}
+
+// categoryWeight grows with how weak the user is on the criteria the category
+// exercises. Criteria graded in every category, like communication quality,
+// do not single out any category and are skipped.
+func (p *targetingProfile) categoryWeight(category string) float64 {
+	weight := 1.0
+	for _, criterion := range RubricCriteria() {
+		average, ok := p.criterionAverages[criterion.Key]
+		if !ok || len(criterion.Categories) == 0 || !criterion.AppliesTo(category) {
+			continue
+		}
+		scale := criterion.Scale
+		weight += maxCategoryWeakWeight * math.Max(0, float64(scale.Max)-average) / float64(scale.Max-scale.Min)
+	}
+	return weight
+}
+
+// questionWeight follows the review schedule: due questions come first, more
+// so when they were answered poorly or are long overdue; questions that are
+// not due yet fade out and come back as their due date nears.
+func (p *targetingProfile) questionWeight(id string) float64 {
+	item, ok := p.reviews[id]
+	if !ok {
+		return unseenQuestionWeight
+	}
+	interval := time.Duration(item.IntervalDays) * reviewDay
+	if interval <= 0 {
+		interval = reviewDay
+	}
+	if !p.now.Before(item.DueAt) {
+		overdue := math.Min(maxOverdueIntervals, float64(p.now.Sub(item.DueAt))/float64(interval))
+		return 1 + float64(maxQuality-item.LastQuality) + overdue
+	}
+	elapsed := float64(p.now.Sub(item.ReviewedAt)) / float64(interval)
+	return minNotDueWeight + (maxNotDueWeight-minNotDueWeight)*math.Max(0, math.Min(1, elapsed))
+}
+
+// selectTargetedQuestions draws level.Questions questions by weighted sampling
+// without replacement, at most Count per category, then orders them by the
+// level's categories so the interview still flows topic by topic.
+func selectTargetedQuestions(level Level, questionsByCategory map[string][]Question, rng *rand.Rand, p *targetingProfile) []Question {
+	type candidate struct {
+		question Question
+		rank     int     // index of the category in the level
+		key      float64 // smaller is picked first
+	}
+
+	var candidates []candidate
+	for rank, lc := range level.Categories {
+		categoryWeight := p.categoryWeight(lc.Category)
+		for _, q := range questionsByCategory[lc.Category] {
+			weight := categoryWeight * p.questionWeight(q.ID)
+			// Exponential keys give weighted sampling without replacement
+			candidates = append(candidates, candidate{q, rank, rng.ExpFloat64() / weight})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].key < candidates[j].key })
+
+	perCategory := make(map[int]int)
+	selectedTexts := make(map[string]bool)
+	var picked []candidate
+	for _, c := range candidates {
+		if len(picked) == level.Questions {
+			break
+		}
+		if perCategory[c.rank] >= level.Categories[c.rank].Count || selectedTexts[c.question.Text] {
+			continue
+		}
+		perCategory[c.rank]++
+		selectedTexts[c.question.Text] = true
+		picked = append(picked, c)
+	}
+	sort.SliceStable(picked, func(i, j int) bool { return picked[i].rank < picked[j].rank })
+
+	selected := make([]Question, len(picked))
+	for i, c := range picked {
+		selected[i] = c.question
+	}
+	return selected
+}
@@ -0,0 +1,235 @@
+package interview
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Supported visa types
+const (
+	VisaF1   = "F-1"   // academic student
+	VisaJ1   = "J-1"   // exchange visitor
+	VisaB1B2 = "B1/B2" // business or tourist visitor
+	VisaH1B  = "H-1B"  // specialty occupation worker
+	VisaM1   = "M-1"   // vocational student
+)
+
+// DefaultVisaType is used for sessions that do not name a visa, and for bank
+// questions without visa_types
+const DefaultVisaType = VisaF1
+
+// ErrUnknownVisaType is returned when a session asks for a visa that is not supported
+var ErrUnknownVisaType = errors.New("unknown visa type")
+
+// VisaProfile is what the interview and the grading rubric need to know about a visa
+type VisaProfile struct {
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Applicant string `json:"applicant"` // how the prompts refer to the interviewee
+	// Categories are the question categories of the visa, in interview order.
+	// The rubric decides which criteria are graded in each.
+	Categories []string `json:"categories"`
+	// UngradedCriteria are rubric criteria that never apply to the visa and
+	// are always null
+	UngradedCriteria []string `json:"ungraded_criteria,omitempty"`
+	// RedFlags are what an officer listens for with this visa
+	RedFlags []string `json:"-"`
+}
+
+// visaProfiles are the supported visas, in display order
+var visaProfiles = []VisaProfile{
+	{
+		Type:      VisaF1,
+		Name:      "F-1 student visa",
+		Applicant: "student",
+		Categories: []string{
+			"Purpose of Study",
+			"Academic Background",
+			"University Choice",
+			"Financial Capability",
+			"Post-Graduation Plans",
+			"Immigration Intent",
+		},
+		RedFlags: []string{
+			"Vague or rehearsed responses (\"it's a good school\", \"I'll see\", \"maybe\")",
+			"Contradictions between answers",
+			"Lack of specific knowledge about program/university",
+			"Unrealistic financial plans",
+			"Weak ties to home country",
+			"Suspicious patterns (applying to many low-tier schools, can't explain choices)",
+			"Overly rehearsed or robotic delivery",
+			"Inability to answer follow-up questions naturally",
+		},
+	},
+	{
+		Type:      VisaJ1,
+		Name:      "J-1 exchange visitor visa",
+		Applicant: "exchange visitor",
+		Categories: []string{
+			"Exchange Program",
+			"Program Funding",
+			"Home Country Ties",
+			"Return Requirement",
+		},
+		RedFlags: []string{
+			"Cannot name the sponsor or describe the program",
+			"Program unrelated to their field or career",
+			"Unclear who pays the stipend or costs",
+			"Unaware of or planning around the two-year home residency requirement",
+			"Weak ties to home country",
+		},
+	},
+	{
+		Type:      VisaB1B2,
+		Name:      "B1/B2 visitor visa",
+		Applicant: "visitor",
+		Categories: []string{
+			"Purpose of Trip",
+			"Trip Funding",
+			"Travel History",
+			"Home Country Ties",
+		},
+		UngradedCriteria: []string{"academic_credibility"},
+		RedFlags: []string{
+			"Vague itinerary or open-ended length of stay",
+			"Trip cost out of line with income",
+			"Plans that amount to working or studying in the U.S.",
+			"Past overstays or an unexplained travel history",
+			"No job, family or property to return to",
+		},
+	},
+	{
+		Type:      VisaH1B,
+		Name:      "H-1B specialty occupation visa",
+		Applicant: "worker",
+		Categories: []string{
+			"Job Role",
+			"Employer",
+			"Qualifications",
+			"Salary & Terms",
+		},
+		// H-1B allows dual intent, so migration intent is not graded
+		UngradedCriteria: []string{"migration_intent"},
+		RedFlags: []string{
+			"Cannot describe day-to-day duties of the role",
+			"Job duties that do not need a specialty degree",
+			"Knows little about the employer or the client site",
+			"Salary or terms that contradict the petition",
+			"Degree unrelated to the position",
+		},
+	},
+	{
+		Type:      VisaM1,
+		Name:      "M-1 vocational student visa",
+		Applicant: "student",
+		Categories: []string{
+			"Vocational Program",
+			"Program Funding",
+			"Career Plans",
+			"Home Country Ties",
+		},
+		RedFlags: []string{
+			"Cannot explain why the training is not available at home",
+			"Unclear how the full program and living costs are paid",
+			"No job waiting or career path back home",
+			"Plans to change to an academic or work status",
+		},
+	},
+}
+
+// VisaProfiles returns the supported visas in display order
+func VisaProfiles() []VisaProfile {
+	return append([]VisaProfile(nil), visaProfiles...)
+}
+
+// LookupVisaType returns the profile of a visa, or of DefaultVisaType when
+// visaType is empty. Unknown types return ErrUnknownVisaType.
+func LookupVisaType(visaType string) (VisaProfile, error) {
+	if visaType == "" {
+		visaType = DefaultVisaType
+	}
+	for _, v := range visaProfiles {
+		if strings.EqualFold(v.Type, visaType) {
+			return v, nil
+		}
+	}
+	return VisaProfile{}, fmt.Errorf("%w '%s'", ErrUnknownVisaType, visaType)
+}
+
+// visaProfile is LookupVisaType for stored sessions, falling back to the default visa
+func visaProfile(visaType string) VisaProfile {
+	v, err := LookupVisaType(visaType)
+	if err != nil {
+		v, _ = LookupVisaType(DefaultVisaType)
+	}
+	return v
+}
+
+// AppliesTo reports whether the question is asked for the visa. Questions
+// without visa types are F-1 questions from before visas were tracked.
+func (q Question) AppliesTo(visaType string) bool {
+	if len(q.VisaTypes) == 0 {
+		return visaType == DefaultVisaType
+	}
+	for _, v := range q.VisaTypes {
+		if v == visaType {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCategory reports whether the visa asks questions in category
+func (v VisaProfile) hasCategory(category string) bool {
+	return containsString(v.Categories, category)
+}
+
+// gradesCriterion reports whether the criterion applies to the visa
+func (v VisaProfile) gradesCriterion(criterion string) bool {
+	return !containsString(v.UngradedCriteria, criterion)
+}
+
+// criteriaFor returns the rubric criteria graded for the visa in category
+func (v VisaProfile) criteriaFor(category string) []Criterion {
+	var criteria []Criterion
+	for _, c := range RubricCriteria() {
+		if c.AppliesTo(category) && v.gradesCriterion(c.Key) {
+			criteria = append(criteria, c)
+		}
+	}
+	return criteria
+}
+
+// questionsForVisa keeps the questions of each category that apply to the visa
+func questionsForVisa(questionsByCategory map[string][]Question, visaType string) map[string][]Question {
+	filtered := make(map[string][]Question, len(questionsByCategory))
+	for category, questions := range questionsByCategory {
+		for _, q := range questions {
+			if q.AppliesTo(visaType) {
+				filtered[category] = append(filtered[category], q)
+			}
+		}
+	}
+	return filtered
+}
+
+// categoriesFor returns the categories a level asks for a visa. The level's
+// own categories are written for the default visa; other visas ask each of
+// their categories as often as the level's most asked category.
+func (l Level) categoriesFor(v VisaProfile) []LevelCategory {
+	if v.Type == DefaultVisaType {
+		return l.Categories
+	}
+	count := 0
+	for _, lc := range l.Categories {
+		if lc.Count > count {
+			count = lc.Count
+		}
+	}
+	categories := make([]LevelCategory, len(v.Categories))
+	for i, c := range v.Categories {
+		categories[i] = LevelCategory{Category: c, Count: count}
+	}
+	return categories
+}
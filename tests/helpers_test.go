@@ -0,0 +1,15 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+// decodeJSON unmarshals a recorded response body into v
+func decodeJSON(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("Failed to decode response %q: %v", w.Body.String(), err)
+	}
+}
@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"altoai_mvp/internal/handlers"
+	"altoai_mvp/internal/repository"
+	"altoai_mvp/internal/services"
+	"altoai_mvp/interview"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sseEvents returns the event names of an SSE body in order
+func sseEvents(body string) []string {
+	var events []string
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		if name, ok := strings.CutPrefix(scanner.Text(), "event:"); ok {
+			events = append(events, strings.TrimSpace(name))
+		}
+	}
+	return events
+}
+
+func TestChatStreamEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+	previous := interview.GetAnalyzer()
+	defer interview.SetAnalyzer(previous)
+	// A strong answer, so no follow-up replaces the streamed question
+	fake := interview.NewFakeLLMClient(sampleAnalysisJSON)
+	interview.SetAnalyzer(interview.NewVisaAnalyzerWithClient(interview.NewResilientClient(fake, fastResilience())))
+
+	session := interview.NewSessionWithLevel("alice", "easy")
+	interview.SaveSession(session)
+
+	chatH := handlers.NewChatHandler(services.NewUserService(repository.NewUserMemoryRepo()))
+	r := gin.New()
+	r.POST("/chat/stream", withUser("alice"), chatH.ChatStream)
+
+	body := `{"session_id":"` + session.ID + `","messages":[{"role":"user","content":"To study robotics."}]}`
+	req := httptest.NewRequest(http.MethodPost, "/chat/stream", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Fatalf("Expected an event stream, got %q", ct)
+	}
+	events := sseEvents(w.Body.String())
+	if len(events) < 4 || events[0] != "question" || events[1] != "token" {
+		t.Fatalf("Expected question, then tokens, got %v", events)
+	}
+	if events[len(events)-2] != "analysis" || events[len(events)-1] != "done" {
+		t.Errorf("Expected analysis then done at the end, got %v", events)
+	}
+	if !strings.Contains(w.Body.String(), session.SelectedQuestions[1].ID) {
+		t.Error("Question event should carry the next question")
+	}
+
+	saved, _ := interview.GetSession(session.ID)
+	if len(saved.Answers) != 1 || saved.Answers[0].Analysis == nil {
+		t.Errorf("Streamed answer should be saved with its analysis, got %+v", saved.Answers)
+	}
+}
+
+func TestChatStreamOutlastsWriteTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+	previous := interview.GetAnalyzer()
+	defer interview.SetAnalyzer(previous)
+	// The model takes longer than the server allows for a whole response
+	fake := interview.NewFakeLLMClient()
+	fake.Enqueue(interview.FakeResponse{Content: sampleAnalysisJSON, Delay: 300 * time.Millisecond})
+	interview.SetAnalyzer(interview.NewVisaAnalyzerWithClient(interview.NewResilientClient(fake, fastResilience())))
+
+	session := interview.NewSessionWithLevel("alice", "easy")
+	interview.SaveSession(session)
+
+	chatH := handlers.NewChatHandler(services.NewUserService(repository.NewUserMemoryRepo()))
+	r := gin.New()
+	r.POST("/chat/stream", withUser("alice"), chatH.ChatStream)
+	srv := httptest.NewUnstartedServer(r)
+	srv.Config.WriteTimeout = 100 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	body := `{"session_id":"` + session.ID + `","messages":[{"role":"user","content":"To study robotics."}]}`
+	resp, err := http.Post(srv.URL+"/chat/stream", "application/json", bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var out strings.Builder
+	bufio.NewReader(resp.Body).WriteTo(&out)
+
+	events := sseEvents(out.String())
+	if len(events) == 0 || events[len(events)-1] != "done" {
+		t.Errorf("Expected the stream to reach done past the write timeout, got %v", events)
+	}
+}
+
+func TestChatStreamWithoutGrading(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+
+	chatH := handlers.NewChatHandler(services.NewUserService(repository.NewUserMemoryRepo()))
+	r := gin.New()
+	r.POST("/chat/stream", withUser("alice"), chatH.ChatStream)
+
+	req := httptest.NewRequest(http.MethodPost, "/chat/stream", bytes.NewBufferString(`{"level":"easy"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if events := sseEvents(w.Body.String()); len(events) != 1 || events[0] != "done" {
+		t.Errorf("A new session should get a single done event, got %v", events)
+	}
+}
+
+func TestOpenAIClientStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n"))
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"Hel\"}}]}\n\n"))
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"lo\"}}]}\n\n"))
+		w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer srv.Close()
+
+	var deltas []string
+	c := interview.NewOpenAIClient("sk-test", srv.URL, "", nil)
+	resp, err := c.Stream(context.Background(), interview.LLMRequest{}, func(d string) { deltas = append(deltas, d) })
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	if resp.Content != "Hello" || len(deltas) != 2 {
+		t.Errorf("Expected 'Hello' in 2 deltas, got %q from %v", resp.Content, deltas)
+	}
+}
@@ -0,0 +1,216 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"altoai_mvp/internal/handlers"
+	"altoai_mvp/internal/repository"
+	"altoai_mvp/internal/services"
+	"altoai_mvp/interview"
+
+	"github.com/gin-gonic/gin"
+)
+
+// consistencySession has a sponsor that changes between the first and third answer
+func consistencySession() *interview.Session {
+	session := interview.NewSession("alice")
+	score := 4
+	analysis := &interview.AnalysisResponse{
+		Scores:         interview.AnalysisScores{Criteria: map[string]*int{"communication_quality": &score}, TotalScore: 4},
+		Classification: "Good",
+	}
+	session.Answers = []interview.Answer{
+		{QuestionID: "q1", QuestionText: "Who is sponsoring you?", Text: "My father is paying for everything. He owns a pharmacy.", CreatedAt: time.Now(), Analysis: analysis},
+		{QuestionID: "q2", QuestionText: "Which university?", Text: "I was admitted to Ohio State for computer science.", CreatedAt: time.Now(), Analysis: analysis},
+		{QuestionID: "q3", QuestionText: "How will you cover living costs?", Text: "My uncle sponsors my whole education, about $40,000 a year.", CreatedAt: time.Now(), Analysis: analysis},
+	}
+	return session
+}
+
+func TestCheckConsistency(t *testing.T) {
+	fake := interview.NewFakeLLMClient(`{
+		"facts": [
+			{"fact": "sponsor", "value": "father", "answer": 1, "quote": "My father is paying for everything."},
+			{"fact": "university", "value": "Ohio State", "answer": 2, "quote": "I was admitted to Ohio State for computer science."},
+			{"fact": "sponsor", "value": "uncle", "answer": 3, "quote": "My uncle sponsors my whole education, about $40,000 a year."},
+			{"fact": "gpa", "value": "3.9", "answer": 2, "quote": "My GPA is 3.9."},
+			{"fact": "hobby", "value": "chess", "answer": 2, "quote": "I was admitted to Ohio State"}
+		],
+		"contradictions": [
+			{"fact": "sponsor", "explanation": "The sponsor changes from the father to an uncle.", "claims": [
+				{"fact": "sponsor", "value": "father", "answer": 1, "quote": "my father is paying for everything"},
+				{"fact": "sponsor", "value": "uncle", "answer": 3, "quote": "My uncle sponsors my whole education"}
+			]},
+			{"fact": "university", "explanation": "Made up.", "claims": [
+				{"fact": "university", "value": "Ohio State", "answer": 2, "quote": "Ohio State"},
+				{"fact": "university", "value": "Penn State", "answer": 7, "quote": "Penn State"}
+			]}
+		]
+	}`)
+	va := interview.NewVisaAnalyzerWithClient(fake)
+
+	report, err := va.CheckConsistency(t.Context(), consistencySession())
+	if err != nil {
+		t.Fatalf("CheckConsistency failed: %v", err)
+	}
+
+	// The GPA quote is not in the answer and hobby is not a known fact
+	if len(report.Facts) != 3 {
+		t.Fatalf("Expected 3 verified facts, got %+v", report.Facts)
+	}
+	if report.Facts[2].QuestionID != "q3" || report.Facts[2].AnswerIndex != 2 {
+		t.Errorf("Expected the third fact to cite q3, got %+v", report.Facts[2])
+	}
+
+	// The second contradiction cites an answer that does not exist
+	if len(report.Contradictions) != 1 {
+		t.Fatalf("Expected 1 contradiction, got %+v", report.Contradictions)
+	}
+	c := report.Contradictions[0]
+	if c.Fact != "sponsor" || len(c.Claims) != 2 {
+		t.Fatalf("Expected a sponsor contradiction with 2 claims, got %+v", c)
+	}
+	if c.Claims[0].QuestionID != "q1" || c.Claims[1].QuestionID != "q3" {
+		t.Errorf("Expected claims from q1 and q3, got %+v", c.Claims)
+	}
+
+	prompt := fake.Requests()[0].Messages[1].Content
+	if !strings.Contains(prompt, "Answer 3\nQuestion: How will you cover living costs?") {
+		t.Errorf("Expected numbered answers in the prompt, got %q", prompt)
+	}
+}
+
+func TestSessionSummaryIncludesContradictions(t *testing.T) {
+	fake := interview.NewFakeLLMClient(`{
+		"facts": [{"fact": "sponsor", "value": "father", "answer": 1, "quote": "My father is paying for everything."}],
+		"contradictions": [{"fact": "sponsor", "explanation": "Father, then uncle.", "claims": [
+			{"fact": "sponsor", "value": "father", "answer": 1, "quote": "My father is paying for everything."},
+			{"fact": "sponsor", "value": "uncle", "answer": 3, "quote": "My uncle sponsors my whole education"}
+		]}]
+	}`)
+	previous := interview.GetAnalyzer()
+	defer interview.SetAnalyzer(previous)
+	interview.SetAnalyzer(interview.NewVisaAnalyzerWithClient(fake))
+
+	// The summary is made without the check, which runs outside the session lock
+	session := consistencySession()
+	session.Status = interview.SessionStatusFinished
+	summary, err := interview.GenerateSessionSummary(session)
+	if err != nil {
+		t.Fatalf("GenerateSessionSummary failed: %v", err)
+	}
+	if summary.FactSheet != nil || len(fake.Requests()) != 0 {
+		t.Fatalf("Expected no consistency check while summarizing, got %+v", summary.FactSheet)
+	}
+	session.Summary = summary
+	interview.SaveSession(session)
+
+	interview.RefreshConsistency(t.Context(), session.ID)
+	saved, _ := interview.GetSession(session.ID)
+	summary = saved.Summary
+	if len(summary.FactSheet) != 1 || len(summary.Contradictions) != 1 {
+		t.Fatalf("Expected the consistency report on the summary, got %+v / %+v", summary.FactSheet, summary.Contradictions)
+	}
+	if summary.Contradictions[0].Claims[1].Quote != "My uncle sponsors my whole education" {
+		t.Errorf("Expected the conflicting sentence to be quoted, got %+v", summary.Contradictions[0].Claims[1])
+	}
+
+	// Unchanged answers are not checked again, and a new summary keeps the report
+	interview.RefreshConsistency(t.Context(), session.ID)
+	if len(fake.Requests()) != 1 {
+		t.Errorf("Expected one consistency check, got %d", len(fake.Requests()))
+	}
+	if summary, _ = interview.GenerateSessionSummary(saved); len(summary.Contradictions) != 1 {
+		t.Errorf("Expected the report to be kept, got %+v", summary)
+	}
+
+	// A changed answer drops the report until the next check, and a failed check leaves none
+	saved.Answers[2].Text = "My father sponsors my whole education."
+	if summary, _ = interview.GenerateSessionSummary(saved); summary.FactSheet != nil || summary.Contradictions != nil {
+		t.Errorf("Expected the stale report to be dropped, got %+v", summary)
+	}
+	saved.Summary = summary
+	interview.SaveSession(saved)
+	fake.Enqueue(interview.FakeResponse{Err: interview.ErrLLMNotConfigured})
+	interview.RefreshConsistency(t.Context(), session.ID)
+	if saved, _ = interview.GetSession(session.ID); saved.Summary.FactSheet != nil {
+		t.Errorf("Expected no consistency report, got %+v", saved.Summary)
+	}
+	interview.DeleteSession(session.ID)
+}
+
+func TestChatReplyDoesNotWaitForConsistencyCheck(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+	// Grading is quick, the consistency check is slow
+	fake := interview.NewFakeLLMClient(sampleAnalysisJSON)
+	fake.Enqueue(interview.FakeResponse{
+		Content: `{"facts": [], "contradictions": []}`,
+		Delay:   time.Second,
+	})
+	previous := interview.GetAnalyzer()
+	defer interview.SetAnalyzer(previous)
+	interview.SetAnalyzer(interview.NewVisaAnalyzerWithClient(fake))
+
+	// Every question but the last one is answered
+	session := interview.NewSessionWithLevel("alice", "easy")
+	last := len(session.SelectedQuestions) - 1
+	for _, q := range session.SelectedQuestions[:last] {
+		session.Answers = append(session.Answers, interview.Answer{
+			QuestionID:    q.ID,
+			QuestionText:  q.Text,
+			Text:          "My father is paying for everything.",
+			CreatedAt:     time.Now(),
+			GradingStatus: interview.GradingStatusGraded,
+		})
+	}
+	session.QuestionIndex = last
+	session.CurrentQuestion = session.SelectedQuestions[last].ID
+	interview.SaveSession(session)
+	defer interview.DeleteSession(session.ID)
+
+	chatH := handlers.NewChatHandler(services.NewUserService(repository.NewUserMemoryRepo()))
+	r := gin.New()
+	r.POST("/chat", withUser("alice"), chatH.Chat)
+
+	body := `{"session_id":"` + session.ID + `","messages":[{"role":"user","content":"I will come back to run the pharmacy."}]}`
+	req := httptest.NewRequest(http.MethodPost, "/chat", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	start := time.Now()
+	r.ServeHTTP(w, req)
+
+	if elapsed := time.Since(start); elapsed >= 500*time.Millisecond {
+		t.Errorf("Expected the reply before the consistency check ends, took %v", elapsed)
+	}
+	var chatResp struct {
+		Data handlers.ChatResponse `json:"data"`
+	}
+	decodeJSON(t, w, &chatResp)
+	if !chatResp.Data.Finished {
+		t.Fatalf("Expected the last answer to finish the session, got %+v", chatResp.Data)
+	}
+
+	// The check still lands on the summary once the model replies
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		unlock := interview.LockSession(session.ID)
+		saved, _ := interview.GetSession(session.ID)
+		checked := saved.Summary != nil && saved.Summary.ConsistencyKey != ""
+		unlock()
+		if checked {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the consistency check to run in the background")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
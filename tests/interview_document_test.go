@@ -0,0 +1,246 @@
+package tests
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"altoai_mvp/internal/handlers"
+	"altoai_mvp/internal/repository"
+	"altoai_mvp/internal/services"
+	"altoai_mvp/interview"
+
+	"github.com/gin-gonic/gin"
+)
+
+// textPDF builds a minimal PDF showing one line of text per entry, in a
+// Flate-compressed content stream as PDF writers produce them
+func textPDF(t *testing.T, lines ...string) []byte {
+	t.Helper()
+	var content strings.Builder
+	content.WriteString("BT /F1 12 Tf 72 720 Td\n")
+	for i, line := range lines {
+		if i > 0 {
+			content.WriteString("0 -14 Td\n")
+		}
+		escaped := strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(line)
+		fmt.Fprintf(&content, "(%s) Tj\n", escaped)
+	}
+	content.WriteString("ET\n")
+
+	var stream bytes.Buffer
+	zw := zlib.NewWriter(&stream)
+	if _, err := zw.Write([]byte(content.String())); err != nil {
+		t.Fatal(err)
+	}
+	zw.Close()
+
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj\n")
+	fmt.Fprintf(&pdf, "4 0 obj << /Length %d /Filter /FlateDecode >>\nstream\n", stream.Len())
+	pdf.Write(stream.Bytes())
+	pdf.WriteString("\nendstream\nendobj\n%%EOF\n")
+	return pdf.Bytes()
+}
+
+func TestTextPDFExtractor(t *testing.T) {
+	i20 := textPDF(t,
+		"CERTIFICATE OF ELIGIBILITY FOR NONIMMIGRANT STUDENT STATUS",
+		"School Name: The Ohio State University",
+		"Major 1: Computer Science, M.S.",
+		"Program Start Date: 08/20/2025",
+		"Program End Date: 05/15/2027",
+		"Estimated Average Costs for: 12 months",
+		"Total Estimated Costs: $ 52,340",
+	)
+	doc, err := interview.NewDocument(interview.DocumentI20, "i20.pdf", i20)
+	if err != nil {
+		t.Fatalf("NewDocument failed: %v", err)
+	}
+	fields, err := interview.NewTextPDFExtractor().Extract(t.Context(), doc)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	want := interview.DocumentFields{
+		School:        "The Ohio State University",
+		Program:       "Computer Science, M.S.",
+		ProgramStart:  "2025-08-20",
+		ProgramEnd:    "2027-05-15",
+		EstimatedCost: 52340,
+	}
+	if *fields != want {
+		t.Errorf("Expected %+v, got %+v", want, *fields)
+	}
+
+	statement := textPDF(t, "Account summary", "Closing balance: 48,250.17 USD")
+	doc, _ = interview.NewDocument(interview.DocumentBankStatement, "bank.pdf", statement)
+	fields, err = interview.NewTextPDFExtractor().Extract(t.Context(), doc)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if fields.AvailableFunds != 48250 {
+		t.Errorf("Expected available funds of 48250, got %d", fields.AvailableFunds)
+	}
+
+	letter := textPDF(t, "Dear Aibek,", "We are pleased to inform you that you have been admitted to the Master of Science in Data Science program at Northeastern University.")
+	doc, _ = interview.NewDocument(interview.DocumentAdmissionLetter, "letter.pdf", letter)
+	fields, err = interview.NewTextPDFExtractor().Extract(t.Context(), doc)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if fields.School != "Northeastern University" || fields.Program != "Master of Science in Data Science" {
+		t.Errorf("Expected the school and program from the letter, got %+v", fields)
+	}
+
+	// Images need an OCR extractor
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 32)...)
+	doc, err = interview.NewDocument(interview.DocumentI20, "i20.png", png)
+	if err != nil {
+		t.Fatalf("NewDocument failed: %v", err)
+	}
+	if _, err := interview.NewTextPDFExtractor().Extract(t.Context(), doc); !errors.Is(err, interview.ErrUnsupportedDocument) {
+		t.Errorf("Expected ErrUnsupportedDocument for an image, got %v", err)
+	}
+	if _, err := interview.NewDocument(interview.DocumentI20, "notes.txt", []byte("School Name: Ohio State")); !errors.Is(err, interview.ErrUnsupportedDocument) {
+		t.Errorf("Expected ErrUnsupportedDocument for a text file, got %v", err)
+	}
+}
+
+func TestTextPDFExtractorInflateBudget(t *testing.T) {
+	// Each stream inflates 4 MB of spaces from a few KB; the PDF as a whole
+	// may inflate 16 MB, so the text after four of them is never read
+	var blank bytes.Buffer
+	zw := zlib.NewWriter(&blank)
+	zw.Write(bytes.Repeat([]byte(" "), 4<<20))
+	zw.Close()
+	withBlanks := func(n int) []byte {
+		var pdf bytes.Buffer
+		pdf.WriteString("%PDF-1.4\n")
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(&pdf, "%d 0 obj << /Length %d /Filter /FlateDecode >>\nstream\n", i+10, blank.Len())
+			pdf.Write(blank.Bytes())
+			pdf.WriteString("\nendstream\nendobj\n")
+		}
+		pdf.Write(textPDF(t, "School Name: Ohio State")[len("%PDF-1.4\n"):])
+		return pdf.Bytes()
+	}
+
+	doc, _ := interview.NewDocument(interview.DocumentI20, "i20.pdf", withBlanks(3))
+	if fields, err := interview.NewTextPDFExtractor().Extract(t.Context(), doc); err != nil || fields.School != "Ohio State" {
+		t.Errorf("Expected the school within the budget, got %+v, %v", fields, err)
+	}
+	doc, _ = interview.NewDocument(interview.DocumentI20, "i20.pdf", withBlanks(4))
+	if _, err := interview.NewTextPDFExtractor().Extract(t.Context(), doc); !errors.Is(err, interview.ErrUnsupportedDocument) {
+		t.Errorf("Expected the text past the budget to be skipped, got %v", err)
+	}
+}
+
+func TestImportDocumentPrefillsProfile(t *testing.T) {
+	useProfileStore(t)
+	previous := interview.GetDocumentExtractor()
+	defer interview.SetDocumentExtractor(previous)
+
+	if err := interview.SaveProfile(&interview.ApplicantProfile{UserID: "alice", University: "Ohio State University", EstimatedCost: 50000}); err != nil {
+		t.Fatalf("SaveProfile failed: %v", err)
+	}
+	fake := interview.NewFakeDocumentExtractor(interview.DocumentFields{
+		School:         "ohio state university",
+		Program:        "MS Computer Science",
+		ProgramStart:   "2025-08-20",
+		EstimatedCost:  52340,
+		AvailableFunds: 9000, // not shown on an I-20
+	})
+	interview.SetDocumentExtractor(fake)
+
+	doc, _ := interview.NewDocument(interview.DocumentI20, "i20.pdf", textPDF(t, "I-20"))
+	result, err := interview.ImportDocument(t.Context(), "alice", doc)
+	if err != nil {
+		t.Fatalf("ImportDocument failed: %v", err)
+	}
+	if strings.Join(result.Filled, ",") != "program,program_start" {
+		t.Errorf("Expected program and program_start to be filled, got %v", result.Filled)
+	}
+	if len(result.Mismatches) != 1 || result.Mismatches[0].Field != "estimated_cost" || result.Mismatches[0].Document != "$52,340" {
+		t.Errorf("Expected an estimated_cost mismatch, got %+v", result.Mismatches)
+	}
+
+	saved, _ := interview.GetProfile("alice")
+	if saved.Program != "MS Computer Science" || saved.EstimatedCost != 50000 || saved.AvailableFunds != 0 {
+		t.Errorf("Expected only empty fields to be filled, got %+v", saved)
+	}
+
+	// A bank statement only fills the funds
+	doc.Kind = interview.DocumentBankStatement
+	if result, err = interview.ImportDocument(t.Context(), "alice", doc); err != nil {
+		t.Fatalf("ImportDocument failed: %v", err)
+	}
+	if strings.Join(result.Filled, ",") != "available_funds" || result.Profile.AvailableFunds != 9000 {
+		t.Errorf("Expected available funds to be filled, got %+v", result)
+	}
+}
+
+func TestUploadDocumentEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	useProfileStore(t)
+	previous := interview.GetDocumentExtractor()
+	defer interview.SetDocumentExtractor(previous)
+	interview.SetDocumentExtractor(interview.NewFakeDocumentExtractor(interview.DocumentFields{School: "Ohio State University"}))
+
+	profileH := handlers.NewProfileHandler(services.NewUserService(repository.NewUserMemoryRepo()))
+	r := gin.New()
+	r.POST("/documents", withUser("alice"), profileH.UploadDocument)
+
+	upload := func(kind, filename string, data []byte) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		mw.WriteField("kind", kind)
+		part, _ := mw.CreateFormFile("file", filename)
+		part.Write(data)
+		mw.Close()
+		req := httptest.NewRequest(http.MethodPost, "/documents", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := upload("passport", "i20.pdf", textPDF(t, "I-20")); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an unknown kind, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+	}
+	if w := upload("i20", "i20.txt", []byte("School Name: Ohio State")); w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected status %d for a text file, got %d: %s", http.StatusUnsupportedMediaType, w.Code, w.Body.String())
+	}
+
+	w := upload("i20", "i20.pdf", textPDF(t, "I-20"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data interview.DocumentResult `json:"data"`
+	}
+	decodeJSON(t, w, &resp)
+	if resp.Data.Profile == nil || resp.Data.Profile.University != "Ohio State University" {
+		t.Errorf("Expected the pre-filled profile, got %+v", resp.Data)
+	}
+
+	// Nothing on a bank statement that the fake reads
+	if w := upload("bank_statement", "bank.pdf", textPDF(t, "Statement")); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d, got %d: %s", http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	}
+
+	// Images are refused up front while only the local PDF extractor is set
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 32)...)
+	if w := upload("i20", "i20.png", png); w.Code != http.StatusOK {
+		t.Errorf("Expected an extractor without ReadsContentType to get the image, got %d: %s", w.Code, w.Body.String())
+	}
+	interview.SetDocumentExtractor(interview.NewTextPDFExtractor())
+	if w := upload("i20", "i20.png", png); w.Code != http.StatusUnsupportedMediaType || !strings.Contains(w.Body.String(), "OCR") {
+		t.Errorf("Expected status %d for an image, got %d: %s", http.StatusUnsupportedMediaType, w.Code, w.Body.String())
+	}
+}
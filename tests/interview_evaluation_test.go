@@ -1,14 +1,14 @@
 package tests
 
 import (
+	"altoai_mvp/interview"
 	"testing"
 	"time"
-	"altoai_mvp/interview"
 )
 
 func TestGenerateSessionSummary(t *testing.T) {
 	session := interview.NewSession("test-user")
-	
+
 	// Add some answers with analyses (7-criteria, 7-35 total score)
 	mi1, fu1, ac1, sr1, co1, cq1, rf1 := 5, 4, 4, 4, 5, 5, 5
 	mi2, fu2, ac2, sr2, co2, cq2, rf2 := 4, 4, 4, 4, 4, 4, 5
@@ -20,14 +20,16 @@ func TestGenerateSessionSummary(t *testing.T) {
 			CreatedAt:    time.Now(),
 			Analysis: &interview.AnalysisResponse{
 				Scores: interview.AnalysisScores{
-					MigrationIntent:        &mi1,
-					FinancialUnderstanding: &fu1,
-					AcademicCredibility:    &ac1,
-					SpecificityResearch:    &sr1,
-					Consistency:            &co1,
-					CommunicationQuality:   &cq1,
-					RedFlags:              &rf1,
-					TotalScore:             32,
+					Criteria: map[string]*int{
+						"migration_intent":        &mi1,
+						"financial_understanding": &fu1,
+						"academic_credibility":    &ac1,
+						"specificity_research":    &sr1,
+						"consistency":             &co1,
+						"communication_quality":   &cq1,
+						"red_flags":               &rf1,
+					},
+					TotalScore: 32,
 				},
 				Classification: "Excellent",
 			},
@@ -39,14 +41,16 @@ func TestGenerateSessionSummary(t *testing.T) {
 			CreatedAt:    time.Now(),
 			Analysis: &interview.AnalysisResponse{
 				Scores: interview.AnalysisScores{
-					MigrationIntent:        &mi2,
-					FinancialUnderstanding: &fu2,
-					AcademicCredibility:    &ac2,
-					SpecificityResearch:    &sr2,
-					Consistency:            &co2,
-					CommunicationQuality:   &cq2,
-					RedFlags:              &rf2,
-					TotalScore:             29,
+					Criteria: map[string]*int{
+						"migration_intent":        &mi2,
+						"financial_understanding": &fu2,
+						"academic_credibility":    &ac2,
+						"specificity_research":    &sr2,
+						"consistency":             &co2,
+						"communication_quality":   &cq2,
+						"red_flags":               &rf2,
+					},
+					TotalScore: 29,
 				},
 				Classification: "Good",
 			},
@@ -65,20 +69,20 @@ func TestGenerateSessionSummary(t *testing.T) {
 	if summary.AverageScore != 30.5 {
 		t.Errorf("Expected average score 30.5, got %.2f", summary.AverageScore)
 	}
-	// With 7 criteria, 30.5 out of 35 max = ~87% => A (Excellent) using dynamic thresholds
-	// Actually, let's check: 30.5 with 7 criteria (max 35) = 87% which is >= 85% => A
+	// Weighted by the default rubric the answers score 90.3% and 79.2%, averaging 84.7% => A
+	if summary.ScorePercent < 84 || summary.ScorePercent > 85 {
+		t.Errorf("Expected a weighted score of about 84.7%%, got %.2f", summary.ScorePercent)
+	}
 	if summary.OverallGrade != "A" {
-		t.Errorf("Expected grade A (30.5/35 = 87%%), got %s", summary.OverallGrade)
+		t.Errorf("Expected grade A (84.7%%), got %s", summary.OverallGrade)
 	}
 }
 
 func TestGenerateSessionSummaryEmptySession(t *testing.T) {
 	session := interview.NewSession("test-user")
-	
+
 	_, err := interview.GenerateSessionSummary(session)
 	if err == nil {
 		t.Error("Should return error for empty session")
 	}
 }
-
-
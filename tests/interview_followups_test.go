@@ -0,0 +1,229 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"altoai_mvp/internal/handlers"
+	"altoai_mvp/internal/repository"
+	"altoai_mvp/internal/services"
+	"altoai_mvp/interview"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestFollowupsLoadedWithQuestions(t *testing.T) {
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+	for followupType, questions := range interview.FollowupsByType() {
+		for _, q := range questions {
+			if q.ID == "" || q.Text == "" || !q.IsFollowup {
+				t.Errorf("Follow-ups of type %s should be defined, got %+v", followupType, q)
+			}
+		}
+	}
+	if _, ok := interview.LookupFollowup("q5f_finance_clarify"); !ok {
+		t.Error("Expected q5f_finance_clarify in the follow-up bank")
+	}
+}
+
+func TestInsertFollowup(t *testing.T) {
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+	weak := &interview.EvalResult{NeedsFollowup: true}
+	session := &interview.Session{
+		SelectedQuestions: []interview.Question{
+			{ID: "q1", Category: "Financial Capability", Text: "Who sponsors you?"},
+			{ID: "q2", Category: "Purpose of Study", Text: "Why the US?"},
+			{ID: "q3", Category: "Immigration Intent", Text: "Will you come back?"},
+		},
+	}
+
+	if f := interview.InsertFollowup(session, session.SelectedQuestions[0], &interview.EvalResult{}); f != nil {
+		t.Error("A good answer should not get a follow-up")
+	}
+
+	f := interview.InsertFollowup(session, session.SelectedQuestions[0], weak)
+	if f == nil || f.ID != "q5f_finance_clarify" || !f.IsFollowup || f.ParentID != "q1" {
+		t.Fatalf("Expected the finance follow-up after q1, got %+v", f)
+	}
+	if session.SelectedQuestions[1].ID != f.ID || len(session.SelectedQuestions) != 4 {
+		t.Errorf("Follow-up should be inserted right after the current question, got %+v", session.SelectedQuestions)
+	}
+
+	// A follow-up is never followed up
+	session.QuestionIndex = 1
+	if f := interview.InsertFollowup(session, session.SelectedQuestions[1], weak); f != nil {
+		t.Errorf("Follow-ups should not chain, got %+v", f)
+	}
+
+	session.QuestionIndex = 2
+	if f := interview.InsertFollowup(session, session.SelectedQuestions[2], weak); f == nil || f.ID != "q1f_clarify_purpose" {
+		t.Fatalf("Expected the purpose follow-up, got %+v", f)
+	}
+
+	// The cap is reached
+	session.QuestionIndex = 4
+	if f := interview.InsertFollowup(session, session.SelectedQuestions[4], weak); f != nil {
+		t.Errorf("Expected no more than %d follow-ups, got %+v", interview.MaxFollowupsPerSession, f)
+	}
+}
+
+func TestInsertFollowupForVisa(t *testing.T) {
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+	weak := &interview.EvalResult{NeedsFollowup: true, SuggestedFollowup: "clarify_financial"}
+	session := &interview.Session{
+		VisaType: interview.VisaJ1,
+		SelectedQuestions: []interview.Question{
+			{ID: "q1", Category: "Exchange Program", Text: "What is your program?"},
+			{ID: "q2", Category: "Financial Capability", Text: "Who sponsors you?"},
+		},
+	}
+
+	if f := interview.InsertFollowup(session, session.SelectedQuestions[0], weak); f == nil || f.ID != "j1f_program_detail" {
+		t.Fatalf("Expected the J-1 program follow-up, got %+v", f)
+	}
+
+	// The suggested type has F-1 follow-ups first; only the J-1 one may be asked
+	session.QuestionIndex = 2
+	if f := interview.InsertFollowup(session, session.SelectedQuestions[2], weak); f == nil || f.ID != "j1f_funding_detail" {
+		t.Errorf("Expected the J-1 funding follow-up, got %+v", f)
+	}
+}
+
+func TestChatAsksFollowupAfterWeakAnswer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+	// The default fake analysis scores 6/10, which is weak
+	previous := interview.GetAnalyzer()
+	defer interview.SetAnalyzer(previous)
+	interview.SetAnalyzer(interview.NewVisaAnalyzerWithClient(interview.NewFakeLLMClient()))
+
+	session := interview.NewSessionWithLevel("alice", "easy")
+	first := session.SelectedQuestions[0]
+	interview.SaveSession(session)
+
+	chatH := handlers.NewChatHandler(services.NewUserService(repository.NewUserMemoryRepo()))
+	r := gin.New()
+	r.POST("/chat", withUser("alice"), chatH.Chat)
+
+	body := `{"session_id":"` + session.ID + `","messages":[{"role":"user","content":"Because."}]}`
+	req := httptest.NewRequest(http.MethodPost, "/chat", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp struct {
+		Data handlers.ChatResponse `json:"data"`
+	}
+	decodeJSON(t, w, &resp)
+	if !resp.Data.IsFollowup {
+		t.Fatalf("Expected a follow-up question, got %+v", resp.Data)
+	}
+	followup, ok := interview.LookupFollowup(resp.Data.QuestionID)
+	if !ok || followup.Category != first.Category {
+		t.Errorf("Follow-up should dig into %q, got %q", first.Category, resp.Data.QuestionID)
+	}
+
+	saved, _ := interview.GetSession(session.ID)
+	if len(saved.SelectedQuestions) != 5 || saved.SelectedQuestions[1].ParentID != first.ID {
+		t.Errorf("Follow-up should be stored after the first question, got %+v", saved.SelectedQuestions)
+	}
+}
+
+// weakAnalysisJSON is a valid analysis weak enough to trigger a follow-up
+const weakAnalysisJSON = `{
+  "scores": {"migration_intent": null, "financial_understanding": 2, "academic_credibility": null,
+    "specificity_research": null, "consistency": null, "communication_quality": 2, "red_flags": 2, "total_score": 6},
+  "classification": "Weak",
+  "feedback": {"overall": "Vague about the sponsor.", "by_criterion": {}, "improvements": ["Name your sponsor's job."]}
+}`
+
+func TestGenerateFollowup(t *testing.T) {
+	current := interview.Question{ID: "q5", Category: "Financial Capability", Text: "Who sponsors you?"}
+
+	fake := interview.NewFakeLLMClient(`{"question": "You said your uncle is sponsoring you — what does he do?", "category": "Financial Capability"}`)
+	va := interview.NewVisaAnalyzerWithClient(fake)
+	f, err := va.GenerateFollowup(context.Background(), current, "My uncle is sponsoring me.")
+	if err != nil {
+		t.Fatalf("GenerateFollowup failed: %v", err)
+	}
+	if f.ID != "q5_gen" || !f.IsFollowup || !f.Generated || f.ParentID != "q5" || f.Category != current.Category {
+		t.Errorf("Unexpected generated follow-up %+v", f)
+	}
+	if req := fake.Requests()[0]; !strings.Contains(req.Messages[1].Content, "My uncle is sponsoring me.") {
+		t.Error("The prompt should include the student's answer")
+	}
+
+	invalid := []string{
+		`{"question": "` + strings.Repeat("a", interview.MaxGeneratedFollowupLength) + `?", "category": "Financial Capability"}`,
+		`{"question": "What is your favourite food?", "category": "Cooking"}`,
+		`{"question": "Tell me more about your uncle.", "category": "Financial Capability"}`,
+		`not json`,
+	}
+	for _, content := range invalid {
+		va := interview.NewVisaAnalyzerWithClient(interview.NewFakeLLMClient(content))
+		_, err := va.GenerateFollowup(context.Background(), current, "My uncle.")
+		var validationErr *interview.FollowupValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("Expected a FollowupValidationError for %.40q, got %v", content, err)
+		}
+	}
+}
+
+func TestChatAsksGeneratedFollowup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+	session := interview.NewSessionWithLevel("alice", "easy")
+	first := session.SelectedQuestions[0]
+	interview.SaveSession(session)
+
+	generated := `{"question": "You mentioned robotics — which lab would you join?", "category": "` + first.Category + `"}`
+	previous := interview.GetAnalyzer()
+	defer interview.SetAnalyzer(previous)
+	interview.SetAnalyzer(interview.NewVisaAnalyzerWithClient(interview.NewFakeLLMClient(weakAnalysisJSON, generated, sampleAnalysisJSON)))
+
+	chatH := handlers.NewChatHandler(services.NewUserService(repository.NewUserMemoryRepo()))
+	r := gin.New()
+	r.POST("/chat", withUser("alice"), chatH.Chat)
+	post := func(answer string) handlers.ChatResponse {
+		body := `{"session_id":"` + session.ID + `","messages":[{"role":"user","content":"` + answer + `"}]}`
+		req := httptest.NewRequest(http.MethodPost, "/chat", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var resp struct {
+			Data handlers.ChatResponse `json:"data"`
+		}
+		decodeJSON(t, w, &resp)
+		return resp.Data
+	}
+
+	resp := post("Robotics, I guess.")
+	if !resp.IsFollowup || resp.QuestionID != first.ID+"_gen" || !strings.Contains(resp.Content, "robotics") {
+		t.Fatalf("Expected the generated follow-up, got %+v", resp)
+	}
+
+	// The follow-up is answered and graded like any other question
+	resp = post("The autonomous systems lab.")
+	saved, _ := interview.GetSession(session.ID)
+	if len(saved.Answers) != 2 || saved.Answers[1].QuestionID != first.ID+"_gen" || saved.Answers[1].Analysis == nil {
+		t.Errorf("The generated follow-up should be answered and graded, got %+v", saved.Answers)
+	}
+	if resp.IsFollowup || resp.QuestionID != saved.SelectedQuestions[2].ID {
+		t.Errorf("Expected the interview to move on after the follow-up, got %+v", resp)
+	}
+}
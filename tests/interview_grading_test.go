@@ -0,0 +1,252 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"altoai_mvp/internal/handlers"
+	"altoai_mvp/internal/repository"
+	"altoai_mvp/internal/services"
+	"altoai_mvp/interview"
+
+	"github.com/gin-gonic/gin"
+)
+
+// startGradingQueue installs a fast queue graded by fake and removes it when the test ends
+func startGradingQueue(t *testing.T, fake *interview.FakeLLMClient) {
+	t.Helper()
+	previous := interview.GetAnalyzer()
+	interview.SetAnalyzer(interview.NewVisaAnalyzerWithClient(fake))
+
+	q := interview.NewGradingQueue(interview.GradingQueueConfig{Workers: 1, RetryInterval: 10 * time.Millisecond})
+	q.Start()
+	interview.SetGradingQueue(q)
+	t.Cleanup(func() {
+		interview.SetGradingQueue(nil)
+		q.Stop()
+		interview.SetAnalyzer(previous)
+	})
+}
+
+// waitUntilGraded blocks until no answer of the session is pending
+func waitUntilGraded(t *testing.T, sessionID string) *interview.Session {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		changed, stop := interview.WatchGrading(sessionID)
+		unlock := interview.LockSession(sessionID)
+		s, _ := interview.GetSession(sessionID)
+		pending := s.HasPendingGrading()
+		unlock()
+		if !pending {
+			stop()
+			return s
+		}
+		select {
+		case <-changed:
+			stop()
+		case <-timeout:
+			stop()
+			t.Fatalf("Session %s still pending", sessionID)
+		}
+	}
+}
+
+func TestGradingQueueRegradesPendingAnswers(t *testing.T) {
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+	fake := interview.NewFakeLLMClient()
+	// The provider is still down on the first try
+	fake.Enqueue(interview.FakeResponse{Err: interview.ErrCircuitOpen})
+
+	session := interview.NewSessionWithLevel("alice", "easy")
+	for _, q := range session.SelectedQuestions[:2] {
+		session.Answers = append(session.Answers, interview.Answer{
+			QuestionID:    q.ID,
+			QuestionText:  q.Text,
+			Text:          "An answer",
+			GradingStatus: interview.GradingStatusPending,
+		})
+	}
+	session.Status = interview.SessionStatusFinished
+	interview.SaveSession(session)
+
+	startGradingQueue(t, fake)
+	interview.EnqueueGrading(session.ID)
+
+	graded := waitUntilGraded(t, session.ID)
+	for _, a := range graded.Answers {
+		if a.GradingStatus != interview.GradingStatusGraded || a.Analysis == nil || a.Eval == nil {
+			t.Errorf("Answer %s should be graded, got %+v", a.QuestionID, a)
+		}
+	}
+	if graded.Summary == nil {
+		t.Error("Summary should be regenerated for a finished session")
+	}
+
+	var want interview.Scores
+	for _, a := range graded.Answers {
+		want.Academic += a.Eval.ScoreDelta.Academic
+		want.Financial += a.Eval.ScoreDelta.Financial
+		want.IntentToReturn += a.Eval.ScoreDelta.IntentToReturn
+		want.OverallRisk += a.Eval.ScoreDelta.OverallRisk
+	}
+	if graded.Scores != want {
+		t.Errorf("Scores should be recomputed from evals: want %+v, got %+v", want, graded.Scores)
+	}
+}
+
+func TestChatAsyncGradingAndPolling(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+	startGradingQueue(t, interview.NewFakeLLMClient())
+
+	session := interview.NewSessionWithLevel("alice", "easy")
+	interview.SaveSession(session)
+
+	userSvc := services.NewUserService(repository.NewUserMemoryRepo())
+	chatH := handlers.NewChatHandler(userSvc)
+	interviewH := handlers.NewInterviewHandler(userSvc)
+	r := gin.New()
+	r.POST("/chat", withUser("alice"), chatH.Chat)
+	r.GET("/interviews/:id/grading", withUser("alice"), interviewH.Grading)
+
+	body := `{"session_id":"` + session.ID + `","async_grading":true,"messages":[{"role":"user","content":"To study data science."}]}`
+	req := httptest.NewRequest(http.MethodPost, "/chat", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var chatResp struct {
+		Data handlers.ChatResponse `json:"data"`
+	}
+	decodeJSON(t, w, &chatResp)
+	if chatResp.Data.GradingStatus != interview.GradingStatusPending || chatResp.Data.Analysis != nil {
+		t.Errorf("Async chat should return before grading, got %+v", chatResp.Data)
+	}
+	if chatResp.Data.QuestionID == session.SelectedQuestions[0].ID {
+		t.Error("Async chat should still move on to the next question")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/interviews/"+session.ID+"/grading?wait=5s", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var gradingResp struct {
+		Data handlers.GradingStatusResponse `json:"data"`
+	}
+	decodeJSON(t, w, &gradingResp)
+	if !gradingResp.Data.Complete || gradingResp.Data.Graded != 1 {
+		t.Errorf("Expected grading to complete with 1 graded answer, got %+v", gradingResp.Data)
+	}
+	if len(gradingResp.Data.Answers) != 1 || gradingResp.Data.Answers[0].Analysis == nil {
+		t.Errorf("Expected the analysis in the grading response, got %+v", gradingResp.Data.Answers)
+	}
+}
+
+func TestGradingLongPollOutlastsWriteTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+	// Grading takes longer than the server allows for a whole response
+	fake := interview.NewFakeLLMClient()
+	fake.Enqueue(interview.FakeResponse{Content: sampleAnalysisJSON, Delay: 300 * time.Millisecond})
+	startGradingQueue(t, fake)
+
+	session := interview.NewSessionWithLevel("alice", "easy")
+	q := session.SelectedQuestions[0]
+	session.Answers = append(session.Answers, interview.Answer{
+		QuestionID:    q.ID,
+		QuestionText:  q.Text,
+		Text:          "An answer",
+		GradingStatus: interview.GradingStatusPending,
+	})
+	interview.SaveSession(session)
+	interview.EnqueueGrading(session.ID)
+
+	interviewH := handlers.NewInterviewHandler(services.NewUserService(repository.NewUserMemoryRepo()))
+	r := gin.New()
+	r.GET("/interviews/:id/grading", withUser("alice"), interviewH.Grading)
+	srv := httptest.NewUnstartedServer(r)
+	srv.Config.WriteTimeout = 100 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/interviews/" + session.ID + "/grading?wait=5s")
+	if err != nil {
+		t.Fatalf("The long poll should get a reply past the write timeout: %v", err)
+	}
+	defer resp.Body.Close()
+	var gradingResp struct {
+		Data handlers.GradingStatusResponse `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&gradingResp); err != nil {
+		t.Fatalf("Decoding the reply failed: %v", err)
+	}
+	if !gradingResp.Data.Complete || gradingResp.Data.Graded != 1 {
+		t.Errorf("Expected grading to complete with 1 graded answer, got %+v", gradingResp.Data)
+	}
+}
+
+func TestChatDisconnectLeavesAnswerPending(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+	startGradingQueue(t, interview.NewFakeLLMClient())
+
+	session := interview.NewSessionWithLevel("alice", "easy")
+	interview.SaveSession(session)
+
+	chatH := handlers.NewChatHandler(services.NewUserService(repository.NewUserMemoryRepo()))
+	r := gin.New()
+	r.POST("/chat", withUser("alice"), chatH.Chat)
+
+	// The client is gone before grading runs
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	body := `{"session_id":"` + session.ID + `","messages":[{"role":"user","content":"To study data science."}]}`
+	req := httptest.NewRequest(http.MethodPost, "/chat", bytes.NewBufferString(body)).WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	graded := waitUntilGraded(t, session.ID)
+	if len(graded.Answers) != 1 || graded.Answers[0].GradingStatus != interview.GradingStatusGraded || graded.Answers[0].Analysis == nil {
+		t.Errorf("Expected the answer to be graded in the background, got %+v", graded.Answers)
+	}
+}
+
+func TestLockSessionExcludes(t *testing.T) {
+	// Locks are dropped when released; one taken meanwhile must still exclude
+	var holders atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				unlock := interview.LockSession("lock-test")
+				if holders.Add(1) != 1 {
+					t.Error("Two callers held the same session lock")
+				}
+				holders.Add(-1)
+				unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,171 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"altoai_mvp/internal/handlers"
+	"altoai_mvp/internal/repository"
+	"altoai_mvp/internal/services"
+	"altoai_mvp/interview"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newInterviewRouter(userID string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	interviewH := handlers.NewInterviewHandler(services.NewUserService(repository.NewUserMemoryRepo()))
+	r := gin.New()
+	g := r.Group("/interviews", withUser(userID))
+	g.GET("", interviewH.List)
+	g.GET("/:id", interviewH.Get)
+	g.DELETE("/:id", interviewH.Delete)
+	return r
+}
+
+func TestListSessionsFilter(t *testing.T) {
+	store := interview.NewMemorySessionStore()
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, level := range []string{"easy", "hard", "easy"} {
+		s := &interview.Session{ID: level + string(rune('a'+i)), UserID: "alice", Level: level, Status: interview.SessionStatusFinished, CreatedAt: base.AddDate(0, 0, i)}
+		store.SaveSession(s)
+	}
+	store.SaveSession(&interview.Session{ID: "other", UserID: "bob", Level: "easy", CreatedAt: base})
+
+	sessions, total, err := store.ListSessions(interview.SessionFilter{UserID: "alice", Level: "easy"})
+	if err != nil {
+		t.Fatalf("ListSessions failed: %v", err)
+	}
+	if total != 2 || len(sessions) != 2 {
+		t.Fatalf("Expected 2 easy sessions for alice, got %d (total %d)", len(sessions), total)
+	}
+	if !sessions[0].CreatedAt.After(sessions[1].CreatedAt) {
+		t.Error("Sessions should be ordered newest first")
+	}
+
+	sessions, total, _ = store.ListSessions(interview.SessionFilter{UserID: "alice", From: base.AddDate(0, 0, 1), Limit: 1})
+	if total != 2 || len(sessions) != 1 {
+		t.Errorf("Expected 1 of 2 sessions after date filter and limit, got %d (total %d)", len(sessions), total)
+	}
+
+	// Visa types are matched however the caller spells them
+	previous := interview.GetSessionStore()
+	defer interview.SetSessionStore(previous)
+	interview.SetSessionStore(store)
+	store.SaveSession(&interview.Session{ID: "j1", UserID: "alice", VisaType: interview.VisaJ1, CreatedAt: base})
+	if sessions, total, _ = interview.ListSessions(interview.SessionFilter{UserID: "alice", VisaType: "j-1"}); total != 1 || sessions[0].ID != "j1" {
+		t.Errorf("Expected the J-1 session for visa_type=j-1, got %d", total)
+	}
+}
+
+func TestInterviewHistoryEndpoints(t *testing.T) {
+	previous := interview.GetSessionStore()
+	defer interview.SetSessionStore(previous)
+	interview.SetSessionStore(interview.NewMemorySessionStore())
+
+	mine := &interview.Session{ID: "mine", UserID: "alice", Level: "easy", Status: interview.SessionStatusFinished, CreatedAt: time.Now()}
+	theirs := &interview.Session{ID: "theirs", UserID: "bob", Level: "easy", Status: interview.SessionStatusFinished, CreatedAt: time.Now()}
+	interview.SaveSession(mine)
+	interview.SaveSession(theirs)
+
+	r := newInterviewRouter("alice")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/interviews?level=easy", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("List: expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var list struct {
+		Data handlers.InterviewListResponse `json:"data"`
+	}
+	decodeJSON(t, w, &list)
+	if list.Data.Total != 1 || len(list.Data.Items) != 1 || list.Data.Items[0].ID != "mine" {
+		t.Errorf("List should only return the caller's sessions, got %+v", list.Data)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/interviews?from=not-a-date", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("List with bad date: expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/interviews/theirs", nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Get foreign: expected status %d, got %d", http.StatusForbidden, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/interviews/mine", nil))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Delete: expected status %d, got %d", http.StatusNoContent, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/interviews/mine", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Get deleted: expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+// TestInterviewGetWhileChatting reads a session while its answers are being
+// recorded and graded; run with -race to catch unlocked reads
+func TestInterviewGetWhileChatting(t *testing.T) {
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+	previous := interview.GetAnalyzer()
+	defer interview.SetAnalyzer(previous)
+	interview.SetAnalyzer(interview.NewVisaAnalyzerWithClient(interview.NewFakeLLMClient()))
+
+	session := interview.NewSessionWithLevel("alice", "easy")
+	interview.SaveSession(session)
+	defer interview.DeleteSession(session.ID)
+
+	r := newInterviewRouter("alice")
+	chatH := handlers.NewChatHandler(services.NewUserService(repository.NewUserMemoryRepo()))
+	r.POST("/chat", withUser("alice"), chatH.Chat)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		body := `{"session_id":"` + session.ID + `","messages":[{"role":"user","content":"To study data science."}]}`
+		for range 20 {
+			req := httptest.NewRequest(http.MethodPost, "/chat", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			var chatResp struct {
+				Data handlers.ChatResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &chatResp); err != nil {
+				t.Errorf("Chat: failed to decode %q: %v", w.Body.String(), err)
+				return
+			}
+			if chatResp.Data.Finished {
+				return
+			}
+		}
+	}()
+
+	for reading := true; reading; {
+		select {
+		case <-done:
+			reading = false
+		default:
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/interviews/"+session.ID, nil))
+		var got struct {
+			Data interview.Session `json:"data"`
+		}
+		decodeJSON(t, w, &got)
+		if w.Code != http.StatusOK || got.Data.ID != session.ID {
+			t.Fatalf("Get: expected the session with status %d, got %d", http.StatusOK, w.Code)
+		}
+	}
+}
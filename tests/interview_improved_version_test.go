@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"altoai_mvp/interview"
+)
+
+// analysisWithImprovedVersion is a valid analysis whose model answer is improved
+func analysisWithImprovedVersion(improved string) string {
+	return `{
+		"scores": {"migration_intent": null, "financial_understanding": 4, "academic_credibility": null,
+			"specificity_research": null, "consistency": null, "communication_quality": 4, "red_flags": 4, "total_score": 12},
+		"classification": "Good",
+		"feedback": {"overall": "Clear sponsor.", "by_criterion": {}, "improvements": ["Give the yearly cost."]},
+		"improved_version": "` + improved + `"
+	}`
+}
+
+func TestImprovedVersionUsesStatedFacts(t *testing.T) {
+	useProfileStore(t)
+	if err := interview.SaveProfile(&interview.ApplicantProfile{UserID: "alice", EstimatedCost: 52000}); err != nil {
+		t.Fatalf("SaveProfile failed: %v", err)
+	}
+
+	// The figures come from the answer ($40,000) and the profile ($52,000)
+	good := "My father, a surgeon, is sponsoring me. He has set aside $40,000 a year toward the $52,000 cost of my F-1 program."
+	fake := interview.NewFakeLLMClient(analysisWithImprovedVersion(good))
+	va := interview.NewVisaAnalyzerWithClient(fake)
+	session := interview.NewSession("alice")
+
+	analysis, err := va.AnalyzeAnswerWithSession(session, "Financial Capability", "Who is sponsoring you?", "My father. He is a surgeon and saved 40,000 dollars per year.")
+	if err != nil {
+		t.Fatalf("AnalyzeAnswerWithSession failed: %v", err)
+	}
+	if analysis.ImprovedVersion != good {
+		t.Errorf("Expected the improved version to be kept, got %q", analysis.ImprovedVersion)
+	}
+	if len(fake.Requests()) != 1 {
+		t.Errorf("Expected no repair, got %d requests", len(fake.Requests()))
+	}
+	if prompt := fake.Requests()[0].Messages[0].Content; !strings.Contains(prompt, `"improved_version": "string"`) {
+		t.Error("Expected the prompt to ask for an improved version")
+	}
+}
+
+func TestImprovedVersionWithInventedFacts(t *testing.T) {
+	// Counts are not facts a rewrite makes up
+	counts := "My father is sponsoring me. I worked for 2 years and can explain my plan in 1 minute."
+	fake := interview.NewFakeLLMClient(analysisWithImprovedVersion(counts))
+	va := interview.NewVisaAnalyzerWithClient(fake)
+	analysis, err := va.AnalyzeAnswer("Who is sponsoring you?", "My father.")
+	if err != nil {
+		t.Fatalf("AnalyzeAnswer failed: %v", err)
+	}
+	if analysis.ImprovedVersion != counts {
+		t.Errorf("Expected the improved version to be kept, got %q", analysis.ImprovedVersion)
+	}
+
+	// Names the applicant stated are kept, whatever their case
+	named := "My father is sponsoring me. He is an engineer at Google, and I will study [Your Major] at Ohio State University in the United States."
+	fake = interview.NewFakeLLMClient(analysisWithImprovedVersion(named))
+	va = interview.NewVisaAnalyzerWithClient(fake)
+	analysis, err = va.AnalyzeAnswer("Who is sponsoring you?", "My father, he works at google. I got into ohio state university.")
+	if err != nil {
+		t.Fatalf("AnalyzeAnswer failed: %v", err)
+	}
+	if analysis.ImprovedVersion != named {
+		t.Errorf("Expected the improved version to be kept, got %q", analysis.ImprovedVersion)
+	}
+
+	// An invented amount or name drops the model answer without grading again
+	for _, invented := range []string{
+		"My father is sponsoring me, and I also won a $15,000 scholarship.",
+		"My father covers 80% of the cost.",
+		"My father is sponsoring me from 2026.",
+		"My father is sponsoring me, and I received the Fulbright scholarship.",
+		"Google offered me a position after graduation, so my father is sponsoring me.",
+		"My father, a professor at the University of Tashkent, is sponsoring me.",
+	} {
+		fake := interview.NewFakeLLMClient(analysisWithImprovedVersion(invented))
+		va := interview.NewVisaAnalyzerWithClient(fake)
+		analysis, err := va.AnalyzeAnswer("Who is sponsoring you?", "My father.")
+		if err != nil {
+			t.Fatalf("AnalyzeAnswer failed: %v", err)
+		}
+		if analysis.ImprovedVersion != "" || analysis.Classification == "" || analysis.Scores.TotalScore == 0 {
+			t.Errorf("Expected a graded analysis without an improved version for %q, got %+v", invented, analysis)
+		}
+		if len(fake.Requests()) != 1 {
+			t.Errorf("Expected no repair for %q, got %d requests", invented, len(fake.Requests()))
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"altoai_mvp/internal/handlers"
+	"altoai_mvp/internal/repository"
+	"altoai_mvp/internal/services"
+	"altoai_mvp/interview"
+
+	"github.com/gin-gonic/gin"
+)
+
+// writeLevels writes questions.json and the given levels.json to a temp dir and loads them
+func writeLevels(t *testing.T, levels string) {
+	t.Helper()
+	questions, err := os.ReadFile("../interview/questions.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "questions.json"), questions, 0o644)
+	os.WriteFile(filepath.Join(dir, "levels.json"), []byte(levels), 0o644)
+	t.Cleanup(func() { interview.LoadQuestions("../interview/questions.json") })
+	if err := interview.LoadQuestions(filepath.Join(dir, "questions.json")); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+}
+
+func TestLoadLevels(t *testing.T) {
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+
+	names := make([]string, 0)
+	for _, l := range interview.Levels() {
+		names = append(names, l.Name)
+	}
+	if strings.Join(names, ",") != "easy,medium,hard,targeted" {
+		t.Errorf("Expected easy, medium, hard and targeted in order, got %v", names)
+	}
+
+	def, err := interview.LookupLevel("")
+	if err != nil || def.Name != "hard" {
+		t.Errorf("Expected hard as the default level, got %+v (%v)", def, err)
+	}
+	if _, err := interview.LookupLevel("expert"); !errors.Is(err, interview.ErrUnknownLevel) {
+		t.Errorf("Expected ErrUnknownLevel, got %v", err)
+	}
+	if selected := interview.SelectQuestionsForSession("expert"); len(selected) != 0 {
+		t.Errorf("Unknown levels should not fall through to another level, got %d questions", len(selected))
+	}
+	if session := interview.NewSession("alice"); session.Level != "hard" {
+		t.Errorf("Sessions should record the default level, got %q", session.Level)
+	}
+}
+
+func TestCustomLevel(t *testing.T) {
+	writeLevels(t, `{
+		"default": "quick",
+		"levels": [{
+			"name": "quick",
+			"categories": [
+				{"category": "Financial Capability", "count": 2},
+				{"category": "Purpose of Study", "count": 1}
+			],
+			"followup_budget": 0,
+			"answer_time_limit_seconds": 60
+		}]
+	}`)
+
+	selected := interview.SelectQuestionsForSession("")
+	if len(selected) != 3 {
+		t.Fatalf("Expected 3 questions, got %d", len(selected))
+	}
+	for i, want := range []string{"Financial Capability", "Financial Capability", "Purpose of Study"} {
+		if selected[i].Category != want {
+			t.Errorf("Question %d: expected category %s, got %s", i, want, selected[i].Category)
+		}
+	}
+	if _, err := interview.LookupLevel("hard"); err == nil {
+		t.Error("Levels missing from levels.json should be unknown")
+	}
+
+	// A zero budget turns follow-ups off
+	session := interview.NewSession("alice")
+	weak := &interview.EvalResult{NeedsFollowup: true}
+	if f := interview.InsertFollowup(session, session.SelectedQuestions[0], weak); f != nil {
+		t.Errorf("Expected no follow-up with a zero budget, got %+v", f)
+	}
+}
+
+func TestLoadQuestionsKeepsConfigOnBadBank(t *testing.T) {
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+	levels := interview.Levels()
+	criteria := strings.Join(interview.CriterionKeys(), ",")
+
+	// Valid levels and rubric next to a bank without the categories they need
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "levels.json"), []byte(`{"default": "quick", "levels": [
+		{"name": "quick", "categories": [{"category": "Financial Capability", "count": 1}]}
+	]}`), 0o644)
+	os.WriteFile(filepath.Join(dir, "rubric.json"), []byte(`{"criteria": [
+		{"key": "communication_quality", "label": "Communication quality", "scale": {"min": 1, "max": 5}, "weight": 1}
+	]}`), 0o644)
+	os.WriteFile(filepath.Join(dir, "questions.json"), []byte(`{"version": 2, "questions": [
+		{"id": "only", "category": "Purpose of Study", "text": "Why this program?"}
+	]}`), 0o644)
+	t.Cleanup(func() { interview.LoadQuestions("../interview/questions.json") })
+
+	if err := interview.LoadQuestions(filepath.Join(dir, "questions.json")); !errors.Is(err, interview.ErrInvalidQuestion) {
+		t.Fatalf("Expected ErrInvalidQuestion, got %v", err)
+	}
+	if got := interview.Levels(); len(got) != len(levels) || got[0].Name != levels[0].Name {
+		t.Errorf("Expected the levels to be kept, got %+v", got)
+	}
+	if got := strings.Join(interview.CriterionKeys(), ","); got != criteria {
+		t.Errorf("Expected the rubric to be kept, got %s", got)
+	}
+	if _, ok := interview.LookupQuestion("only"); ok {
+		t.Error("Expected the bad bank not to be installed")
+	}
+}
+
+func TestLevelConfigValidation(t *testing.T) {
+	cases := map[string]interview.LevelConfig{
+		"no levels":       {Default: "easy"},
+		"missing default": {Default: "hard", Levels: []interview.Level{{Name: "easy", Categories: []interview.LevelCategory{{Category: "Purpose of Study", Count: 1}}}}},
+		"zero count":      {Default: "easy", Levels: []interview.Level{{Name: "easy", Categories: []interview.LevelCategory{{Category: "Purpose of Study"}}}}},
+		"targeted length": {Default: "easy", Levels: []interview.Level{{Name: "easy", Strategy: interview.LevelStrategyTargeted, Categories: []interview.LevelCategory{{Category: "Purpose of Study", Count: 1}}}}},
+		"duplicate name": {Default: "easy", Levels: []interview.Level{
+			{Name: "easy", Categories: []interview.LevelCategory{{Category: "Purpose of Study", Count: 1}}},
+			{Name: "easy", Categories: []interview.LevelCategory{{Category: "Purpose of Study", Count: 2}}},
+		}},
+	}
+	for name, config := range cases {
+		if err := config.Validate(); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
+
+func TestChatRejectsUnknownLevel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+
+	chatH := handlers.NewChatHandler(services.NewUserService(repository.NewUserMemoryRepo()))
+	r := gin.New()
+	r.POST("/chat", withUser("alice"), chatH.Chat)
+
+	if w := doJSON(r, http.MethodPost, "/chat", `{"level":"expert"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+	}
+}
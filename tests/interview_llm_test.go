@@ -0,0 +1,292 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"altoai_mvp/interview"
+)
+
+const sampleAnalysisJSON = `{
+  "scores": {"migration_intent": null, "financial_understanding": 5, "academic_credibility": null,
+             "specificity_research": null, "consistency": null, "communication_quality": 4, "red_flags": 5, "total_score": 0},
+  "classification": "Weak",
+  "feedback": {"overall": "Clear funding plan.", "by_criterion": {}, "improvements": ["Mention the sponsor's income"]}
+}`
+
+func TestAnalyzeAnswerWithFakeClient(t *testing.T) {
+	fake := interview.NewFakeLLMClient("```json\n" + sampleAnalysisJSON + "\n```")
+	previous := interview.GetAnalyzer()
+	defer interview.SetAnalyzer(previous)
+	interview.SetAnalyzer(interview.NewVisaAnalyzerWithClient(fake))
+
+	session := interview.NewSession("alice")
+	q := interview.Question{ID: "q1", Category: "Financial Capability", Text: "Who is sponsoring you?"}
+
+	analysis, err := interview.AnalyzeAnswer(session, q, "My father, a surgeon earning $90,000 a year.")
+	if err != nil {
+		t.Fatalf("AnalyzeAnswer failed: %v", err)
+	}
+	if analysis.Scores.TotalScore != 14 {
+		t.Errorf("Expected total score 14, got %d", analysis.Scores.TotalScore)
+	}
+	if analysis.Classification != "Excellent" {
+		t.Errorf("Classification should be corrected to Excellent, got %s", analysis.Classification)
+	}
+
+	requests := fake.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(requests))
+	}
+	msgs := requests[0].Messages
+	if msgs[0].Role != "system" {
+		t.Errorf("First message should be the system prompt, got role %s", msgs[0].Role)
+	}
+	if last := msgs[len(msgs)-1].Content; !strings.Contains(last, "Category: Financial Capability") {
+		t.Errorf("Last message should include the category, got %q", last)
+	}
+}
+
+func TestFakeClientDefaultAndErrors(t *testing.T) {
+	fake := interview.NewFakeLLMClient()
+	boom := errors.New("boom")
+	fake.Enqueue(interview.FakeResponse{Err: boom})
+
+	va := interview.NewVisaAnalyzerWithClient(fake)
+	if _, err := va.AnalyzeAnswer("Q", "A"); !errors.Is(err, boom) {
+		t.Errorf("Expected scripted error, got %v", err)
+	}
+
+	analysis, err := va.AnalyzeAnswer("Q", "A")
+	if err != nil {
+		t.Fatalf("Default fake response should parse: %v", err)
+	}
+	if analysis.Classification == "" {
+		t.Error("Default fake response should be classified")
+	}
+}
+
+func TestAnalyzerWithoutClient(t *testing.T) {
+	va := interview.NewVisaAnalyzerWithClient(nil)
+	if _, err := va.AnalyzeAnswer("Q", "A"); !errors.Is(err, interview.ErrLLMNotConfigured) {
+		t.Errorf("Expected ErrLLMNotConfigured, got %v", err)
+	}
+}
+
+func TestNewLLMClientProviders(t *testing.T) {
+	if _, err := interview.NewLLMClient(interview.LLMConfig{Provider: interview.ProviderOpenAI}); !errors.Is(err, interview.ErrLLMNotConfigured) {
+		t.Errorf("OpenAI without key: expected ErrLLMNotConfigured, got %v", err)
+	}
+	if _, err := interview.NewLLMClient(interview.LLMConfig{Provider: "nope"}); err == nil {
+		t.Error("Unknown provider should be rejected")
+	}
+	if c, err := interview.NewLLMClient(interview.LLMConfig{Provider: interview.ProviderOllama}); err != nil || c == nil {
+		t.Errorf("Ollama needs no key, got %v", err)
+	}
+}
+
+// captureServer records the decoded request body and replies with reply
+func captureServer(t *testing.T, path, reply string, got *map[string]any, headers *http.Header) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			t.Errorf("Expected path %s, got %s", path, r.URL.Path)
+		}
+		*headers = r.Header.Clone()
+		json.NewDecoder(r.Body).Decode(got)
+		w.Write([]byte(reply))
+	}))
+}
+
+func TestOpenAIClient(t *testing.T) {
+	var body map[string]any
+	var headers http.Header
+	srv := captureServer(t, "/v1/chat/completions", `{"choices":[{"message":{"role":"assistant","content":"hi"}}]}`, &body, &headers)
+	defer srv.Close()
+
+	c := interview.NewOpenAIClient("sk-test", srv.URL+"/v1", "gpt-test", nil)
+	resp, err := c.Complete(context.Background(), interview.LLMRequest{Messages: []interview.GPTMessage{{Role: "user", Content: "hello"}}})
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if resp.Content != "hi" {
+		t.Errorf("Expected 'hi', got %q", resp.Content)
+	}
+	if headers.Get("Authorization") != "Bearer sk-test" {
+		t.Errorf("Expected bearer auth, got %q", headers.Get("Authorization"))
+	}
+	if body["model"] != "gpt-test" {
+		t.Errorf("Expected model gpt-test, got %v", body["model"])
+	}
+}
+
+func TestAnthropicClient(t *testing.T) {
+	var body map[string]any
+	var headers http.Header
+	srv := captureServer(t, "/v1/messages", `{"content":[{"type":"text","text":"hello "},{"type":"text","text":"there"}]}`, &body, &headers)
+	defer srv.Close()
+
+	c := interview.NewAnthropicClient("ak-test", srv.URL+"/v1", "", nil)
+	resp, err := c.Complete(context.Background(), interview.LLMRequest{Messages: []interview.GPTMessage{
+		{Role: "system", Content: "be an officer"},
+		{Role: "user", Content: "hello"},
+	}})
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if resp.Content != "hello there" {
+		t.Errorf("Expected joined text blocks, got %q", resp.Content)
+	}
+	if headers.Get("x-api-key") != "ak-test" || headers.Get("anthropic-version") == "" {
+		t.Errorf("Missing Anthropic headers: %v", headers)
+	}
+	if body["system"] != "be an officer" {
+		t.Errorf("System prompt should be lifted out, got %v", body["system"])
+	}
+	if msgs := body["messages"].([]any); len(msgs) != 1 {
+		t.Errorf("Expected only the user turn in messages, got %d", len(msgs))
+	}
+}
+
+func TestOllamaClient(t *testing.T) {
+	var body map[string]any
+	var headers http.Header
+	srv := captureServer(t, "/api/chat", `{"message":{"role":"assistant","content":"local"}}`, &body, &headers)
+	defer srv.Close()
+
+	c := interview.NewOllamaClient(srv.URL, "mistral", nil)
+	resp, err := c.Complete(context.Background(), interview.LLMRequest{Messages: []interview.GPTMessage{{Role: "user", Content: "hello"}}})
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if resp.Content != "local" {
+		t.Errorf("Expected 'local', got %q", resp.Content)
+	}
+	if body["stream"] != false || body["model"] != "mistral" {
+		t.Errorf("Unexpected request body: %v", body)
+	}
+}
+
+func TestLLMAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := interview.NewOpenAIClient("sk-test", srv.URL, "", nil)
+	_, err := c.Complete(context.Background(), interview.LLMRequest{})
+	var apiErr *interview.LLMAPIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected *LLMAPIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", apiErr.StatusCode)
+	}
+}
+
+func TestAnalyzerRepairsInvalidOutput(t *testing.T) {
+	invalid := `{"scores": {"financial_understanding": 7, "charm": 4}, "classification": "Good", "feedback": {"overall": ""}}`
+	fake := interview.NewFakeLLMClient("Sure! Here is my analysis.", invalid, sampleAnalysisJSON)
+	va := interview.NewVisaAnalyzerWithClient(fake)
+
+	analysis, err := va.AnalyzeAnswer("Who is sponsoring you?", "My father.")
+	if err != nil {
+		t.Fatalf("Analyzer should recover after repairs: %v", err)
+	}
+	if analysis.Scores.TotalScore != 14 {
+		t.Errorf("Expected total score 14, got %d", analysis.Scores.TotalScore)
+	}
+
+	requests := fake.Requests()
+	if len(requests) != 3 {
+		t.Fatalf("Expected 3 requests (1 + 2 repairs), got %d", len(requests))
+	}
+	if requests[0].ResponseSchema == nil {
+		t.Error("Analysis requests should carry the response schema")
+	}
+	msgs := requests[2].Messages
+	last := msgs[len(msgs)-1]
+	if last.Role != "user" {
+		t.Fatalf("Repair prompt should be a user message, got %s", last.Role)
+	}
+	for _, want := range []string{"scores.financial_understanding must be an integer from 1 to 5", `unknown criterion "charm"`, "feedback.overall"} {
+		if !strings.Contains(last.Content, want) {
+			t.Errorf("Repair prompt should mention %q, got %q", want, last.Content)
+		}
+	}
+	if prev := msgs[len(msgs)-2]; prev.Role != "assistant" || prev.Content != invalid {
+		t.Errorf("Repair should replay the invalid reply as the assistant turn, got %+v", prev)
+	}
+}
+
+func TestAnalyzerGivesUpAfterMaxRepairs(t *testing.T) {
+	fake := interview.NewFakeLLMClient("not json", "still not json", "nope")
+	va := interview.NewVisaAnalyzerWithClient(fake)
+	va.SetMaxRepairAttempts(1)
+
+	_, err := va.AnalyzeAnswer("Q", "A")
+	var vErr *interview.AnalysisValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("Expected *AnalysisValidationError, got %v", err)
+	}
+	if vErr.Attempts != 2 || len(fake.Requests()) != 2 {
+		t.Errorf("Expected 2 attempts, got %d (requests %d)", vErr.Attempts, len(fake.Requests()))
+	}
+}
+
+func TestValidateAnalysis(t *testing.T) {
+	if _, problems := interview.ValidateAnalysis(sampleAnalysisJSON); len(problems) != 0 {
+		t.Errorf("Sample analysis should be valid, got %v", problems)
+	}
+
+	allNull := `{"scores": {"red_flags": null}, "feedback": {"overall": "ok", "improvements": ["x"]}}`
+	if _, problems := interview.ValidateAnalysis(allNull); len(problems) != 1 || !strings.Contains(problems[0], "at least one criterion") {
+		t.Errorf("Expected a single 'at least one criterion' problem, got %v", problems)
+	}
+
+	fractional := `{"scores": {"red_flags": 3.5}, "feedback": {"overall": "ok", "improvements": ["x"]}}`
+	if _, problems := interview.ValidateAnalysis(fractional); len(problems) == 0 {
+		t.Error("Fractional scores should be rejected")
+	}
+}
+
+func TestStructuredOutputRequests(t *testing.T) {
+	schema := &interview.ResponseSchema{Name: "test", Schema: map[string]any{"type": "object"}}
+	req := interview.LLMRequest{Messages: []interview.GPTMessage{{Role: "user", Content: "json please"}}, ResponseSchema: schema}
+
+	var body map[string]any
+	var headers http.Header
+	srv := captureServer(t, "/chat/completions", `{"choices":[{"message":{"role":"assistant","content":"{}"}}]}`, &body, &headers)
+	defer srv.Close()
+
+	openai := interview.NewOpenAIClient("sk-test", srv.URL, "", nil)
+	if _, err := openai.Complete(context.Background(), req); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if rf, _ := body["response_format"].(map[string]any); rf["type"] != "json_object" {
+		t.Errorf("Expected json_object response_format by default, got %v", body["response_format"])
+	}
+
+	openai.ResponseFormat = interview.ResponseFormatJSONSchema
+	if _, err := openai.Complete(context.Background(), req); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	rf, _ := body["response_format"].(map[string]any)
+	if js, _ := rf["json_schema"].(map[string]any); rf["type"] != "json_schema" || js["name"] != "test" {
+		t.Errorf("Expected json_schema response_format, got %v", body["response_format"])
+	}
+
+	ollamaSrv := captureServer(t, "/api/chat", `{"message":{"role":"assistant","content":"{}"}}`, &body, &headers)
+	defer ollamaSrv.Close()
+
+	if _, err := interview.NewOllamaClient(ollamaSrv.URL, "", nil).Complete(context.Background(), req); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if format, _ := body["format"].(map[string]any); format["type"] != "object" {
+		t.Errorf("Expected the schema as Ollama format, got %v", body["format"])
+	}
+}
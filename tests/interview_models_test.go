@@ -1,9 +1,9 @@
 package tests
 
 import (
+	"altoai_mvp/interview"
 	"encoding/json"
 	"testing"
-	"altoai_mvp/interview"
 )
 
 func TestAnalysisScoresJSON(t *testing.T) {
@@ -15,14 +15,16 @@ func TestAnalysisScoresJSON(t *testing.T) {
 	cq := 5
 	rf := 5
 	scores := interview.AnalysisScores{
-		MigrationIntent:        &mi,
-		FinancialUnderstanding: &fu,
-		AcademicCredibility:    &ac,
-		SpecificityResearch:    &sr,
-		Consistency:            &co,
-		CommunicationQuality:   &cq,
-		RedFlags:              &rf,
-		TotalScore:            32,
+		Criteria: map[string]*int{
+			"migration_intent":        &mi,
+			"financial_understanding": &fu,
+			"academic_credibility":    &ac,
+			"specificity_research":    &sr,
+			"consistency":             &co,
+			"communication_quality":   &cq,
+			"red_flags":               &rf,
+		},
+		TotalScore: 32,
 	}
 
 	jsonData, err := json.Marshal(scores)
@@ -35,14 +37,14 @@ func TestAnalysisScoresJSON(t *testing.T) {
 		t.Fatalf("Failed to unmarshal AnalysisScores: %v", err)
 	}
 
-	if unmarshaled.MigrationIntent == nil || *unmarshaled.MigrationIntent != *scores.MigrationIntent {
-		t.Errorf("MigrationIntent mismatch: got %v, want %d", unmarshaled.MigrationIntent, *scores.MigrationIntent)
+	if unmarshaled.Score("migration_intent") == nil || *unmarshaled.Score("migration_intent") != *scores.Score("migration_intent") {
+		t.Errorf("migration_intent mismatch: got %v, want %d", unmarshaled.Score("migration_intent"), *scores.Score("migration_intent"))
 	}
-	if unmarshaled.FinancialUnderstanding == nil || *unmarshaled.FinancialUnderstanding != *scores.FinancialUnderstanding {
-		t.Errorf("FinancialUnderstanding mismatch: got %v, want %d", unmarshaled.FinancialUnderstanding, *scores.FinancialUnderstanding)
+	if unmarshaled.Score("financial_understanding") == nil || *unmarshaled.Score("financial_understanding") != *scores.Score("financial_understanding") {
+		t.Errorf("financial_understanding mismatch: got %v, want %d", unmarshaled.Score("financial_understanding"), *scores.Score("financial_understanding"))
 	}
-	if unmarshaled.SpecificityResearch == nil || *unmarshaled.SpecificityResearch != *scores.SpecificityResearch {
-		t.Errorf("SpecificityResearch mismatch: got %v, want %d", unmarshaled.SpecificityResearch, *scores.SpecificityResearch)
+	if unmarshaled.Score("specificity_research") == nil || *unmarshaled.Score("specificity_research") != *scores.Score("specificity_research") {
+		t.Errorf("specificity_research mismatch: got %v, want %d", unmarshaled.Score("specificity_research"), *scores.Score("specificity_research"))
 	}
 	if unmarshaled.TotalScore != scores.TotalScore {
 		t.Errorf("TotalScore mismatch: got %d, want %d", unmarshaled.TotalScore, scores.TotalScore)
@@ -53,13 +55,13 @@ func TestStructuredFeedbackJSON(t *testing.T) {
 	feedback := interview.StructuredFeedback{
 		Overall: "Good answer overall",
 		ByCriterion: interview.FeedbackByCriterion{
-			MigrationIntent:       "Strong return intent",
-			FinancialUnderstanding: "Good financial understanding",
-			AcademicCredibility:   "Strong academic fit",
-			SpecificityResearch:   "Good research",
-			Consistency:           "Consistent",
-			CommunicationQuality:  "Clear communication",
-			RedFlags:              "No red flags",
+			"migration_intent":        "Strong return intent",
+			"financial_understanding": "Good financial understanding",
+			"academic_credibility":    "Strong academic fit",
+			"specificity_research":    "Good research",
+			"consistency":             "Consistent",
+			"communication_quality":   "Clear communication",
+			"red_flags":               "No red flags",
 		},
 		Improvements: []string{"Be more specific", "Add examples"},
 	}
@@ -92,26 +94,28 @@ func TestAnalysisResponseJSON(t *testing.T) {
 	rf := 5
 	response := interview.AnalysisResponse{
 		Scores: interview.AnalysisScores{
-			MigrationIntent:        &mi,
-			FinancialUnderstanding: &fu,
-			AcademicCredibility:    &ac,
-			SpecificityResearch:    &sr,
-			Consistency:            &co,
-			CommunicationQuality:   &cq,
-			RedFlags:              &rf,
-			TotalScore:            32,
+			Criteria: map[string]*int{
+				"migration_intent":        &mi,
+				"financial_understanding": &fu,
+				"academic_credibility":    &ac,
+				"specificity_research":    &sr,
+				"consistency":             &co,
+				"communication_quality":   &cq,
+				"red_flags":               &rf,
+			},
+			TotalScore: 32,
 		},
 		Classification: "Excellent",
 		Feedback: interview.StructuredFeedback{
 			Overall: "Good answer",
 			ByCriterion: interview.FeedbackByCriterion{
-				MigrationIntent:       "Good",
-				FinancialUnderstanding: "Good",
-				AcademicCredibility:   "Good",
-				SpecificityResearch:   "Good",
-				Consistency:           "Good",
-				CommunicationQuality:  "Good",
-				RedFlags:              "Good",
+				"migration_intent":        "Good",
+				"financial_understanding": "Good",
+				"academic_credibility":    "Good",
+				"specificity_research":    "Good",
+				"consistency":             "Good",
+				"communication_quality":   "Good",
+				"red_flags":               "Good",
 			},
 			Improvements: []string{"Improve clarity"},
 		},
@@ -134,5 +138,3 @@ func TestAnalysisResponseJSON(t *testing.T) {
 		t.Errorf("TotalScore mismatch: got %d, want %d", unmarshaled.Scores.TotalScore, response.Scores.TotalScore)
 	}
 }
-
-
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"altoai_mvp/internal/handlers"
+	"altoai_mvp/internal/middleware"
+	"altoai_mvp/internal/repository"
+	"altoai_mvp/internal/services"
+	"altoai_mvp/interview"
+
+	"github.com/gin-gonic/gin"
+)
+
+// withUser simulates JWTAuth by storing claims for the given user ID
+func withUser(userID string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("user", &middleware.MyClaims{UserID: userID, Email: userID + "@example.com"})
+		c.Next()
+	}
+}
+
+func TestSessionOwnedBy(t *testing.T) {
+	session := interview.NewSession("alice")
+	if !session.OwnedBy("alice") {
+		t.Error("Session should be owned by its creator")
+	}
+	if session.OwnedBy("bob") {
+		t.Error("Session should not be owned by another user")
+	}
+}
+
+func TestChatRejectsForeignSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+
+	session := interview.NewSession("alice")
+	interview.SaveSession(session)
+
+	chatH := handlers.NewChatHandler(services.NewUserService(repository.NewUserMemoryRepo()))
+	r := gin.New()
+	r.POST("/chat", withUser("bob"), chatH.Chat)
+
+	body := `{"session_id":"` + session.ID + `","messages":[{"role":"user","content":"hello"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/chat", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d, got %d", http.StatusForbidden, w.Code)
+	}
+}
+
+func TestChatCreatesSessionForCaller(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+
+	chatH := handlers.NewChatHandler(services.NewUserService(repository.NewUserMemoryRepo()))
+	r := gin.New()
+	r.POST("/chat", withUser("alice"), chatH.Chat)
+
+	req := httptest.NewRequest(http.MethodPost, "/chat", bytes.NewBufferString(`{"level":"easy"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var resp struct {
+		Data handlers.ChatResponse `json:"data"`
+	}
+	decodeJSON(t, w, &resp)
+
+	session, ok := interview.GetSession(resp.Data.SessionID)
+	if !ok {
+		t.Fatal("Session should be saved")
+	}
+	if session.UserID != "alice" {
+		t.Errorf("Expected session owned by 'alice', got %q", session.UserID)
+	}
+}
+
+func TestSubmitAnswerRejectsForeignSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	session := interview.NewSession("alice")
+	interview.SaveSession(session)
+
+	r := gin.New()
+	userResolver := handlers.UserResolver(services.NewUserService(repository.NewUserMemoryRepo()))
+	r.POST("/sessions/:id/answer", withUser("bob"), interview.SubmitAnswerHandler(userResolver))
+
+	req := httptest.NewRequest(http.MethodPost, "/sessions/"+session.ID+"/answer", bytes.NewBufferString(`{"answer":"hi"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d, got %d", http.StatusForbidden, w.Code)
+	}
+}
+
+func TestSubmitAnswerRejectsUnknownCaller(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// A session saved without a user must not be open to callers without one
+	session := interview.NewSession("")
+	interview.SaveSession(session)
+	defer interview.DeleteSession(session.ID)
+
+	r := gin.New()
+	userResolver := handlers.UserResolver(services.NewUserService(repository.NewUserMemoryRepo()))
+	r.POST("/sessions/:id/answer", withUser(""), interview.SubmitAnswerHandler(userResolver))
+
+	req := httptest.NewRequest(http.MethodPost, "/sessions/"+session.ID+"/answer", bytes.NewBufferString(`{"answer":"hi"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+	if saved, _ := interview.GetSession(session.ID); len(saved.Answers) != 0 {
+		t.Errorf("Expected no answer to be recorded, got %+v", saved.Answers)
+	}
+}
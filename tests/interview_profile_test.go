@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"altoai_mvp/internal/handlers"
+	"altoai_mvp/internal/repository"
+	"altoai_mvp/internal/services"
+	"altoai_mvp/interview"
+
+	"github.com/gin-gonic/gin"
+)
+
+// useProfileStore gives the test an empty in-memory profile store
+func useProfileStore(t *testing.T) {
+	t.Helper()
+	previous := interview.GetProfileStore()
+	interview.SetProfileStore(interview.NewMemoryProfileStore())
+	t.Cleanup(func() { interview.SetProfileStore(previous) })
+}
+
+func TestProfileValidation(t *testing.T) {
+	cases := []interview.ApplicantProfile{
+		{EstimatedCost: -1},
+		{Funding: []interview.FundingSource{{Source: "", Amount: 1000}}},
+		{Funding: []interview.FundingSource{{Source: "savings", Amount: -5}}},
+		{ProgramStart: "08/20/2025"},
+		{ProgramStart: "2025-08-20", ProgramEnd: "2025-05-01"},
+	}
+	for _, p := range cases {
+		if err := p.Validate(); !errors.Is(err, interview.ErrInvalidProfile) {
+			t.Errorf("Expected ErrInvalidProfile for %+v, got %v", p, err)
+		}
+	}
+
+	valid := interview.ApplicantProfile{
+		ProgramStart: "2025-08-20",
+		ProgramEnd:   "2027-05-15",
+		Funding:      []interview.FundingSource{{Source: "father", Amount: 30000}, {Source: "scholarship", Amount: 15000}},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Expected a valid profile, got %v", err)
+	}
+	if valid.TotalFunding() != 45000 {
+		t.Errorf("Expected total funding of 45000, got %d", valid.TotalFunding())
+	}
+}
+
+func TestProfileInAnalyzerPrompt(t *testing.T) {
+	useProfileStore(t)
+	err := interview.SaveProfile(&interview.ApplicantProfile{
+		UserID:              "alice",
+		University:          "Ohio State University",
+		Program:             "MS Computer Science",
+		EstimatedCost:       52000,
+		Funding:             []interview.FundingSource{{Source: "father", Amount: 40000}, {Source: "scholarship", Amount: 12000}},
+		Sponsor:             "Bakyt Aliev",
+		SponsorRelationship: "father",
+		TestScores:          map[string]string{"TOEFL": "104", "GRE": "320"},
+	})
+	if err != nil {
+		t.Fatalf("SaveProfile failed: %v", err)
+	}
+
+	fake := interview.NewFakeLLMClient()
+	va := interview.NewVisaAnalyzerWithClient(fake)
+	session := interview.NewSession("alice")
+	if _, err := va.AnalyzeAnswerWithSession(session, "University Choice", "Why this university?", "Penn State has the best AI lab."); err != nil {
+		t.Fatalf("AnalyzeAnswerWithSession failed: %v", err)
+	}
+
+	messages := fake.Requests()[0].Messages
+	if len(messages) < 2 || messages[1].Role != "system" {
+		t.Fatalf("Expected the profile as a second system message, got %+v", messages)
+	}
+	for _, want := range []string{
+		"- Admitted university: Ohio State University",
+		"- Estimated cost per year: $52,000",
+		"- Funding per year: father $40,000, scholarship $12,000 (total $52,000)",
+		"- Sponsor: Bakyt Aliev (father)",
+		"- Test scores: GRE 320, TOEFL 104",
+		"score consistency and red_flags down",
+	} {
+		if !strings.Contains(messages[1].Content, want) {
+			t.Errorf("Expected %q in the profile prompt, got %q", want, messages[1].Content)
+		}
+	}
+
+	// Users without a profile are graded as before
+	other := interview.NewSession("bob")
+	if got := va.GetSessionMessages(other); len(got) != 1 {
+		t.Errorf("Expected only the rubric prompt without a profile, got %d messages", len(got))
+	}
+}
+
+func TestApplicantProfileEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	useProfileStore(t)
+
+	profileH := handlers.NewProfileHandler(services.NewUserService(repository.NewUserMemoryRepo()))
+	r := gin.New()
+	r.GET("/profile", withUser("alice"), profileH.Get)
+	r.PUT("/profile", withUser("alice"), profileH.Update)
+
+	var empty struct {
+		Data interview.ApplicantProfile `json:"data"`
+	}
+	w := doJSON(r, http.MethodGet, "/profile", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	decodeJSON(t, w, &empty)
+	if empty.Data.UserID != "alice" || empty.Data.University != "" {
+		t.Errorf("Expected an empty profile, got %+v", empty.Data)
+	}
+
+	if w := doJSON(r, http.MethodPut, "/profile", `{"estimated_cost": -10}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+	}
+
+	// The user ID comes from the token, not the body
+	w = doJSON(r, http.MethodPut, "/profile", `{"user_id": "bob", "university": "Ohio State University", "funding": [{"source": "father", "amount": 40000}]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	saved, err := interview.GetProfile("alice")
+	if err != nil {
+		t.Fatalf("GetProfile failed: %v", err)
+	}
+	if saved.University != "Ohio State University" || saved.TotalFunding() != 40000 {
+		t.Errorf("Expected the saved profile, got %+v", saved)
+	}
+	if _, err := interview.GetProfile("bob"); !errors.Is(err, interview.ErrProfileNotFound) {
+		t.Errorf("Expected no profile for bob, got %v", err)
+	}
+}
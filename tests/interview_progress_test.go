@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"altoai_mvp/internal/handlers"
+	"altoai_mvp/internal/repository"
+	"altoai_mvp/internal/services"
+	"altoai_mvp/interview"
+
+	"github.com/gin-gonic/gin"
+)
+
+// finishedSession builds a finished session whose answers score the given
+// migration_intent and financial_understanding values
+func finishedSession(id string, day int, migration, financial int) *interview.Session {
+	return &interview.Session{
+		ID:        id,
+		UserID:    "alice",
+		Status:    interview.SessionStatusFinished,
+		CreatedAt: time.Date(2025, 1, day, 0, 0, 0, 0, time.UTC),
+		Answers: []interview.Answer{
+			{Analysis: &interview.AnalysisResponse{Scores: interview.AnalysisScores{Criteria: map[string]*int{"migration_intent": &migration}}}},
+			{Analysis: &interview.AnalysisResponse{Scores: interview.AnalysisScores{Criteria: map[string]*int{"financial_understanding": &financial}}}},
+		},
+	}
+}
+
+func TestBuildProgressReport(t *testing.T) {
+	sessions := []*interview.Session{
+		finishedSession("s3", 3, 5, 2),
+		finishedSession("s1", 1, 1, 2),
+		finishedSession("s2", 2, 3, 2),
+		{ID: "active", Status: interview.SessionStatusActive, CreatedAt: time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC)},
+	}
+
+	report := interview.BuildProgressReport(sessions, 2)
+
+	if report.SessionCount != 3 {
+		t.Errorf("Expected 3 finished sessions, got %d", report.SessionCount)
+	}
+	if len(report.Criteria) != 2 {
+		t.Fatalf("Expected 2 scored criteria, got %d", len(report.Criteria))
+	}
+
+	migration := report.Criteria[0]
+	if migration.Criterion != "migration_intent" {
+		t.Fatalf("Expected migration_intent first, got %s", migration.Criterion)
+	}
+	if migration.Points[0].SessionID != "s1" {
+		t.Errorf("Points should be in chronological order, got %s first", migration.Points[0].SessionID)
+	}
+	// Moving averages with window 2: 1, (1+3)/2, (3+5)/2
+	wantMA := []float64{1, 2, 4}
+	for i, want := range wantMA {
+		if migration.Points[i].MovingAverage != want {
+			t.Errorf("Point %d moving average = %.2f, want %.2f", i, migration.Points[i].MovingAverage, want)
+		}
+	}
+	if migration.Change != 3 {
+		t.Errorf("Expected change 3, got %.2f", migration.Change)
+	}
+
+	if len(report.MostImproved) != 1 || report.MostImproved[0] != "migration_intent" {
+		t.Errorf("Expected migration_intent as most improved, got %v", report.MostImproved)
+	}
+	if len(report.StillWeak) != 1 || report.StillWeak[0] != "financial_understanding" {
+		t.Errorf("Expected financial_understanding as still weak, got %v", report.StillWeak)
+	}
+}
+
+func TestBuildProgressReportCountsBestAttempt(t *testing.T) {
+	// The latest attempt scored 1, an earlier one 5
+	session := finishedSession("s1", 1, 1, 4)
+	strong := 5
+	session.Answers[0].Attempts = []interview.AnswerAttempt{
+		{Analysis: &interview.AnalysisResponse{Scores: interview.AnalysisScores{Criteria: map[string]*int{"migration_intent": &strong}}}},
+	}
+
+	report := interview.BuildProgressReport([]*interview.Session{session}, 0)
+	if report.Criteria[0].Latest != 1 || len(report.StillWeak) != 1 || report.StillWeak[0] != "migration_intent" {
+		t.Errorf("Expected the latest attempt to count by default, got %+v / %v", report.Criteria[0], report.StillWeak)
+	}
+
+	session.SummaryAttempt = interview.SummaryAttemptBest
+	report = interview.BuildProgressReport([]*interview.Session{session}, 0)
+	if report.Criteria[0].Latest != 5 || len(report.StillWeak) != 0 {
+		t.Errorf("Expected the best attempt to count, got %+v / %v", report.Criteria[0], report.StillWeak)
+	}
+}
+
+func TestCohortProgressEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	previous := interview.GetSessionStore()
+	defer interview.SetSessionStore(previous)
+	store := interview.NewMemorySessionStore()
+	interview.SetSessionStore(store)
+	for i, id := range []string{"s1", "s2", "s3"} {
+		store.SaveSession(finishedSession(id, i+1, 3, 3))
+	}
+	other := finishedSession("bob1", 1, 1, 1)
+	other.UserID = "bob"
+	store.SaveSession(other)
+
+	interviewH := handlers.NewInterviewHandler(services.NewUserService(repository.NewUserMemoryRepo()))
+	r := gin.New()
+	r.GET("/cohort/progress", interviewH.CohortProgress)
+
+	// One report never mixes students
+	if w := doJSON(r, http.MethodGet, "/cohort/progress", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d without user_id, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	w := doJSON(r, http.MethodGet, "/cohort/progress?user_id=alice&page_size=2", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data handlers.ProgressResponse `json:"data"`
+	}
+	decodeJSON(t, w, &resp)
+	if resp.Data.ProgressReport == nil || resp.Data.SessionCount != 2 || resp.Data.Total != 3 || resp.Data.PageSize != 2 {
+		t.Fatalf("Expected the newest 2 of alice's 3 sessions, got %+v", resp.Data)
+	}
+	if points := resp.Data.Criteria[0].Points; points[0].SessionID != "s2" || points[1].SessionID != "s3" {
+		t.Errorf("Expected the newest sessions on the first page, got %+v", points)
+	}
+}
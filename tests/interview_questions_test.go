@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"strings"
 	"testing"
 	"altoai_mvp/interview"
 )
@@ -235,4 +236,98 @@ func TestHardLevelSelection(t *testing.T) {
 			t.Errorf("Hard level should have exactly 2 questions from category %s, got %d", category, count)
 		}
 	}
-}
\ No newline at end of file
+}
+func TestQuestionBankSchema(t *testing.T) {
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+
+	q, ok := interview.LookupQuestion("financial_capability_1")
+	if !ok {
+		t.Fatal("Expected financial_capability_1 in the question bank")
+	}
+	if q.Category != "Financial Capability" || q.Difficulty == "" || len(q.Tags) == 0 ||
+		len(q.VisaTypes) == 0 || q.OfficerNotes == "" || len(q.RubricHints) == 0 || len(q.FollowupCandidates) == 0 {
+		t.Errorf("Expected the full schema to be loaded, got %+v", q)
+	}
+
+	// Selected questions keep their stable bank IDs
+	for _, selected := range interview.SelectQuestionsForSession("medium") {
+		if bank, ok := interview.LookupQuestion(selected.ID); !ok || bank.Text != selected.Text {
+			t.Errorf("Selected question %s should come from the bank", selected.ID)
+		}
+	}
+}
+
+func TestParseLegacyQuestionBank(t *testing.T) {
+	bank, err := interview.ParseQuestionBank([]byte(`{"Purpose of Study": ["Why the US?", "Why now?"]}`))
+	if err != nil {
+		t.Fatalf("ParseQuestionBank failed: %v", err)
+	}
+	if bank.Version != 1 || len(bank.Questions) != 2 {
+		t.Fatalf("Expected 2 version 1 questions, got %+v", bank)
+	}
+	if bank.Questions[1].ID != "purpose_of_study_2" || bank.Questions[1].Category != "Purpose of Study" {
+		t.Errorf("Expected a stable ID derived from category and position, got %+v", bank.Questions[1])
+	}
+}
+
+func TestParseQuestionBankValidation(t *testing.T) {
+	invalid := map[string]string{
+		"duplicate id":       `{"version": 2, "questions": [{"id": "a", "category": "C", "text": "1?"}, {"id": "a", "category": "C", "text": "2?"}]}`,
+		"missing text":       `{"version": 2, "questions": [{"id": "a", "category": "C"}]}`,
+		"unknown difficulty": `{"version": 2, "questions": [{"id": "a", "category": "C", "text": "1?", "difficulty": "extreme"}]}`,
+		"newer version":      `{"version": 99, "questions": []}`,
+	}
+	for name, data := range invalid {
+		if _, err := interview.ParseQuestionBank([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func questionIDs(questions []interview.Question) []string {
+	ids := make([]string, len(questions))
+	for i, q := range questions {
+		ids[i] = q.ID
+	}
+	return ids
+}
+
+func TestSeededSelection(t *testing.T) {
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+
+	for _, level := range []string{"easy", "medium", "hard"} {
+		first := questionIDs(interview.SelectQuestionsWithSeed(level, 42))
+		second := questionIDs(interview.SelectQuestionsWithSeed(level, 42))
+		if strings.Join(first, ",") != strings.Join(second, ",") {
+			t.Errorf("%s: the same seed should select the same questions, got %v and %v", level, first, second)
+		}
+	}
+
+	// Different seeds should not all agree
+	seen := make(map[string]bool)
+	for seed := int64(0); seed < 20; seed++ {
+		seen[strings.Join(questionIDs(interview.SelectQuestionsWithSeed("hard", seed)), ",")] = true
+	}
+	if len(seen) == 1 {
+		t.Error("Different seeds should select different questions")
+	}
+}
+
+func TestReplaySession(t *testing.T) {
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+
+	original := interview.NewSessionWithLevel("alice", "medium")
+	replay := interview.NewSessionWithSeed("bob", original.Level, original.Seed)
+	if replay.Seed != original.Seed {
+		t.Errorf("Expected seed %d, got %d", original.Seed, replay.Seed)
+	}
+	if got, want := questionIDs(replay.SelectedQuestions), questionIDs(original.SelectedQuestions); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("A replayed session should ask %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,174 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"altoai_mvp/internal/handlers"
+	"altoai_mvp/internal/repository"
+	"altoai_mvp/internal/services"
+	"altoai_mvp/interview"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fastResilience keeps retry delays short enough for tests
+func fastResilience() interview.ResilienceConfig {
+	return interview.ResilienceConfig{
+		MaxAttempts:      3,
+		AttemptTimeout:   time.Second,
+		BaseBackoff:      time.Millisecond,
+		MaxBackoff:       50 * time.Millisecond,
+		FailureThreshold: 2,
+		OpenDuration:     time.Hour,
+	}
+}
+
+func TestResilientClientRetriesTransientErrors(t *testing.T) {
+	fake := interview.NewFakeLLMClient()
+	fake.Enqueue(
+		interview.FakeResponse{Err: &interview.LLMAPIError{Provider: "openai", StatusCode: http.StatusTooManyRequests, RetryAfter: 20 * time.Millisecond}},
+		interview.FakeResponse{Err: &interview.LLMAPIError{Provider: "openai", StatusCode: http.StatusBadGateway}},
+		interview.FakeResponse{Content: "ok"},
+	)
+	c := interview.NewResilientClient(fake, fastResilience())
+
+	start := time.Now()
+	resp, err := c.Complete(context.Background(), interview.LLMRequest{})
+	if err != nil {
+		t.Fatalf("Expected success after retries, got %v", err)
+	}
+	if resp.Content != "ok" || len(fake.Requests()) != 3 {
+		t.Errorf("Expected 'ok' on the 3rd attempt, got %q after %d", resp.Content, len(fake.Requests()))
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("Retry-After should be honored, retried after %s", elapsed)
+	}
+}
+
+func TestResilientClientDoesNotRetryPermanentErrors(t *testing.T) {
+	fake := interview.NewFakeLLMClient()
+	fake.Enqueue(interview.FakeResponse{Err: &interview.LLMAPIError{Provider: "openai", StatusCode: http.StatusUnauthorized}})
+	c := interview.NewResilientClient(fake, fastResilience())
+
+	_, err := c.Complete(context.Background(), interview.LLMRequest{})
+	if err == nil || errors.Is(err, interview.ErrGradingUnavailable) {
+		t.Errorf("401 should fail immediately without marking grading unavailable, got %v", err)
+	}
+	if len(fake.Requests()) != 1 {
+		t.Errorf("Expected a single attempt, got %d", len(fake.Requests()))
+	}
+}
+
+func TestResilientClientCircuitBreaker(t *testing.T) {
+	down := &interview.LLMAPIError{Provider: "openai", StatusCode: http.StatusServiceUnavailable}
+	fake := interview.NewFakeLLMClient()
+	for i := 0; i < 6; i++ {
+		fake.Enqueue(interview.FakeResponse{Err: down})
+	}
+	c := interview.NewResilientClient(fake, fastResilience())
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.Complete(context.Background(), interview.LLMRequest{}); !errors.Is(err, interview.ErrGradingUnavailable) {
+			t.Fatalf("Call %d: expected ErrGradingUnavailable, got %v", i, err)
+		}
+	}
+	sent := len(fake.Requests())
+
+	_, err := c.Complete(context.Background(), interview.LLMRequest{})
+	if !errors.Is(err, interview.ErrCircuitOpen) || !errors.Is(err, interview.ErrGradingUnavailable) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if len(fake.Requests()) != sent {
+		t.Error("Open breaker should not call the provider")
+	}
+}
+
+func TestResilientClientAttemptTimeout(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			time.Sleep(200 * time.Millisecond)
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"late but fine"}}]}`))
+	}))
+	defer srv.Close()
+
+	cfg := fastResilience()
+	cfg.AttemptTimeout = 50 * time.Millisecond
+	c := interview.NewResilientClient(interview.NewOpenAIClient("sk-test", srv.URL, "", nil), cfg)
+
+	resp, err := c.Complete(context.Background(), interview.LLMRequest{})
+	if err != nil {
+		t.Fatalf("Expected the second attempt to succeed, got %v", err)
+	}
+	if resp.Content != "late but fine" {
+		t.Errorf("Unexpected content %q", resp.Content)
+	}
+}
+
+func TestParseRetryAfterHeader(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	_, err := interview.NewOpenAIClient("sk-test", srv.URL, "", nil).Complete(context.Background(), interview.LLMRequest{})
+	var apiErr *interview.LLMAPIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected *LLMAPIError, got %v", err)
+	}
+	if apiErr.RetryAfter != 7*time.Second || !apiErr.Temporary() {
+		t.Errorf("Expected a temporary error with RetryAfter 7s, got %+v", apiErr)
+	}
+}
+
+func TestChatReportsPendingGrading(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+
+	fake := interview.NewFakeLLMClient()
+	fake.Enqueue(interview.FakeResponse{Err: interview.ErrCircuitOpen})
+	previous := interview.GetAnalyzer()
+	defer interview.SetAnalyzer(previous)
+	interview.SetAnalyzer(interview.NewVisaAnalyzerWithClient(fake))
+
+	session := interview.NewSessionWithLevel("alice", "easy")
+	interview.SaveSession(session)
+
+	chatH := handlers.NewChatHandler(services.NewUserService(repository.NewUserMemoryRepo()))
+	r := gin.New()
+	r.POST("/chat", withUser("alice"), chatH.Chat)
+
+	body := `{"session_id":"` + session.ID + `","messages":[{"role":"user","content":"I want to study computer science."}]}`
+	req := httptest.NewRequest(http.MethodPost, "/chat", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var resp struct {
+		Data handlers.ChatResponse `json:"data"`
+	}
+	decodeJSON(t, w, &resp)
+	if resp.Data.GradingStatus != interview.GradingStatusPending || resp.Data.GradingMessage == "" {
+		t.Errorf("Expected pending grading with a message, got %q / %q", resp.Data.GradingStatus, resp.Data.GradingMessage)
+	}
+
+	saved, _ := interview.GetSession(session.ID)
+	if len(saved.Answers) != 1 || saved.Answers[0].GradingStatus != interview.GradingStatusPending {
+		t.Errorf("Answer should be saved as pending, got %+v", saved.Answers)
+	}
+}
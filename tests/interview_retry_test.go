@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"testing"
+
+	"altoai_mvp/internal/handlers"
+	"altoai_mvp/internal/repository"
+	"altoai_mvp/internal/services"
+	"altoai_mvp/interview"
+
+	"github.com/gin-gonic/gin"
+)
+
+// communicationAnalysis is a valid analysis scoring only communication
+// quality, which places it at 25% per point above 1
+func communicationAnalysis(score int) string {
+	return fmt.Sprintf(`{
+		"scores": {"communication_quality": %d, "total_score": %d},
+		"classification": "Average",
+		"feedback": {"overall": "Clear.", "by_criterion": {}, "improvements": ["Name the program."]},
+		"improved_version": ""
+	}`, score, score)
+}
+
+func communicationScore(score int) *interview.AnalysisResponse {
+	return &interview.AnalysisResponse{Scores: interview.AnalysisScores{Criteria: map[string]*int{"communication_quality": &score}, TotalScore: score}}
+}
+
+func TestSummaryCountsBestOrLatestAttempt(t *testing.T) {
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+	previous := interview.GetAnalyzer()
+	defer interview.SetAnalyzer(previous)
+	interview.SetAnalyzer(interview.NewVisaAnalyzerWithClient(interview.NewFakeLLMClient()))
+
+	if _, err := interview.ParseSummaryAttempt("worst"); !errors.Is(err, interview.ErrUnknownSummaryAttempt) {
+		t.Errorf("Expected ErrUnknownSummaryAttempt, got %v", err)
+	}
+
+	session := interview.NewSessionWithOptions("alice", interview.SessionOptions{Level: "easy", SummaryAttempt: "best"})
+	q := session.SelectedQuestions[0]
+	session.Answers = []interview.Answer{{QuestionID: q.ID, QuestionText: q.Text, Text: "I study.", Analysis: communicationScore(5)}}
+	if _, err := session.RecordAttempt(interview.Answer{QuestionID: "unknown", Text: "Hi."}); !errors.Is(err, interview.ErrQuestionNotAnswered) {
+		t.Errorf("Expected ErrQuestionNotAnswered, got %v", err)
+	}
+	answer, err := session.RecordAttempt(interview.Answer{QuestionID: q.ID, Text: "Um.", Analysis: communicationScore(2)})
+	if err != nil {
+		t.Fatalf("RecordAttempt failed: %v", err)
+	}
+	if answer.AttemptNumber() != 2 || len(answer.Attempts) != 1 || answer.Attempts[0].Text != "I study." || answer.Text != "Um." {
+		t.Errorf("Expected the first attempt in the history and the second as the answer, got %+v", answer)
+	}
+	if delta, ok := interview.AttemptScoreDelta(*answer); !ok || math.Abs(delta+75) > 0.01 {
+		t.Errorf("Expected a delta of -75, got %.2f (%v)", delta, ok)
+	}
+
+	summary, err := interview.GenerateSessionSummary(session)
+	if err != nil {
+		t.Fatalf("GenerateSessionSummary failed: %v", err)
+	}
+	if summary.ScorePercent != 100 {
+		t.Errorf("Expected the best attempt to count, got %.1f%%", summary.ScorePercent)
+	}
+
+	session.SummaryAttempt = interview.SummaryAttemptLatest
+	if summary, _ = interview.GenerateSessionSummary(session); summary.ScorePercent != 25 {
+		t.Errorf("Expected the latest attempt to count, got %.1f%%", summary.ScorePercent)
+	}
+
+	// Only the latest attempt is graded in the background, so a pending one cannot be replaced
+	answer.GradingStatus = interview.GradingStatusPending
+	if _, err := session.RecordAttempt(interview.Answer{QuestionID: q.ID, Text: "Again."}); !errors.Is(err, interview.ErrAnswerPending) {
+		t.Errorf("Expected ErrAnswerPending, got %v", err)
+	}
+}
+
+func TestChatRetryQuestion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+	// 75% needs no follow-up, so only the gradings reach the model
+	fake := interview.NewFakeLLMClient(communicationAnalysis(4), communicationAnalysis(5))
+	previous := interview.GetAnalyzer()
+	defer interview.SetAnalyzer(previous)
+	interview.SetAnalyzer(interview.NewVisaAnalyzerWithClient(fake))
+
+	previousReviews := interview.GetReviewStore()
+	defer interview.SetReviewStore(previousReviews)
+	reviews := interview.NewMemoryReviewStore()
+	interview.SetReviewStore(reviews)
+
+	session := interview.NewSessionWithLevel("alice", "easy")
+	interview.SaveSession(session)
+	first, second := session.SelectedQuestions[0], session.SelectedQuestions[1]
+
+	chatH := handlers.NewChatHandler(services.NewUserService(repository.NewUserMemoryRepo()))
+	r := gin.New()
+	r.POST("/chat", withUser("alice"), chatH.Chat)
+	chat := func(extra, message string) (*handlers.ChatResponse, int) {
+		body := `{"session_id":"` + session.ID + `"` + extra + `,"messages":[{"role":"user","content":"` + message + `"}]}`
+		w := doJSON(r, http.MethodPost, "/chat", body)
+		var resp struct {
+			Data handlers.ChatResponse `json:"data"`
+		}
+		if w.Code == http.StatusOK {
+			decodeJSON(t, w, &resp)
+		}
+		return &resp.Data, w.Code
+	}
+
+	if _, code := chat(`,"summary_attempt":"worst"`, "Hello."); code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an unknown summary attempt, got %d", http.StatusBadRequest, code)
+	}
+	if _, code := chat(`,"retry_question_id":"`+second.ID+`"`, "Hello."); code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for retrying an unanswered question, got %d", http.StatusBadRequest, code)
+	}
+
+	resp, code := chat("", "I want to study computer science.")
+	if code != http.StatusOK || resp.QuestionID != second.ID || resp.Attempt != 0 {
+		t.Fatalf("Expected the second question after the first answer, got %d %+v", code, resp)
+	}
+
+	resp, code = chat(`,"retry_question_id":"`+first.ID+`"`, "I want a master's in computer science at Ohio State.")
+	if code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, code)
+	}
+	if resp.Attempt != 2 || resp.ScoreDelta == nil || math.Abs(*resp.ScoreDelta-25) > 0.01 {
+		t.Errorf("Expected attempt 2 with a delta of 25, got %d / %v", resp.Attempt, resp.ScoreDelta)
+	}
+	if resp.QuestionID != second.ID || resp.Content != second.Text || resp.Analysis == nil {
+		t.Errorf("Expected the retry to be graded and the session to stay on the second question, got %+v", resp)
+	}
+
+	// The retry is not a second review of the question
+	if items, _ := reviews.ListReviews("alice"); len(items) != 1 || items[0].Repetitions != 1 {
+		t.Errorf("Expected one review of the first question, got %+v", items)
+	}
+
+	saved, _ := interview.GetSession(session.ID)
+	if saved.QuestionIndex != 1 || len(saved.Answers) != 1 {
+		t.Fatalf("Expected one answer and the session on the second question, got %d answers at %d", len(saved.Answers), saved.QuestionIndex)
+	}
+	answer := saved.Answers[0]
+	if len(answer.Attempts) != 1 || answer.Attempts[0].Analysis == nil || answer.Attempts[0].Text != "I want to study computer science." {
+		t.Errorf("Expected the first attempt with its analysis in the history, got %+v", answer.Attempts)
+	}
+	if answer.Text != "I want a master's in computer science at Ohio State." {
+		t.Errorf("Expected the retry as the latest attempt, got %q", answer.Text)
+	}
+}
@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"altoai_mvp/interview"
+)
+
+func TestReviewSchedule(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var item interview.ReviewItem
+
+	// Passing answers push the question further out each time
+	intervals := []int{1, 6, 16}
+	for i, want := range intervals {
+		item.Review(5, now)
+		if item.IntervalDays != want {
+			t.Errorf("Review %d: expected an interval of %d days, got %d", i+1, want, item.IntervalDays)
+		}
+	}
+	if !item.DueAt.Equal(now.Add(16 * 24 * time.Hour)) {
+		t.Errorf("Expected the question due in 16 days, got %v", item.DueAt)
+	}
+	ease := item.EaseFactor
+
+	// A failed answer starts over and makes the question harder
+	item.Review(1, now)
+	if item.Repetitions != 0 || item.IntervalDays != 1 {
+		t.Errorf("A failed answer should reset the schedule, got %+v", item)
+	}
+	if item.EaseFactor >= ease {
+		t.Errorf("A failed answer should lower the ease factor, got %.2f from %.2f", item.EaseFactor, ease)
+	}
+	for i := 0; i < 10; i++ {
+		item.Review(0, now)
+	}
+	if item.EaseFactor < 1.3 {
+		t.Errorf("The ease factor should not drop below 1.3, got %.2f", item.EaseFactor)
+	}
+}
+
+func TestRecordReview(t *testing.T) {
+	previous := interview.GetReviewStore()
+	defer interview.SetReviewStore(previous)
+	store := interview.NewMemoryReviewStore()
+	interview.SetReviewStore(store)
+
+	two, five := 2, 5
+	weak := &interview.AnalysisResponse{Scores: interview.AnalysisScores{Criteria: map[string]*int{"academic_credibility": &two, "consistency": &two}}}
+	strong := &interview.AnalysisResponse{Scores: interview.AnalysisScores{Criteria: map[string]*int{"academic_credibility": &five}}}
+	q := interview.Question{ID: "academic_background_1", Category: "Academic Background"}
+
+	interview.RecordReview("alice", q, strong)
+	interview.RecordReview("alice", q, weak)
+	interview.RecordReview("alice", interview.Question{ID: "q1f", IsFollowup: true}, weak)
+
+	items, _ := store.ListReviews("alice")
+	if len(items) != 1 {
+		t.Fatalf("Expected one scheduled question, follow-ups excluded, got %+v", items)
+	}
+	if items[0].LastQuality != 2 || items[0].Repetitions != 0 {
+		t.Errorf("Expected the weak answer to reset the schedule, got %+v", items[0])
+	}
+}
+
+func TestTargetedSelection(t *testing.T) {
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+	previousReviews := interview.GetReviewStore()
+	previousSessions := interview.GetSessionStore()
+	defer interview.SetReviewStore(previousReviews)
+	defer interview.SetSessionStore(previousSessions)
+	reviews := interview.NewMemoryReviewStore()
+	interview.SetReviewStore(reviews)
+	interview.SetSessionStore(interview.NewMemorySessionStore())
+
+	level, err := interview.LookupLevel("targeted")
+	if err != nil {
+		t.Fatalf("LookupLevel failed: %v", err)
+	}
+
+	// One question was answered poorly and is overdue; the rest of the bank was mastered recently
+	now := time.Now()
+	data, err := os.ReadFile("../interview/questions.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bank, err := interview.ParseQuestionBank(data)
+	if err != nil {
+		t.Fatalf("ParseQuestionBank failed: %v", err)
+	}
+	for _, q := range bank.Questions {
+		item := interview.ReviewItem{UserID: "alice", QuestionID: q.ID, Category: q.Category}
+		item.Review(5, now)
+		reviews.SaveReview(item)
+	}
+	weak := interview.ReviewItem{UserID: "alice", QuestionID: "financial_capability_1", Category: "Financial Capability"}
+	weak.Review(1, now.Add(-10*24*time.Hour))
+	reviews.SaveReview(weak)
+
+	hits := 0
+	for seed := int64(0); seed < 20; seed++ {
+		selected := interview.SelectQuestionsForUser("alice", interview.SessionOptions{Level: "targeted", Seed: &seed})
+		if len(selected) != level.Questions {
+			t.Fatalf("Expected %d questions, got %d", level.Questions, len(selected))
+		}
+		for _, q := range selected {
+			if q.ID == "financial_capability_1" {
+				hits++
+			}
+		}
+	}
+	if hits < 18 {
+		t.Errorf("The overdue weak question should almost always resurface, got %d/20", hits)
+	}
+
+	// The same seed and history select the same questions
+	seed := int64(7)
+	first := questionIDs(interview.SelectQuestionsForUser("alice", interview.SessionOptions{Level: "targeted", Seed: &seed}))
+	second := questionIDs(interview.SelectQuestionsForUser("alice", interview.SessionOptions{Level: "targeted", Seed: &seed}))
+	if len(first) != len(second) {
+		t.Fatalf("Expected the same selection, got %v and %v", first, second)
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("Expected the same selection, got %v and %v", first, second)
+		}
+	}
+}
+
+// filterRecorder records the filters sessions are listed with
+type filterRecorder struct {
+	interview.SessionStore
+	filters []interview.SessionFilter
+}
+
+func (r *filterRecorder) ListSessions(filter interview.SessionFilter) ([]*interview.Session, int, error) {
+	r.filters = append(r.filters, filter)
+	return r.SessionStore.ListSessions(filter)
+}
+
+func TestTargetedSelectionReadsRecentSessions(t *testing.T) {
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+	previous := interview.GetSessionStore()
+	defer interview.SetSessionStore(previous)
+	store := &filterRecorder{SessionStore: interview.NewMemorySessionStore()}
+	interview.SetSessionStore(store)
+
+	for day := 1; day <= 40; day++ {
+		interview.SaveSession(finishedSession(fmt.Sprintf("s%d", day), day, 5, 2))
+	}
+
+	seed := int64(3)
+	interview.SelectQuestionsForUser("alice", interview.SessionOptions{Level: "targeted", Seed: &seed})
+	if len(store.filters) != 1 {
+		t.Fatalf("Expected one session listing, got %d", len(store.filters))
+	}
+	if f := store.filters[0]; f.Limit <= 0 || f.Limit >= 40 || f.UserID != "alice" {
+		t.Errorf("Expected a listing of the user's newest sessions only, got %+v", f)
+	}
+}
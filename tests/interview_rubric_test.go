@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"altoai_mvp/interview"
+)
+
+// writeRubric writes questions.json and the given rubric.json to a temp dir and loads them
+func writeRubric(t *testing.T, rubric string) {
+	t.Helper()
+	questions, err := os.ReadFile("../interview/questions.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "questions.json"), questions, 0o644)
+	os.WriteFile(filepath.Join(dir, "rubric.json"), []byte(rubric), 0o644)
+	t.Cleanup(func() { interview.LoadQuestions("../interview/questions.json") })
+	if err := interview.LoadQuestions(filepath.Join(dir, "questions.json")); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+}
+
+func TestLoadRubric(t *testing.T) {
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+
+	keys := strings.Join(interview.CriterionKeys(), ",")
+	if keys != "migration_intent,financial_understanding,academic_credibility,specificity_research,consistency,communication_quality,red_flags" {
+		t.Errorf("Expected the seven default criteria in order, got %s", keys)
+	}
+	c, ok := interview.LookupCriterion("financial_understanding")
+	if !ok || !c.AppliesTo("Financial Capability") || c.AppliesTo("University Choice") {
+		t.Errorf("Expected financial understanding to apply to Financial Capability only, got %+v", c)
+	}
+	if c, _ := interview.LookupCriterion("red_flags"); !c.AppliesTo("University Choice") {
+		t.Error("Criteria without categories should apply everywhere")
+	}
+}
+
+func TestCustomRubricCriterion(t *testing.T) {
+	writeRubric(t, `{"criteria": [
+		{"key": "communication_quality", "label": "Communication quality", "scale": {"min": 1, "max": 5}, "weight": 1,
+			"prompt": ["5: Clear and confident"]},
+		{"key": "document_readiness", "label": "Document readiness", "area": "Ready documents", "red_flag": "Missing documents",
+			"scale": {"min": 0, "max": 10}, "weight": 2, "categories": ["Financial Capability"],
+			"prompt": ["10: Names every document and the amounts on it"]}
+	]}`)
+
+	fake := interview.NewFakeLLMClient(`{
+		"scores": {"communication_quality": 4, "document_readiness": 9, "total_score": 13},
+		"classification": "Good",
+		"feedback": {"overall": "Well prepared.", "by_criterion": {"document_readiness": "Knows the I-20 amounts."}, "improvements": ["Bring bank letters."]}
+	}`)
+	va := interview.NewVisaAnalyzerWithClient(fake)
+	session := interview.NewSession("alice")
+	analysis, err := va.AnalyzeAnswerWithSession(session, "Financial Capability", "Who pays?", "My father, $40,000 a year.")
+	if err != nil {
+		t.Fatalf("AnalyzeAnswerWithSession failed: %v", err)
+	}
+	if score := analysis.Scores.Score("document_readiness"); score == nil || *score != 9 {
+		t.Errorf("Expected document_readiness 9, got %v", score)
+	}
+	if analysis.Feedback.ByCriterion["document_readiness"] == "" {
+		t.Error("Expected feedback for the new criterion")
+	}
+
+	prompt := fake.Requests()[0].Messages[0].Content
+	for _, want := range []string{"document_readiness (0-10 or null)", "10: Names every document", "- Financial Capability: Evaluate ONLY communication_quality, document_readiness."} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected the prompt to contain %q", want)
+		}
+	}
+
+	// Criteria outside the rubric and scores off the scale are rejected
+	if _, problems := interview.ValidateAnalysis(`{"scores": {"migration_intent": 3, "document_readiness": 11, "communication_quality": 4}, "feedback": {"overall": "x", "improvements": ["y"]}}`); len(problems) != 2 {
+		t.Errorf("Expected two problems, got %v", problems)
+	}
+
+	// Review quality places a score on its own scale: 5 of 10 is middling, not top marks
+	previous := interview.GetReviewStore()
+	defer interview.SetReviewStore(previous)
+	store := interview.NewMemoryReviewStore()
+	interview.SetReviewStore(store)
+	five := 5
+	interview.RecordReview("alice", interview.Question{ID: "financial_capability_1", Category: "Financial Capability"},
+		&interview.AnalysisResponse{Scores: interview.AnalysisScores{Criteria: map[string]*int{"document_readiness": &five}}})
+	if items, _ := store.ListReviews("alice"); len(items) != 1 || items[0].LastQuality != 3 {
+		t.Errorf("Expected quality 3 for 5 on a 0-10 scale, got %+v", items)
+	}
+}
+
+func TestAnalysisScoresDecodeOldSessions(t *testing.T) {
+	stored := `{"migration_intent": 4, "financial_understanding": null, "retired_criterion": 2, "total_score": 6}`
+	var scores interview.AnalysisScores
+	if err := json.Unmarshal([]byte(stored), &scores); err != nil {
+		t.Fatalf("Failed to decode stored scores: %v", err)
+	}
+	if scores.TotalScore != 6 || *scores.Score("migration_intent") != 4 || *scores.Score("retired_criterion") != 2 {
+		t.Errorf("Unexpected scores %+v", scores)
+	}
+	if scores.Score("financial_understanding") != nil {
+		t.Error("Expected financial_understanding to stay null")
+	}
+
+	data, _ := json.Marshal(scores)
+	var roundTrip map[string]any
+	json.Unmarshal(data, &roundTrip)
+	if _, ok := roundTrip["financial_understanding"]; !ok || roundTrip["total_score"] != float64(6) {
+		t.Errorf("Expected the flat format to round-trip, got %s", data)
+	}
+}
+
+func TestRubricValidation(t *testing.T) {
+	scale := interview.ScoreScale{Min: 1, Max: 5}
+	cases := map[string]interview.Rubric{
+		"no criteria":   {},
+		"duplicate key": {Criteria: []interview.Criterion{{Key: "a", Label: "A", Scale: scale, Weight: 1}, {Key: "a", Label: "A", Scale: scale, Weight: 1}}},
+		"total key":     {Criteria: []interview.Criterion{{Key: "total_score", Label: "Total", Scale: scale, Weight: 1}}},
+		"flat scale":    {Criteria: []interview.Criterion{{Key: "a", Label: "A", Scale: interview.ScoreScale{Min: 3, Max: 3}, Weight: 1}}},
+		"no weight":     {Criteria: []interview.Criterion{{Key: "a", Label: "A", Scale: scale}}},
+	}
+	for name, r := range cases {
+		if err := r.Validate(); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
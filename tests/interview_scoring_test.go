@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"altoai_mvp/interview"
+)
+
+func TestAnswerPercentWeighsCriteria(t *testing.T) {
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+
+	// Migration intent weighs 2 and communication 1: (2*0 + 1*1) / 3
+	one, five := 1, 5
+	scores := interview.AnalysisScores{Criteria: map[string]*int{"migration_intent": &one, "communication_quality": &five}}
+	percent, ok := interview.AnswerPercent(scores)
+	if !ok || math.Abs(percent-100.0/3) > 0.01 {
+		t.Errorf("Expected 33.3%%, got %.2f (%v)", percent, ok)
+	}
+	if band := interview.GradeBandFor(percent); band.Classification != "Weak" || band.Grade != "D" {
+		t.Errorf("Expected Weak/D, got %+v", band)
+	}
+
+	if _, ok := interview.AnswerPercent(interview.AnalysisScores{}); ok {
+		t.Error("Expected no score without graded criteria")
+	}
+}
+
+func TestSessionPercentWeighsCategories(t *testing.T) {
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+
+	two, five := 2, 5
+	analyses := []interview.AnalysisRecord{
+		{Category: "Immigration Intent", Analysis: interview.AnalysisResponse{Scores: interview.AnalysisScores{Criteria: map[string]*int{"migration_intent": &two}}}},
+		{Category: "University Choice", Analysis: interview.AnalysisResponse{Scores: interview.AnalysisScores{Criteria: map[string]*int{"specificity_research": &five}}}},
+	}
+	// Immigration Intent weighs 1.5: (1.5*25 + 1*100) / 2.5
+	percent, ok := interview.SessionPercent(analyses)
+	if !ok || math.Abs(percent-55) > 0.01 {
+		t.Errorf("Expected 55%%, got %.2f (%v)", percent, ok)
+	}
+
+	summary, err := interview.NewVisaAnalyzerWithClient(nil).GenerateSessionSummary(analyses)
+	if err != nil {
+		t.Fatalf("GenerateSessionSummary failed: %v", err)
+	}
+	band := interview.GradeBandFor(percent)
+	if summary.OverallGrade != "C" || summary.Recommendation != band.Recommendation {
+		t.Errorf("Expected the summary to use the Average band, got %s: %s", summary.OverallGrade, summary.Recommendation)
+	}
+}
+
+func TestCustomGradeBands(t *testing.T) {
+	writeRubric(t, `{
+		"criteria": [{"key": "communication_quality", "label": "Communication quality", "scale": {"min": 1, "max": 5}, "weight": 1}],
+		"grade_bands": [
+			{"classification": "Excellent", "grade": "A", "min_percent": 95},
+			{"classification": "Weak", "grade": "F", "min_percent": 0}
+		]
+	}`)
+
+	fake := interview.NewFakeLLMClient(`{
+		"scores": {"communication_quality": 4, "total_score": 4},
+		"classification": "Good",
+		"feedback": {"overall": "Clear.", "by_criterion": {}, "improvements": ["Slow down."]}
+	}`)
+	analysis, err := interview.NewVisaAnalyzerWithClient(fake).AnalyzeAnswer("Why this school?", "Its robotics lab.")
+	if err != nil {
+		t.Fatalf("AnalyzeAnswer failed: %v", err)
+	}
+	if analysis.Classification != "Weak" {
+		t.Errorf("75%% is below the custom Excellent band, expected Weak, got %s", analysis.Classification)
+	}
+	if band := interview.GradeBandFor(100); band.Grade != "A" {
+		t.Errorf("Expected 100%% to be A, got %+v", band)
+	}
+
+	bad := interview.Rubric{
+		Criteria:   interview.RubricCriteria(),
+		GradeBands: []interview.GradeBand{{Classification: "Good", Grade: "B", MinPercent: 50}},
+	}
+	if err := bad.Validate(); err == nil {
+		t.Error("Expected bands that do not reach 0 to be rejected")
+	}
+	bad.GradeBands = []interview.GradeBand{{Classification: "Stellar", Grade: "A", MinPercent: 0}}
+	if err := bad.Validate(); err == nil {
+		t.Error("Expected unknown classifications to be rejected")
+	}
+}
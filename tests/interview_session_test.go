@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"errors"
 	"testing"
 	"time"
 	"altoai_mvp/interview"
@@ -85,3 +86,51 @@ func TestSessionAnswers(t *testing.T) {
 	}
 }
 
+
+func TestMemorySessionStore(t *testing.T) {
+	store := interview.NewMemorySessionStore()
+
+	if _, err := store.GetSession("missing"); !errors.Is(err, interview.ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound, got %v", err)
+	}
+
+	session := interview.NewSession("store-user")
+	if err := store.SaveSession(session); err != nil {
+		t.Fatalf("SaveSession failed: %v", err)
+	}
+
+	retrieved, err := store.GetSession(session.ID)
+	if err != nil {
+		t.Fatalf("GetSession failed: %v", err)
+	}
+	if retrieved.UserID != "store-user" {
+		t.Errorf("Expected UserID 'store-user', got %s", retrieved.UserID)
+	}
+
+	// A save racing a delete must not bring the session back
+	if err := store.DeleteSession(session.ID); err != nil {
+		t.Fatalf("DeleteSession failed: %v", err)
+	}
+	if err := store.SaveSession(session); !errors.Is(err, interview.ErrSessionDeleted) {
+		t.Errorf("Expected ErrSessionDeleted, got %v", err)
+	}
+	if _, err := store.GetSession(session.ID); !errors.Is(err, interview.ErrSessionNotFound) {
+		t.Errorf("Expected the deleted session to stay deleted, got %v", err)
+	}
+}
+
+func TestSetSessionStore(t *testing.T) {
+	previous := interview.GetSessionStore()
+	defer interview.SetSessionStore(previous)
+
+	store := interview.NewMemorySessionStore()
+	interview.SetSessionStore(store)
+
+	session := interview.NewSession("swap-user")
+	if err := interview.SaveSession(session); err != nil {
+		t.Fatalf("SaveSession failed: %v", err)
+	}
+	if _, err := store.GetSession(session.ID); err != nil {
+		t.Errorf("Session should be saved in the configured store: %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"altoai_mvp/internal/handlers"
+	"altoai_mvp/internal/repository"
+	"altoai_mvp/internal/services"
+	"altoai_mvp/interview"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestVisaTypeSelection(t *testing.T) {
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+
+	for _, v := range interview.VisaProfiles() {
+		session := interview.NewSessionWithOptions("alice", interview.SessionOptions{VisaType: v.Type})
+		if session.VisaType != v.Type {
+			t.Errorf("Expected visa type %s, got %q", v.Type, session.VisaType)
+		}
+		if len(session.SelectedQuestions) == 0 {
+			t.Errorf("%s: expected questions to be selected", v.Type)
+		}
+		for _, q := range session.SelectedQuestions {
+			if !q.AppliesTo(v.Type) {
+				t.Errorf("%s: question %s is for %v", v.Type, q.ID, q.VisaTypes)
+			}
+		}
+	}
+
+	// Lookups are case-insensitive and default to F-1
+	if v, err := interview.LookupVisaType("h-1b"); err != nil || v.Type != interview.VisaH1B {
+		t.Errorf("Expected H-1B, got %+v (%v)", v, err)
+	}
+	if v, err := interview.LookupVisaType(""); err != nil || v.Type != interview.DefaultVisaType {
+		t.Errorf("Expected the default visa, got %+v (%v)", v, err)
+	}
+	if _, err := interview.LookupVisaType("E-2"); !errors.Is(err, interview.ErrUnknownVisaType) {
+		t.Errorf("Expected ErrUnknownVisaType, got %v", err)
+	}
+}
+
+func TestVisaRubricPrompt(t *testing.T) {
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+
+	// The model scores migration intent, which H-1B does not grade
+	fake := interview.NewFakeLLMClient(`{
+		"scores": {"migration_intent": 2, "financial_understanding": null, "academic_credibility": null,
+			"specificity_research": 4, "consistency": null, "communication_quality": 4, "red_flags": 4, "total_score": 14},
+		"classification": "Good",
+		"feedback": {"overall": "Clear description of the role.", "by_criterion": {}, "improvements": ["Name the client."]}
+	}`)
+	va := interview.NewVisaAnalyzerWithClient(fake)
+	session := interview.NewSessionWithOptions("alice", interview.SessionOptions{VisaType: interview.VisaH1B})
+	q := session.SelectedQuestions[0]
+
+	analysis, err := va.AnalyzeAnswerWithSession(session, q.Category, q.Text, "I will build payment APIs for the client team.")
+	if err != nil {
+		t.Fatalf("AnalyzeAnswerWithSession failed: %v", err)
+	}
+	if analysis.Scores.Score("migration_intent") != nil {
+		t.Errorf("Expected migration intent to be dropped for H-1B, got %d", *analysis.Scores.Score("migration_intent"))
+	}
+	if analysis.Scores.TotalScore != 12 {
+		t.Errorf("Expected the total without migration intent (12), got %d", analysis.Scores.TotalScore)
+	}
+
+	prompt := fake.Requests()[0].Messages[0].Content
+	if !strings.Contains(prompt, "H-1B visa consular officer") || strings.Contains(prompt, "F-1") {
+		t.Errorf("Expected an H-1B rubric prompt, got %q", prompt[:120])
+	}
+	if !strings.Contains(prompt, "- Job Role: Evaluate ONLY") {
+		t.Error("Expected the H-1B categories in the rubric prompt")
+	}
+}
+
+func TestChatRejectsUnknownVisaType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+
+	chatH := handlers.NewChatHandler(services.NewUserService(repository.NewUserMemoryRepo()))
+	r := gin.New()
+	r.POST("/chat", withUser("alice"), chatH.Chat)
+
+	if w := doJSON(r, http.MethodPost, "/chat", `{"visa_type":"E-2"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+	}
+}
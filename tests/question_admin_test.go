@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"altoai_mvp/internal/handlers"
+	"altoai_mvp/internal/middleware"
+	"altoai_mvp/internal/models"
+	"altoai_mvp/interview"
+
+	"github.com/gin-gonic/gin"
+)
+
+// useQuestionStore gives the test a fresh bank store and restores questions.json afterwards
+func useQuestionStore(t *testing.T) {
+	t.Helper()
+	if err := interview.LoadQuestions("../interview/questions.json"); err != nil {
+		t.Fatalf("LoadQuestions failed: %v", err)
+	}
+	interview.SetQuestionStore(interview.NewMemoryQuestionStore())
+	t.Cleanup(func() {
+		interview.SetQuestionStore(interview.NewMemoryQuestionStore())
+		interview.LoadQuestions("../interview/questions.json")
+	})
+}
+
+func questionAdminRouter(role models.Role) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := handlers.NewQuestionAdminHandler()
+	r := gin.New()
+	admin := r.Group("/admin", withRole("someone", role), middleware.RequireRole(models.RoleAdmin))
+	admin.GET("/questions", h.List)
+	admin.POST("/questions", h.Create)
+	admin.PUT("/questions/order", h.Reorder)
+	admin.PUT("/questions/:id", h.Update)
+	admin.POST("/questions/:id/retire", h.Retire)
+	return r
+}
+
+func doJSON(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestQuestionAdminRequiresAdmin(t *testing.T) {
+	useQuestionStore(t)
+
+	if w := doJSON(questionAdminRouter(models.RoleCoach), http.MethodGet, "/admin/questions", ""); w.Code != http.StatusForbidden {
+		t.Errorf("Non-admins should get %d, got %d", http.StatusForbidden, w.Code)
+	}
+	if w := doJSON(questionAdminRouter(models.RoleAdmin), http.MethodGet, "/admin/questions", ""); w.Code != http.StatusOK {
+		t.Errorf("Admins should get %d, got %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestQuestionAdminEdits(t *testing.T) {
+	useQuestionStore(t)
+	r := questionAdminRouter(models.RoleAdmin)
+
+	// A session in progress keeps its questions whatever happens to the bank
+	session := interview.NewSessionWithLevel("alice", "easy")
+	inFlight := session.SelectedQuestions[0]
+
+	w := doJSON(r, http.MethodPost, "/admin/questions", `{"category":"Purpose of Study","text":"What will you miss most about home?","difficulty":"easy"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var created struct {
+		Data interview.BankQuestion `json:"data"`
+	}
+	decodeJSON(t, w, &created)
+	if created.Data.ID != "purpose_of_study_5" || created.Data.Position != 4 {
+		t.Errorf("Expected a generated ID at the end of the category, got %+v", created.Data)
+	}
+	if _, ok := interview.LookupQuestion(created.Data.ID); !ok {
+		t.Error("New sessions should see the created question right away")
+	}
+
+	if w := doJSON(r, http.MethodPost, "/admin/questions", `{"id":"purpose_of_study_5","category":"Purpose of Study","text":"Again?"}`); w.Code != http.StatusConflict {
+		t.Errorf("Duplicate IDs should get %d, got %d", http.StatusConflict, w.Code)
+	}
+
+	w = doJSON(r, http.MethodPut, "/admin/questions/"+inFlight.ID, `{"category":"`+inFlight.Category+`","text":"Reworded?"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if q, _ := interview.LookupQuestion(inFlight.ID); q.Text != "Reworded?" {
+		t.Error("The bank should hold the new text")
+	}
+	if session.SelectedQuestions[0].Text != inFlight.Text {
+		t.Error("Sessions in progress should keep the text they were asked")
+	}
+
+	if w := doJSON(r, http.MethodPost, "/admin/questions/"+created.Data.ID+"/retire", ""); w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if _, ok := interview.LookupQuestion(created.Data.ID); ok {
+		t.Error("Retired questions should not be selectable")
+	}
+	if w := doJSON(r, http.MethodPost, "/admin/questions/nope/retire", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Unknown IDs should get %d, got %d", http.StatusNotFound, w.Code)
+	}
+
+	if w := doJSON(r, http.MethodPost, "/admin/questions", `{"category":"Purpose of Trip","text":"Why now?","visa_types":["Z-9"]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Unknown visa types should get %d, got %d", http.StatusBadRequest, w.Code)
+	}
+	w = doJSON(r, http.MethodPost, "/admin/questions", `{"category":"Purpose of Trip","text":"Why now?","visa_types":["b1/b2"]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	decodeJSON(t, w, &created)
+	if len(created.Data.VisaTypes) != 1 || created.Data.VisaTypes[0] != "B1/B2" {
+		t.Errorf("Expected the visa type as the supported visa spells it, got %v", created.Data.VisaTypes)
+	}
+}
+
+func TestQuestionAdminReorder(t *testing.T) {
+	useQuestionStore(t)
+	r := questionAdminRouter(models.RoleAdmin)
+
+	// Listing the bank does not write to the store
+	if w := doJSON(r, http.MethodGet, "/admin/questions", ""); w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if stored, _ := interview.GetQuestionStore().ListQuestions(); len(stored) != 0 {
+		t.Errorf("Expected a GET to leave the store empty, got %d questions", len(stored))
+	}
+
+	order := `{"category":"Purpose of Study","ids":["purpose_of_study_4","purpose_of_study_3","purpose_of_study_2","purpose_of_study_1"]}`
+	if w := doJSON(r, http.MethodPut, "/admin/questions/order", order); w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	served := interview.QuestionsByCategory["Purpose of Study"]
+	if len(served) != 4 || served[0].ID != "purpose_of_study_4" || served[3].ID != "purpose_of_study_1" {
+		t.Errorf("Expected new sessions to be served the new order, got %v", served)
+	}
+
+	// The order is saved, so a reload from the store keeps it
+	if err := interview.ReloadQuestionBank(); err != nil {
+		t.Fatalf("ReloadQuestionBank failed: %v", err)
+	}
+	if first := interview.QuestionsByCategory["Purpose of Study"][0]; first.ID != "purpose_of_study_4" {
+		t.Errorf("Expected purpose_of_study_4 first after a reload, got %s", first.ID)
+	}
+
+	incomplete := `{"category":"Purpose of Study","ids":["purpose_of_study_1"]}`
+	if w := doJSON(r, http.MethodPut, "/admin/questions/order", incomplete); w.Code != http.StatusBadRequest {
+		t.Errorf("An order missing active questions should get %d, got %d", http.StatusBadRequest, w.Code)
+	}
+	unknown := `{"category":"Purpose of Study","ids":["purpose_of_study_4","purpose_of_study_3","purpose_of_study_2","purpose_of_study_1","nope"]}`
+	if w := doJSON(r, http.MethodPut, "/admin/questions/order", unknown); w.Code != http.StatusBadRequest {
+		t.Errorf("An order with unknown IDs should get %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestQuestionAdminKeepsRequiredCategories(t *testing.T) {
+	useQuestionStore(t)
+
+	ids := make([]string, 0)
+	for _, q := range interview.QuestionsByCategory["Purpose of Study"] {
+		ids = append(ids, q.ID)
+	}
+	for _, id := range ids[:len(ids)-1] {
+		if _, err := interview.RetireBankQuestion(id); err != nil {
+			t.Fatalf("RetireBankQuestion(%s) failed: %v", id, err)
+		}
+	}
+	if _, err := interview.RetireBankQuestion(ids[len(ids)-1]); err == nil {
+		t.Error("Retiring the last question of a required category should fail")
+	}
+	if len(interview.QuestionsByCategory["Purpose of Study"]) != 1 {
+		t.Error("A rejected edit should leave the bank unchanged")
+	}
+}
+
+func TestReloadQuestionBankAddsNewQuestions(t *testing.T) {
+	useQuestionStore(t)
+	total := 0
+	for _, questions := range interview.QuestionsByCategory {
+		total += len(questions)
+	}
+
+	// A store seeded by an older questions.json, with one question edited by an admin
+	edited := interview.QuestionsByCategory["Purpose of Study"][0]
+	edited.Text = "Edited by an admin?"
+	store := interview.GetQuestionStore()
+	if err := store.SaveQuestions([]interview.BankQuestion{{Question: edited}}); err != nil {
+		t.Fatalf("SaveQuestions failed: %v", err)
+	}
+
+	if err := interview.ReloadQuestionBank(); err != nil {
+		t.Fatalf("ReloadQuestionBank failed: %v", err)
+	}
+	stored, _ := store.ListQuestions()
+	if len(stored) != total {
+		t.Errorf("Expected the %d bank questions in the store, got %d", total, len(stored))
+	}
+	for _, q := range stored {
+		if q.ID == edited.ID && q.Text != edited.Text {
+			t.Errorf("Expected the admin's edit to be kept, got %q", q.Text)
+		}
+	}
+	if len(interview.SelectQuestionsForUser("", interview.SessionOptions{VisaType: "J-1"})) == 0 {
+		t.Error("Expected J-1 sessions to get questions after the reload")
+	}
+}
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"altoai_mvp/internal/handlers"
+	"altoai_mvp/internal/middleware"
+	"altoai_mvp/internal/models"
+	"altoai_mvp/internal/repository"
+	"altoai_mvp/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// withRole injects auth claims carrying role, as JWTAuth would for a token issued to that user
+func withRole(userID string, role models.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("user", &middleware.MyClaims{UserID: userID, Email: userID + "@example.com", Role: role})
+		c.Next()
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		name string
+		auth gin.HandlerFunc
+		want int
+	}{
+		{"no claims", func(c *gin.Context) { c.Next() }, http.StatusUnauthorized},
+		{"student", withRole("alice", models.RoleStudent), http.StatusForbidden},
+		{"token without role", withUser("alice"), http.StatusForbidden},
+		{"coach", withRole("carol", models.RoleCoach), http.StatusOK},
+		{"admin", withRole("root", models.RoleAdmin), http.StatusOK},
+	}
+	for _, tc := range cases {
+		r := gin.New()
+		r.GET("/coach", tc.auth, middleware.RequireRole(models.RoleCoach), func(c *gin.Context) { c.Status(http.StatusOK) })
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/coach", nil))
+		if w.Code != tc.want {
+			t.Errorf("%s: expected status %d, got %d", tc.name, tc.want, w.Code)
+		}
+	}
+}
+
+func TestEffectiveRole(t *testing.T) {
+	t.Setenv("ADMIN_EMAILS", "root@example.com, Boss@Example.com")
+
+	if role := services.EffectiveRole(models.User{Email: "alice@example.com"}); role != models.RoleStudent {
+		t.Errorf("Users without a role should be students, got %s", role)
+	}
+	if role := services.EffectiveRole(models.User{Email: "carol@example.com", Role: models.RoleCoach}); role != models.RoleCoach {
+		t.Errorf("Expected the stored role, got %s", role)
+	}
+	if role := services.EffectiveRole(models.User{Email: "boss@example.com", Role: models.RoleStudent}); role != models.RoleAdmin {
+		t.Errorf("ADMIN_EMAILS should make a user admin, got %s", role)
+	}
+}
+
+func TestUpdateRoleEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userSvc := services.NewUserService(repository.NewUserMemoryRepo())
+	user, err := userSvc.Create(context.Background(), models.CreateUserDTO{Email: "carol@example.com", Name: "Carol", Password: "secret1"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if user.Role != models.RoleStudent {
+		t.Errorf("New users should be students, got %s", user.Role)
+	}
+
+	h := handlers.NewUserHandler(userSvc)
+	router := func(role models.Role) *gin.Engine {
+		r := gin.New()
+		r.PUT("/users/:id/role", withRole("someone", role), middleware.RequireRole(models.RoleAdmin), h.UpdateRole)
+		return r
+	}
+
+	if w := doJSON(router(models.RoleCoach), http.MethodPut, "/users/"+user.ID+"/role", `{"role":"admin"}`); w.Code != http.StatusForbidden {
+		t.Errorf("Coaches should not change roles, got %d", w.Code)
+	}
+	if w := doJSON(router(models.RoleAdmin), http.MethodPut, "/users/"+user.ID+"/role", `{"role":"janitor"}`); w.Code != http.StatusUnprocessableEntity && w.Code != http.StatusBadRequest {
+		t.Errorf("Unknown roles should be rejected, got %d", w.Code)
+	}
+	if w := doJSON(router(models.RoleAdmin), http.MethodPut, "/users/missing/role", `{"role":"coach"}`); w.Code != http.StatusNotFound {
+		t.Errorf("Unknown users should get %d, got %d", http.StatusNotFound, w.Code)
+	}
+
+	w := doJSON(router(models.RoleAdmin), http.MethodPut, "/users/"+user.ID+"/role", `{"role":"coach"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var updated struct {
+		Data models.User `json:"data"`
+	}
+	decodeJSON(t, w, &updated)
+	if updated.Data.Role != models.RoleCoach {
+		t.Errorf("Expected role coach, got %s", updated.Data.Role)
+	}
+}
+
+func TestAccessTokenCarriesRole(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	repo := repository.NewUserMemoryRepo()
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret1"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, _ := repo.Create("carol@example.com", "Carol", string(hash))
+	repo.MarkEmailVerified(user.Email)
+	repo.UpdateRole(user.ID, models.RoleCoach)
+
+	access, _, _, err := services.NewAuthService(repo).Login(context.Background(), models.LoginDTO{Email: user.Email, Password: "secret1"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	claims := &middleware.MyClaims{}
+	if _, err := jwt.ParseWithClaims(access, claims, func(*jwt.Token) (any, error) {
+		return []byte("test-secret"), nil
+	}); err != nil {
+		t.Fatalf("Failed to parse access token: %v", err)
+	}
+	if claims.Role != models.RoleCoach {
+		t.Errorf("Expected role coach in the token, got %q", claims.Role)
+	}
+}